@@ -36,6 +36,8 @@ type BuildCmd struct {
 	BuildSequential     bool
 	MaxConcurrentBuilds int
 	ForceDependencies   bool
+
+	Report string
 }
 
 // NewBuildCmd creates a new devspace build command
@@ -70,6 +72,7 @@ Builds all defined images and pushes them
 
 	buildCmd.Flags().BoolVar(&cmd.SkipPush, "skip-push", false, "Skips image pushing, useful for minikube deployment")
 	buildCmd.Flags().BoolVar(&cmd.SkipPushLocalKubernetes, "skip-push-local-kube", false, "Skips image pushing, if a local kubernetes environment is detected")
+	buildCmd.Flags().StringVar(&cmd.Report, "report", "", "Writes a build report with the tags, digests and durations of all images to the given file (json or yaml depending on the file extension)")
 
 	return buildCmd
 }
@@ -159,13 +162,29 @@ func (cmd *BuildCmd) runCommand(f factory.Factory, client kubectl.Client, config
 
 	// Build images if necessary
 	if len(cmd.Dependency) == 0 {
+		var report *build.Report
+		if cmd.Report != "" {
+			report = &build.Report{}
+		}
+
 		builtImages, err := f.NewBuildController(configInterface, dependencies, client).Build(&build.Options{
 			SkipPush:                  cmd.SkipPush,
 			SkipPushOnLocalKubernetes: cmd.SkipPushLocalKubernetes,
 			ForceRebuild:              cmd.ForceBuild,
 			Sequential:                cmd.BuildSequential,
 			MaxConcurrentBuilds:       cmd.MaxConcurrentBuilds,
+			Report:                    report,
 		}, log)
+
+		// Write the report even if the build failed
+		if report != nil {
+			reportErr := build.WriteReport(report, cmd.Report)
+			if reportErr != nil {
+				log.Warnf("Error writing build report to %s: %v", cmd.Report, reportErr)
+			} else {
+				log.Infof("Wrote build report to %s", cmd.Report)
+			}
+		}
 		if err != nil {
 			if strings.Contains(err.Error(), "no space left on device") {
 				return errors.Errorf("Error building image: %v\n\n Try running `%s` to free docker daemon space and retry", err, ansi.Color("devspace cleanup images", "white+b"))
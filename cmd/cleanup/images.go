@@ -2,11 +2,23 @@ package cleanup
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl/walk"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
 	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
+	"github.com/loft-sh/devspace/pkg/devspace/signature"
 	"github.com/loft-sh/devspace/pkg/util/factory"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/pkg/errors"
@@ -15,6 +27,10 @@ import (
 
 type imagesCmd struct {
 	*flags.GlobalFlags
+
+	Registry bool
+	Keep     int
+	DryRun   bool
 }
 
 func newImagesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
@@ -28,6 +44,15 @@ func newImagesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Comm
 ############# devspace cleanup images #################
 #######################################################
 Deletes all locally created docker images from docker
+
+With --registry, old tags of the configured images are
+deleted from the registry instead. The most recent tags
+(--keep) and all tags that are referenced in the
+generated.yaml, by rollback manifests or by running pods
+are kept together with their signatures.
+
+devspace cleanup images --registry --dry-run
+devspace cleanup images --registry --keep 5
 #######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -35,6 +60,10 @@ Deletes all locally created docker images from docker
 			return cmd.RunCleanupImages(f, cobraCmd, args)
 		}}
 
+	imagesCmd.Flags().BoolVar(&cmd.Registry, "registry", false, "If true will delete old image tags from the registry instead of local images")
+	imagesCmd.Flags().IntVar(&cmd.Keep, "keep", 10, "The amount of most recent tags per image to keep in the registry")
+	imagesCmd.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "If true will only print the registry tags that would be deleted")
+
 	return imagesCmd
 }
 
@@ -79,6 +108,11 @@ func (cmd *imagesCmd) RunCleanupImages(f factory.Factory, cobraCmd *cobra.Comman
 		return nil
 	}
 
+	// Cleanup the registry instead of the local images
+	if cmd.Registry {
+		return cmd.cleanupRegistry(f, configInterface, client, log)
+	}
+
 	_, err = client.Ping(context.Background())
 	if err != nil {
 		return errors.Errorf("Docker seems to be not running: %v", err)
@@ -130,3 +164,265 @@ func (cmd *imagesCmd) RunCleanupImages(f factory.Factory, cobraCmd *cobra.Comman
 	log.Donef("Successfully cleaned up images")
 	return nil
 }
+
+// protectedRegistryTags are the tags and digests of an image repository that must not be deleted
+type protectedRegistryTags struct {
+	tags    map[string]bool
+	digests map[string]bool
+}
+
+// cleanupRegistry deletes old tags of the configured images from the registry
+func (cmd *imagesCmd) cleanupRegistry(f factory.Factory, configInterface config.Config, client docker.Client, log logpkg.Logger) error {
+	if cmd.Keep < 0 {
+		return errors.Errorf("--keep must not be negative")
+	}
+
+	protected, err := cmd.getProtectedRegistryTags(f, configInterface, log)
+	if err != nil {
+		return err
+	}
+
+	images := map[string]bool{}
+	for _, imageConfig := range configInterface.Config().Images {
+		images[imageConfig.Image] = true
+	}
+
+	imageNames := []string{}
+	for image := range images {
+		imageNames = append(imageNames, image)
+	}
+	sort.Strings(imageNames)
+
+	// continue with the other images if an image fails and report the failed images at the end
+	failedImages := []string{}
+	for _, image := range imageNames {
+		err = cmd.cleanupRegistryImage(image, protected[helper.GetImageRepository(image)], client, log)
+		if err != nil {
+			log.Warnf("Error cleaning up registry tags of image %s: %v", image, err)
+			failedImages = append(failedImages, image)
+		}
+	}
+	if len(failedImages) > 0 {
+		return errors.Errorf("error cleaning up registry tags of image(s) %s", strings.Join(failedImages, ", "))
+	}
+
+	log.Donef("Successfully cleaned up registry tags")
+	return nil
+}
+
+func (cmd *imagesCmd) cleanupRegistryImage(image string, protected *protectedRegistryTags, client docker.Client, log logpkg.Logger) error {
+	if protected == nil {
+		protected = &protectedRegistryTags{}
+	}
+
+	registryURL, err := pullsecrets.GetRegistryFromImageName(image)
+	if err != nil {
+		return err
+	}
+
+	authConfig, err := client.GetAuthConfig(registryURL, true)
+	if err != nil {
+		return errors.Wrap(err, "get auth config")
+	}
+
+	actions := []string{"pull"}
+	if !cmd.DryRun {
+		actions = append(actions, "push", "delete")
+	}
+
+	registryClient, err := docker.NewRegistryClient(image, authConfig, actions...)
+	if err != nil {
+		return err
+	}
+
+	log.StartWait("Listing registry tags of image " + image)
+	tags, err := registryClient.GetRegistryTags(context.Background())
+	log.StopWait()
+	if err != nil {
+		return err
+	}
+
+	stale := docker.SelectStaleTags(tags, cmd.Keep, protected.tags, protected.digests, signature.Tag)
+	if len(stale) == 0 {
+		log.Infof("No tags of image %s to delete (%d tags found)", image, len(tags))
+		return nil
+	}
+
+	if cmd.DryRun {
+		rows := [][]string{}
+		for _, tag := range stale {
+			created := "unknown"
+			if !tag.Created.IsZero() {
+				created = tag.Created.Format(time.RFC3339)
+			}
+
+			rows = append(rows, []string{tag.Tag, tag.Digest, created})
+		}
+
+		log.Infof("Would delete %d of %d tags of image %s:", len(stale), len(tags), image)
+		logpkg.PrintTable(log, []string{"Tag", "Digest", "Created"}, rows)
+		return nil
+	}
+
+	// Deleting a manifest deletes all tags that point to it
+	tagsByDigest := map[string][]string{}
+	digests := []string{}
+	for _, tag := range stale {
+		if _, ok := tagsByDigest[tag.Digest]; !ok {
+			digests = append(digests, tag.Digest)
+		}
+
+		tagsByDigest[tag.Digest] = append(tagsByDigest[tag.Digest], tag.Tag)
+	}
+
+	for _, digest := range digests {
+		err = registryClient.DeleteManifest(context.Background(), digest)
+		if err != nil {
+			return errors.Wrapf(err, "delete %s@%s", image, digest)
+		}
+
+		log.Donef("Deleted %s:%s", image, strings.Join(tagsByDigest[digest], ", "))
+	}
+
+	log.Donef("Deleted %d of %d tags of image %s", len(stale), len(tags), image)
+	return nil
+}
+
+// getProtectedRegistryTags returns the tags that are referenced by the generated.yaml, the rollback manifests of atomic
+// kubectl deployments, the image configs and the running pods for each image repository
+func (cmd *imagesCmd) getProtectedRegistryTags(f factory.Factory, configInterface config.Config, log logpkg.Logger) (map[string]*protectedRegistryTags, error) {
+	protected := map[string]*protectedRegistryTags{}
+	protect := func(image, tag, digest string) {
+		repository := helper.GetImageRepository(image)
+		if repository == "" {
+			return
+		} else if protected[repository] == nil {
+			protected[repository] = &protectedRegistryTags{
+				tags:    map[string]bool{},
+				digests: map[string]bool{},
+			}
+		}
+
+		if tag != "" {
+			protected[repository].tags[tag] = true
+		}
+		if digest != "" {
+			protected[repository].digests[digest] = true
+		}
+	}
+
+	// Tags in the generated.yaml of all profiles
+	if configInterface.Generated() != nil {
+		for _, cache := range configInterface.Generated().Profiles {
+			if cache == nil {
+				continue
+			}
+
+			for _, imageCache := range cache.Images {
+				if imageCache.ImageName != "" && imageCache.Tag != "" {
+					protect(imageCache.ImageName, imageCache.Tag, "")
+				}
+			}
+
+			// Images a rollback of an atomic kubectl deployment would deploy
+			for deploymentName, deploymentCache := range cache.Deployments {
+				if deploymentCache == nil {
+					continue
+				}
+
+				for _, manifest := range deploymentCache.KubectlRollbackManifests {
+					images, err := getManifestImages(manifest)
+					if err != nil {
+						return nil, errors.Wrapf(err, "find images of the rollback manifests of deployment %s", deploymentName)
+					}
+
+					for _, image := range images {
+						protect(splitImage(image))
+					}
+				}
+			}
+		}
+	}
+
+	// Fixed tags of the image configs
+	for _, imageConfig := range configInterface.Config().Images {
+		for _, tag := range imageConfig.Tags {
+			if !strings.Contains(tag, "#") {
+				protect(imageConfig.Image, tag, "")
+			}
+		}
+	}
+
+	// Images of the running pods
+	kubeClient, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace, cmd.SwitchContext)
+	if err != nil {
+		return nil, errors.Wrap(err, "create kube client to check the images of running pods")
+	}
+
+	pods, err := kubeClient.KubeClient().CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Debugf("Error listing pods in all namespaces: %v", err)
+		pods, err = kubeClient.KubeClient().CoreV1().Pods(kubeClient.Namespace()).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "list pods to check their images")
+		}
+
+		log.Warnf("Only images of pods in namespace %s are kept, because pods in other namespaces cannot be listed", kubeClient.Namespace())
+	}
+
+	for _, pod := range pods.Items {
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			image, tag, digest := splitImage(container.Image)
+			protect(image, tag, digest)
+		}
+
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			image, _, digest := splitImage(strings.TrimPrefix(status.ImageID, "docker-pullable://"))
+			protect(image, "", digest)
+		}
+	}
+
+	return protected, nil
+}
+
+// getManifestImages returns the values of all image fields of the given manifests
+func getManifestImages(manifests string) ([]string, error) {
+	objs, err := diff.ParseObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []string{}
+	for _, obj := range objs {
+		err = walk.WalkStringMap(obj.Object, func(key, value string) bool {
+			return key == "image" && value != ""
+		}, func(value string) (interface{}, error) {
+			images = append(images, value)
+			return value, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return images, nil
+}
+
+// splitImage splits an image reference into the image, the tag and the digest
+func splitImage(image string) (string, string, string) {
+	digest := ""
+	if i := strings.Index(image, "@"); i >= 0 {
+		digest = image[i+1:]
+		image = image[:i]
+	}
+
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+		image = image[:i]
+	}
+
+	return image, tag, digest
+}
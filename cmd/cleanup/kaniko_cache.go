@@ -0,0 +1,109 @@
+package cleanup
+
+import (
+	"sort"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/kaniko"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type kanikoCacheCmd struct {
+	*flags.GlobalFlags
+}
+
+func newKanikoCacheCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &kanikoCacheCmd{GlobalFlags: globalFlags}
+
+	kanikoCacheCmd := &cobra.Command{
+		Use:   "kaniko-cache",
+		Short: "Deletes the kaniko cache volumes",
+		Long: ` 
+#######################################################
+########## devspace cleanup kaniko-cache ##############
+#######################################################
+Deletes the persistent volume claims that are used as
+cache volumes by the kaniko builds of the configured
+images. The volumes are created again with the next
+kaniko build.
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.RunCleanupKanikoCache(f, cobraCmd, args)
+		}}
+
+	return kanikoCacheCmd
+}
+
+// RunCleanupKanikoCache executes the cleanup kaniko-cache command logic
+func (cmd *kanikoCacheCmd) RunCleanupKanikoCache(f factory.Factory, cobraCmd *cobra.Command, args []string) error {
+	// Set config root
+	log := f.GetLog()
+	configLoader := f.NewConfigLoader(cmd.ConfigPath)
+	configExists, err := configLoader.SetDevSpaceRoot(log)
+	if err != nil {
+		return err
+	}
+	if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// Create kube client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace, cmd.SwitchContext)
+	if err != nil {
+		return errors.Wrap(err, "create kube client")
+	}
+
+	// Load config
+	configOptions := cmd.ToConfigOptions(log)
+	configOptions.KubeClient = client
+	configInterface, err := configLoader.Load(configOptions, log)
+	if err != nil {
+		return err
+	}
+
+	// Collect the cache volumes of all kaniko images
+	volumes := map[string]types.NamespacedName{}
+	for imageConfigName, imageConfig := range configInterface.Config().Images {
+		if imageConfig.Build == nil || imageConfig.Build.Kaniko == nil || imageConfig.Build.Kaniko.CacheVolume == nil {
+			continue
+		}
+
+		namespace := client.Namespace()
+		if imageConfig.Build.Kaniko.Namespace != "" {
+			namespace = imageConfig.Build.Kaniko.Namespace
+		}
+
+		volume := types.NamespacedName{Namespace: namespace, Name: kaniko.GetCacheVolumeName(imageConfigName, imageConfig.Build.Kaniko.CacheVolume)}
+		volumes[volume.String()] = volume
+	}
+	if len(volumes) == 0 {
+		log.Done("No kaniko cache volumes found in config to delete")
+		return nil
+	}
+
+	names := []string{}
+	for name := range volumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		deleted, err := kaniko.DeleteCacheVolume(client.KubeClient(), volumes[name].Namespace, volumes[name].Name)
+		if err != nil {
+			return errors.Wrapf(err, "delete kaniko cache volume %s", name)
+		} else if deleted {
+			log.Donef("Deleted kaniko cache volume %s", name)
+		} else {
+			log.Infof("Kaniko cache volume %s does not exist", name)
+		}
+	}
+
+	log.Donef("Successfully cleaned up kaniko cache volumes")
+	return nil
+}
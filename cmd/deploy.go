@@ -5,6 +5,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	"os"
 	"strconv"
 	"strings"
 
@@ -36,11 +37,15 @@ type DeployCmd struct {
 	BuildSequential     bool
 	MaxConcurrentBuilds int
 
-	ForceDeploy         bool
-	SkipDeploy          bool
-	Deployments         string
-	ForceDependencies   bool
-	VerboseDependencies bool
+	ForceDeploy              bool
+	SkipDeploy               bool
+	Deployments              string
+	DeployParallel           bool
+	MaxConcurrentDeployments int
+	NoPrune                  bool
+	ForceDependencies        bool
+	VerboseDependencies      bool
+	Diff                     bool
 
 	SkipPush                bool
 	SkipPushLocalKubernetes bool
@@ -96,6 +101,10 @@ devspace deploy --kube-context=deploy-context
 	deployCmd.Flags().BoolVarP(&cmd.ForceDeploy, "force-deploy", "d", false, "Forces to (re-)deploy every deployment")
 	deployCmd.Flags().BoolVar(&cmd.SkipDeploy, "skip-deploy", false, "Skips deploying and only builds images")
 	deployCmd.Flags().StringVar(&cmd.Deployments, "deployments", "", "Only deploy a specifc deployment (You can specify multiple deployments comma-separated")
+	deployCmd.Flags().BoolVar(&cmd.DeployParallel, "deploy-parallel", false, "Deploys the deployments that do not depend on each other in parallel instead of one after another")
+	deployCmd.Flags().IntVar(&cmd.MaxConcurrentDeployments, "max-concurrent-deployments", 0, "The maximum number of deployments deployed in parallel (0 for infinite)")
+	deployCmd.Flags().BoolVar(&cmd.NoPrune, "no-prune", false, "Keeps objects that were removed from the manifests of kubectl deployments instead of deleting them")
+	deployCmd.Flags().BoolVar(&cmd.Diff, "diff", false, "Shows the changes the deployments would make to the cluster without deploying them")
 
 	deployCmd.Flags().StringSliceVar(&cmd.SkipDependency, "skip-dependency", []string{}, "Skips deploying the following dependencies")
 	deployCmd.Flags().StringSliceVar(&cmd.Dependency, "dependency", []string{}, "Deploys only the specific named dependencies")
@@ -169,6 +178,11 @@ func (cmd *DeployCmd) Run(f factory.Factory) error {
 		return err
 	}
 
+	// only show the changes without deploying anything
+	if cmd.Diff {
+		return cmd.runDiff(f, client, configInterface, configOptions)
+	}
+
 	return runWithHooks("deployCommand", client, configInterface, cmd.log, func() error {
 		return cmd.runCommand(f, client, configInterface, configLoader, configOptions)
 	})
@@ -262,11 +276,20 @@ func (cmd *DeployCmd) runCommand(f factory.Factory, client kubectl.Client, confi
 
 			// deploy all defined deployments
 			err = f.NewDeployController(configInterface, dependencies, client).Deploy(&deploy.Options{
-				ForceDeploy: cmd.ForceDeploy,
-				BuiltImages: builtImages,
-				Deployments: deployments,
+				ForceDeploy:              cmd.ForceDeploy,
+				BuiltImages:              builtImages,
+				Deployments:              deployments,
+				Parallel:                 cmd.DeployParallel,
+				MaxConcurrentDeployments: cmd.MaxConcurrentDeployments,
+				NoPrune:                  cmd.NoPrune,
 			}, cmd.log)
 			if err != nil {
+				// save the generated config anyways, so the objects of a failed deployment are pruned by the next deployment
+				saveErr := configLoader.SaveGenerated(configInterface.Generated())
+				if saveErr != nil {
+					cmd.log.Warnf("Error saving generated config: %v", saveErr)
+				}
+
 				return err
 			}
 		}
@@ -295,10 +318,54 @@ func (cmd *DeployCmd) runCommand(f factory.Factory, client kubectl.Client, confi
 	return nil
 }
 
+// runDiff builds the images without pushing them and prints the changes the deployments would make to the cluster.
+// The dependencies are only resolved and not deployed and the generated config is not saved
+func (cmd *DeployCmd) runDiff(f factory.Factory, client kubectl.Client, configInterface config.Config, configOptions *loader.ConfigOptions) error {
+	dependencies, err := f.NewDependencyManager(configInterface, client, configOptions, cmd.log).ResolveAll(dependency.ResolveOptions{
+		Dependencies:     cmd.Dependency,
+		SkipDependencies: cmd.SkipDependency,
+		Verbose:          cmd.VerboseDependencies,
+	})
+	if err != nil {
+		return errors.Wrap(err, "resolve dependencies")
+	}
+
+	// build images, the new tags are only used for the diff and therefore neither pushed nor saved
+	builtImages := make(map[string]string)
+	if !cmd.SkipBuild {
+		builtImages, err = f.NewBuildController(configInterface, dependencies, client).Build(&build.Options{
+			SkipPush:            true,
+			ForceRebuild:        cmd.ForceBuild,
+			Sequential:          cmd.BuildSequential,
+			MaxConcurrentBuilds: cmd.MaxConcurrentBuilds,
+		}, cmd.log)
+		if err != nil {
+			return err
+		}
+	}
+
+	// what deployments should be compared
+	deployments := []string{}
+	if cmd.Deployments != "" {
+		deployments = strings.Split(cmd.Deployments, ",")
+		for index := range deployments {
+			deployments[index] = strings.TrimSpace(deployments[index])
+		}
+	}
+
+	return f.NewDeployController(configInterface, dependencies, client).Diff(&deploy.Options{
+		BuiltImages: builtImages,
+		Deployments: deployments,
+	}, os.Stdout, cmd.log)
+}
+
 func (cmd *DeployCmd) validateFlags() error {
 	if cmd.SkipBuild && cmd.ForceBuild {
 		return errors.New("flags --skip-build & --force-build cannot be used together")
 	}
+	if cmd.Diff && cmd.SkipDeploy {
+		return errors.New("flags --diff & --skip-deploy cannot be used together")
+	}
 
 	return nil
 }
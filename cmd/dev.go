@@ -62,9 +62,12 @@ type DevCmd struct {
 	BuildSequential     bool
 	MaxConcurrentBuilds int
 
-	ForceDeploy       bool
-	Deployments       string
-	ForceDependencies bool
+	ForceDeploy              bool
+	Deployments              string
+	ForceDependencies        bool
+	DeployParallel           bool
+	MaxConcurrentDeployments int
+	NoPrune                  bool
 
 	Sync            bool
 	ExitAfterDeploy bool
@@ -138,6 +141,9 @@ Open terminal instead of logs:
 
 	devCmd.Flags().BoolVarP(&cmd.ForceDeploy, "force-deploy", "d", false, "Forces to deploy every deployment")
 	devCmd.Flags().StringVar(&cmd.Deployments, "deployments", "", "Only deploy a specifc deployment (You can specify multiple deployments comma-separated")
+	devCmd.Flags().BoolVar(&cmd.DeployParallel, "deploy-parallel", false, "Deploys the deployments that do not depend on each other in parallel instead of one after another")
+	devCmd.Flags().IntVar(&cmd.MaxConcurrentDeployments, "max-concurrent-deployments", 0, "The maximum number of deployments deployed in parallel (0 for infinite)")
+	devCmd.Flags().BoolVar(&cmd.NoPrune, "no-prune", false, "Keeps objects that were removed from the manifests of kubectl deployments instead of deleting them")
 
 	devCmd.Flags().BoolVarP(&cmd.SkipPipeline, "skip-pipeline", "x", false, "Skips build & deployment and only starts sync, portforwarding & terminal")
 	devCmd.Flags().BoolVar(&cmd.SkipPush, "skip-push", false, "Skips image pushing, useful for minikube deployment")
@@ -400,12 +406,21 @@ func (cmd *DevCmd) buildAndDeploy(f factory.Factory, configInterface config.Conf
 
 				// Deploy all
 				err = f.NewDeployController(configInterface, dependencies, client).Deploy(&deploy.Options{
-					IsDev:       true,
-					ForceDeploy: cmd.ForceDeploy,
-					BuiltImages: builtImages,
-					Deployments: deployments,
+					IsDev:                    true,
+					ForceDeploy:              cmd.ForceDeploy,
+					BuiltImages:              builtImages,
+					Deployments:              deployments,
+					Parallel:                 cmd.DeployParallel,
+					MaxConcurrentDeployments: cmd.MaxConcurrentDeployments,
+					NoPrune:                  cmd.NoPrune,
 				}, cmd.log)
 				if err != nil {
+					// save the generated config anyways, so the objects of a failed deployment are pruned by the next deployment
+					saveErr := cmd.configLoader.SaveGenerated(generatedConfig)
+					if saveErr != nil {
+						cmd.log.Warnf("Error saving generated config: %v", saveErr)
+					}
+
 					return 0, errors.Errorf("error deploying: %v", err)
 				}
 
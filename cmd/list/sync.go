@@ -1,7 +1,14 @@
 package list
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/services/synccontroller"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/factory"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
@@ -10,6 +17,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// maxListedConflicts is the maximum amount of conflicts that are printed
+const maxListedConflicts = 20
+
 type syncCmd struct {
 	*flags.GlobalFlags
 }
@@ -24,7 +34,9 @@ func newSyncCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Comman
 #######################################################
 ################# devspace list sync ##################
 #######################################################
-Lists the sync configuration
+Lists the sync configuration, the most recent sync
+conflicts and the metrics of the last or currently
+running syncs
 #######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -64,9 +76,12 @@ func (cmd *syncCmd) RunListSync(f factory.Factory, cobraCmd *cobra.Command, args
 		"Local Path",
 		"Container Path",
 		"Excluded Paths",
+		"Conflicts",
 	}
 
 	syncPaths := make([][]string, 0, len(config.Dev.Sync))
+	conflicts := make([][]string, 0)
+	statuses := make([][]string, 0)
 
 	// Transform values into string arrays
 	for _, value := range config.Dev.Sync {
@@ -91,14 +106,91 @@ func (cmd *syncCmd) RunListSync(f factory.Factory, cobraCmd *cobra.Command, args
 			}
 		}
 
+		syncConflicts, err := sync.ReadConflictLog(synccontroller.ConflictLogPath(value))
+		if err != nil {
+			logger.Warnf("Error reading conflict log: %v", err)
+		}
+		for _, conflict := range syncConflicts {
+			conflicts = append(conflicts, []string{
+				conflict.Time.Format(time.RFC3339),
+				value.LocalSubPath,
+				value.ContainerPath,
+				"." + conflict.Path,
+				string(conflict.Resolution),
+			})
+		}
+
+		status, err := sync.ReadStatus(synccontroller.StatusPath(value))
+		if err != nil && !os.IsNotExist(err) {
+			logger.Warnf("Error reading sync status: %v", err)
+		} else if status != nil {
+			statuses = append(statuses, statusRow(value.LocalSubPath, value.ContainerPath, status))
+		}
+
 		syncPaths = append(syncPaths, []string{
 			selector,
 			value.LocalSubPath,
 			value.ContainerPath,
 			excludedPaths,
+			strconv.Itoa(len(syncConflicts)),
 		})
 	}
 
 	log.PrintTable(logger, headerColumnNames, syncPaths)
+
+	// Print the metrics of the last or currently running syncs
+	if len(statuses) > 0 {
+		logger.WriteString("\n")
+		logger.Info("Sync status:\n")
+		log.PrintTable(logger, []string{
+			"Local Path",
+			"Container Path",
+			"State",
+			"Uploaded",
+			"Downloaded",
+			"Avg Latency",
+			"Retries",
+			"Queue",
+			"Last Error",
+		}, statuses)
+	}
+
+	// Print the most recent conflicts
+	if len(conflicts) > 0 {
+		if len(conflicts) > maxListedConflicts {
+			conflicts = conflicts[len(conflicts)-maxListedConflicts:]
+		}
+
+		logger.WriteString("\n")
+		logger.Info("Most recent sync conflicts:\n")
+		log.PrintTable(logger, []string{
+			"Time",
+			"Local Path",
+			"Container Path",
+			"File",
+			"Resolution",
+		}, conflicts)
+	}
+
 	return nil
 }
+
+// statusRow returns the table row of the given sync status
+func statusRow(localPath, containerPath string, status *sync.Status) []string {
+	state := "running"
+	if status.Stopped {
+		state = "stopped"
+	}
+
+	return []string{
+		localPath,
+		containerPath,
+		state,
+		fmt.Sprintf("%d file(s), %0.2f KB", status.FilesUploaded, float64(status.BytesUploaded)/1024.0),
+		fmt.Sprintf("%d file(s), %0.2f KB", status.FilesDownloaded, float64(status.BytesDownloaded)/1024.0),
+		fmt.Sprintf("%dms", status.AverageBatchLatency),
+		strconv.FormatInt(status.Retries, 10),
+		fmt.Sprintf("%d/%d", status.UpstreamQueue, status.DownstreamPending),
+		status.LastError,
+	}
+}
@@ -59,10 +59,10 @@ func TestListSyncs(t *testing.T) {
 					},
 				},
 			},
-			expectedHeader: []string{"Label Selector", "Local Path", "Container Path", "Excluded Paths"},
+			expectedHeader: []string{"Label Selector", "Local Path", "Container Path", "Excluded Paths", "Conflicts"},
 			expectedValues: [][]string{
-				[]string{"app=test", "local", "container", "path1, path2"},
-				[]string{"a=b=, a=b=", "local2", "container2", ""},
+				[]string{"app=test", "local", "container", "path1, path2", "0"},
+				[]string{"a=b=, a=b=", "local2", "container2", "", "0"},
 			},
 		},
 	}
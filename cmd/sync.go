@@ -17,7 +17,9 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	latest "github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	devspacesync "github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/survey"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -45,6 +47,8 @@ type SyncCmd struct {
 	DownloadOnly          bool
 	UploadOnly            bool
 
+	DryRun bool
+
 	// used for testing to allow interruption
 	Interrupt chan error
 }
@@ -68,6 +72,7 @@ devspace sync --local-path=subfolder --container-path=/app
 devspace sync --exclude=node_modules --exclude=test
 devspace sync --pod=my-pod --container=my-container
 devspace sync --container-path=/my-path
+devspace sync --initial-sync=mirrorLocal --dry-run
 #######################################################`,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			// Print upgrade message if new version available
@@ -96,6 +101,8 @@ devspace sync --container-path=/my-path
 	syncCmd.Flags().BoolVar(&cmd.UploadOnly, "upload-only", false, "If set DevSpace will only upload files")
 	syncCmd.Flags().BoolVar(&cmd.DownloadOnly, "download-only", false, "If set DevSpace will only download files")
 
+	syncCmd.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "Prints the files the initial sync would upload, download, delete or resolve as conflict without changing anything")
+
 	return syncCmd
 }
 
@@ -236,8 +243,69 @@ func (cmd *SyncCmd) Run(f factory.Factory) error {
 
 	options = options.ApplyConfigParameter(syncConfig.LabelSelector, syncConfig.Namespace, syncConfig.ContainerName, "")
 
+	// Only print what the initial sync would do
+	servicesClient := f.NewServicesClient(configInterface, nil, client, logger)
+	if cmd.DryRun {
+		result, err := servicesClient.SyncDryRun(options, syncConfig)
+		if err != nil {
+			return errors.Wrap(err, "dry run")
+		}
+
+		printDryRunResult(logger, result)
+		return nil
+	}
+
 	// Start sync
-	return f.NewServicesClient(configInterface, nil, client, logger).StartSyncFromCmd(options, syncConfig, cmd.Interrupt, cmd.NoWatch, cmd.Verbose)
+	return servicesClient.StartSyncFromCmd(options, syncConfig, cmd.Interrupt, cmd.NoWatch, cmd.Verbose)
+}
+
+func printDryRunResult(logger log.Logger, result *devspacesync.DryRunResult) {
+	rows := [][]string{}
+	for _, changes := range []struct {
+		action string
+		files  []*devspacesync.FileInformation
+	}{
+		{action: "upload", files: result.Upload},
+		{action: "download", files: result.Download},
+		{action: "delete remote", files: result.DeleteRemote},
+		{action: "delete local", files: result.DeleteLocal},
+	} {
+		for _, file := range changes.files {
+			size := formatSize(file.Size)
+			if file.IsDirectory {
+				size = "dir"
+			}
+
+			rows = append(rows, []string{changes.action, "." + file.Name, size})
+		}
+	}
+	for _, conflict := range result.Conflicts {
+		rows = append(rows, []string{"conflict (" + conflict.Resolution + ")", "." + conflict.Path, formatSize(conflict.LocalSize) + " local / " + formatSize(conflict.RemoteSize) + " remote"})
+	}
+
+	if len(rows) == 0 {
+		logger.Info("Local and container files are in sync, the initial sync would not change anything")
+		return
+	}
+
+	log.PrintTable(logger, []string{"Action", "Path", "Size"}, rows)
+	logger.WriteString("\n")
+	logger.Infof("Dry run: %d upload(s), %d download(s), %d remote delete(s), %d local delete(s), %d conflict(s)", len(result.Upload), len(result.Download), len(result.DeleteRemote), len(result.DeleteLocal), len(result.Conflicts))
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
 
 func (cmd *SyncCmd) applyFlagsToSyncConfig(syncConfig *latest.SyncConfig) error {
@@ -51,6 +51,10 @@ module.exports = {
             'configuration/images/append-dockerfile-instructions',
             'configuration/images/inject-restart-helper',
             'configuration/images/rebuild-strategy',
+            'configuration/images/depends-on',
+            'configuration/images/remote-cache',
+            'configuration/images/platforms',
+            'configuration/images/signature',
             'configuration/images/pull-secrets',
             {
               type: 'category',
@@ -60,7 +64,9 @@ module.exports = {
                 'configuration/images/buildkit',
                 'configuration/images/kaniko',
                 'configuration/images/custom',
+                'configuration/images/gobuild',
                 'configuration/images/disabled',
+                'configuration/images/timeout-retry',
               ],
             },
           ],
@@ -208,6 +214,7 @@ module.exports = {
         "commands/devspace_attach",
         "commands/devspace_build",
         "commands/devspace_cleanup_images",
+        "commands/devspace_cleanup_kaniko-cache",
         "commands/devspace_deploy",
         "commands/devspace_dev",
         "commands/devspace_enter",
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package sync
+
+import "syscall"
+
+// detachedProcAttr starts the process in a new session, so that it keeps running if the exec
+// session that started it is closed
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
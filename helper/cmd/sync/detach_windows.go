@@ -0,0 +1,7 @@
+package sync
+
+import "syscall"
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/server"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd holds the serve cmd flags
+type ServeCmd struct {
+	Listen      string
+	AllowRemote bool
+	Detach      bool
+	IdleTimeout time.Duration
+}
+
+// NewServeCmd creates a new serve command
+func NewServeCmd() *cobra.Command {
+	cmd := &ServeCmd{}
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Starts a sync server that serves multiple upstream and downstream sessions",
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+
+	serveCmd.Flags().StringVar(&cmd.Listen, "listen", "", "If set, the server listens on the given tcp address (e.g. 127.0.0.1:9410) instead of stdin and stdout")
+	serveCmd.Flags().BoolVar(&cmd.AllowRemote, "allow-remote", false, "If true, --listen may be a non loopback address. The server is unauthenticated and allows everyone who can reach it to execute commands in the container")
+	serveCmd.Flags().BoolVar(&cmd.Detach, "detach", false, "If true, the server is started in the background and the command returns as soon as the server accepts connections. Requires --listen")
+	serveCmd.Flags().DurationVar(&cmd.IdleTimeout, "idle-timeout", 0, "If set, a server started with --listen exits if there was no connection for the given duration")
+	return serveCmd
+}
+
+// Run runs the command logic
+func (cmd *ServeCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	if cmd.Listen == "" {
+		if cmd.Detach {
+			return errors.New("--detach requires --listen")
+		}
+
+		return server.StartSessionServer(os.Stdin, os.Stdout, true)
+	} else if !cmd.AllowRemote && !isLoopbackAddress(cmd.Listen) {
+		return errors.Errorf("--listen %s is not a loopback address, use e.g. 127.0.0.1:9410 or set --allow-remote", cmd.Listen)
+	} else if cmd.Detach {
+		return cmd.detach()
+	}
+
+	return server.StartSessionServerOnAddress(cmd.Listen, cmd.IdleTimeout)
+}
+
+// detach starts the server in a new process and waits until it accepts connections. If a server
+// already listens on the address, the new process exits and the existing server is used
+func (cmd *ServeCmd) detach() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	process := exec.Command(executable, "sync", "serve", "--listen", cmd.Listen, "--allow-remote="+strconv.FormatBool(cmd.AllowRemote), "--idle-timeout", cmd.IdleTimeout.String())
+	process.SysProcAttr = detachedProcAttr()
+	err = process.Start()
+	if err != nil {
+		return errors.Wrap(err, "start server")
+	}
+	go func() {
+		_ = process.Wait()
+	}()
+
+	for i := 0; i < 50; i++ {
+		conn, err := net.DialTimeout("tcp", cmd.Listen, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	return errors.Errorf("timeout waiting for server to listen on %s", cmd.Listen)
+}
+
+// isLoopbackAddress returns true if the host of the tcp address is a loopback address. An empty host
+// listens on all interfaces and is not a loopback address
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	} else if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -14,5 +14,6 @@ func NewSyncCmd() *cobra.Command {
 
 	syncCmd.AddCommand(NewDownstreamCmd())
 	syncCmd.AddCommand(NewUpstreamCmd())
+	syncCmd.AddCommand(NewServeCmd())
 	return syncCmd
 }
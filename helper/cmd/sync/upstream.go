@@ -1,9 +1,7 @@
 package sync
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/loft-sh/devspace/helper/server"
 	"github.com/spf13/cobra"
@@ -68,30 +66,5 @@ func (cmd *UpstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 }
 
 func ensurePath(args []string) (string, error) {
-	// Create the directory if it does not exist
-	path := args[0]
-	_, err := os.Stat(path)
-	if err != nil && os.IsNotExist(err) {
-		err := os.MkdirAll(path, 0755)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	// we have to resolve the real local path, because the watcher gives us the real path always
-	realLocalPath, err := filepath.EvalSymlinks(path)
-	if err != nil {
-		return "", err
-	}
-
-	absolutePath, err := filepath.Abs(realLocalPath)
-	if err != nil {
-		return "", err
-	}
-
-	if absolutePath == "/" && path != "/" {
-		return "", fmt.Errorf("you are trying to sync the complete container root (/). By default this is not allowed, because this usually leads to unwanted behaviour. Please specify the correct container directory via the `--container-path` flag or `.containerPath` option")
-	}
-
-	return absolutePath, nil
+	return server.EnsurePath(args[0])
 }
@@ -303,6 +303,384 @@ func (m *SocketDataResponse) GetShouldClose() bool {
 	return false
 }
 
+type SessionID struct {
+	ID                   string   `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SessionID) Reset()         { *m = SessionID{} }
+func (m *SessionID) String() string { return proto.CompactTextString(m) }
+func (*SessionID) ProtoMessage()    {}
+func (*SessionID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{3}
+}
+
+func (m *SessionID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionID.Unmarshal(m, b)
+}
+func (m *SessionID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionID.Marshal(b, m, deterministic)
+}
+func (m *SessionID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionID.Merge(m, src)
+}
+func (m *SessionID) XXX_Size() int {
+	return xxx_messageInfo_SessionID.Size(m)
+}
+func (m *SessionID) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionID proto.InternalMessageInfo
+
+func (m *SessionID) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+type SessionOptions struct {
+	ID                   string                    `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Upstream             *UpstreamSessionOptions   `protobuf:"bytes,2,opt,name=Upstream,proto3" json:"Upstream,omitempty"`
+	Downstream           *DownstreamSessionOptions `protobuf:"bytes,3,opt,name=Downstream,proto3" json:"Downstream,omitempty"`
+	ReadOnly             bool                      `protobuf:"varint,4,opt,name=ReadOnly,proto3" json:"ReadOnly,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *SessionOptions) Reset()         { *m = SessionOptions{} }
+func (m *SessionOptions) String() string { return proto.CompactTextString(m) }
+func (*SessionOptions) ProtoMessage()    {}
+func (*SessionOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{4}
+}
+
+func (m *SessionOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionOptions.Unmarshal(m, b)
+}
+func (m *SessionOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionOptions.Marshal(b, m, deterministic)
+}
+func (m *SessionOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionOptions.Merge(m, src)
+}
+func (m *SessionOptions) XXX_Size() int {
+	return xxx_messageInfo_SessionOptions.Size(m)
+}
+func (m *SessionOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionOptions proto.InternalMessageInfo
+
+func (m *SessionOptions) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+func (m *SessionOptions) GetUpstream() *UpstreamSessionOptions {
+	if m != nil {
+		return m.Upstream
+	}
+	return nil
+}
+
+func (m *SessionOptions) GetDownstream() *DownstreamSessionOptions {
+	if m != nil {
+		return m.Downstream
+	}
+	return nil
+}
+
+func (m *SessionOptions) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
+type UpstreamSessionOptions struct {
+	Path                 string      `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Exclude              []string    `protobuf:"bytes,2,rep,name=Exclude,proto3" json:"Exclude,omitempty"`
+	FileChangeCmd        string      `protobuf:"bytes,3,opt,name=FileChangeCmd,proto3" json:"FileChangeCmd,omitempty"`
+	FileChangeArgs       []string    `protobuf:"bytes,4,rep,name=FileChangeArgs,proto3" json:"FileChangeArgs,omitempty"`
+	DirCreateCmd         string      `protobuf:"bytes,5,opt,name=DirCreateCmd,proto3" json:"DirCreateCmd,omitempty"`
+	DirCreateArgs        []string    `protobuf:"bytes,6,rep,name=DirCreateArgs,proto3" json:"DirCreateArgs,omitempty"`
+	OverridePermissions  bool        `protobuf:"varint,7,opt,name=OverridePermissions,proto3" json:"OverridePermissions,omitempty"`
+	PreserveModes        bool        `protobuf:"varint,8,opt,name=PreserveModes,proto3" json:"PreserveModes,omitempty"`
+	Owner                *Owner      `protobuf:"bytes,9,opt,name=Owner,proto3" json:"Owner,omitempty"`
+	ModeMasks            []*ModeMask `protobuf:"bytes,10,rep,name=ModeMasks,proto3" json:"ModeMasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *UpstreamSessionOptions) Reset()         { *m = UpstreamSessionOptions{} }
+func (m *UpstreamSessionOptions) String() string { return proto.CompactTextString(m) }
+func (*UpstreamSessionOptions) ProtoMessage()    {}
+func (*UpstreamSessionOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{5}
+}
+
+func (m *UpstreamSessionOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpstreamSessionOptions.Unmarshal(m, b)
+}
+func (m *UpstreamSessionOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpstreamSessionOptions.Marshal(b, m, deterministic)
+}
+func (m *UpstreamSessionOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpstreamSessionOptions.Merge(m, src)
+}
+func (m *UpstreamSessionOptions) XXX_Size() int {
+	return xxx_messageInfo_UpstreamSessionOptions.Size(m)
+}
+func (m *UpstreamSessionOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpstreamSessionOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpstreamSessionOptions proto.InternalMessageInfo
+
+func (m *UpstreamSessionOptions) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *UpstreamSessionOptions) GetExclude() []string {
+	if m != nil {
+		return m.Exclude
+	}
+	return nil
+}
+
+func (m *UpstreamSessionOptions) GetFileChangeCmd() string {
+	if m != nil {
+		return m.FileChangeCmd
+	}
+	return ""
+}
+
+func (m *UpstreamSessionOptions) GetFileChangeArgs() []string {
+	if m != nil {
+		return m.FileChangeArgs
+	}
+	return nil
+}
+
+func (m *UpstreamSessionOptions) GetDirCreateCmd() string {
+	if m != nil {
+		return m.DirCreateCmd
+	}
+	return ""
+}
+
+func (m *UpstreamSessionOptions) GetDirCreateArgs() []string {
+	if m != nil {
+		return m.DirCreateArgs
+	}
+	return nil
+}
+
+func (m *UpstreamSessionOptions) GetOverridePermissions() bool {
+	if m != nil {
+		return m.OverridePermissions
+	}
+	return false
+}
+
+func (m *UpstreamSessionOptions) GetPreserveModes() bool {
+	if m != nil {
+		return m.PreserveModes
+	}
+	return false
+}
+
+func (m *UpstreamSessionOptions) GetOwner() *Owner {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+func (m *UpstreamSessionOptions) GetModeMasks() []*ModeMask {
+	if m != nil {
+		return m.ModeMasks
+	}
+	return nil
+}
+
+type Owner struct {
+	UID                  int64    `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+	GID                  int64    `protobuf:"varint,2,opt,name=GID,proto3" json:"GID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Owner) Reset()         { *m = Owner{} }
+func (m *Owner) String() string { return proto.CompactTextString(m) }
+func (*Owner) ProtoMessage()    {}
+func (*Owner) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{6}
+}
+
+func (m *Owner) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Owner.Unmarshal(m, b)
+}
+func (m *Owner) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Owner.Marshal(b, m, deterministic)
+}
+func (m *Owner) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Owner.Merge(m, src)
+}
+func (m *Owner) XXX_Size() int {
+	return xxx_messageInfo_Owner.Size(m)
+}
+func (m *Owner) XXX_DiscardUnknown() {
+	xxx_messageInfo_Owner.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Owner proto.InternalMessageInfo
+
+func (m *Owner) GetUID() int64 {
+	if m != nil {
+		return m.UID
+	}
+	return 0
+}
+
+func (m *Owner) GetGID() int64 {
+	if m != nil {
+		return m.GID
+	}
+	return 0
+}
+
+type DownstreamSessionOptions struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Exclude              []string `protobuf:"bytes,2,rep,name=Exclude,proto3" json:"Exclude,omitempty"`
+	Throttle             int64    `protobuf:"varint,3,opt,name=Throttle,proto3" json:"Throttle,omitempty"`
+	Polling              bool     `protobuf:"varint,4,opt,name=Polling,proto3" json:"Polling,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DownstreamSessionOptions) Reset()         { *m = DownstreamSessionOptions{} }
+func (m *DownstreamSessionOptions) String() string { return proto.CompactTextString(m) }
+func (*DownstreamSessionOptions) ProtoMessage()    {}
+func (*DownstreamSessionOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{7}
+}
+
+func (m *DownstreamSessionOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DownstreamSessionOptions.Unmarshal(m, b)
+}
+func (m *DownstreamSessionOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DownstreamSessionOptions.Marshal(b, m, deterministic)
+}
+func (m *DownstreamSessionOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DownstreamSessionOptions.Merge(m, src)
+}
+func (m *DownstreamSessionOptions) XXX_Size() int {
+	return xxx_messageInfo_DownstreamSessionOptions.Size(m)
+}
+func (m *DownstreamSessionOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_DownstreamSessionOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DownstreamSessionOptions proto.InternalMessageInfo
+
+func (m *DownstreamSessionOptions) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *DownstreamSessionOptions) GetExclude() []string {
+	if m != nil {
+		return m.Exclude
+	}
+	return nil
+}
+
+func (m *DownstreamSessionOptions) GetThrottle() int64 {
+	if m != nil {
+		return m.Throttle
+	}
+	return 0
+}
+
+func (m *DownstreamSessionOptions) GetPolling() bool {
+	if m != nil {
+		return m.Polling
+	}
+	return false
+}
+
+type ModeMask struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Set                  uint32   `protobuf:"varint,2,opt,name=Set,proto3" json:"Set,omitempty"`
+	Clear                uint32   `protobuf:"varint,3,opt,name=Clear,proto3" json:"Clear,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModeMask) Reset()         { *m = ModeMask{} }
+func (m *ModeMask) String() string { return proto.CompactTextString(m) }
+func (*ModeMask) ProtoMessage()    {}
+func (*ModeMask) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{8}
+}
+
+func (m *ModeMask) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModeMask.Unmarshal(m, b)
+}
+func (m *ModeMask) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModeMask.Marshal(b, m, deterministic)
+}
+func (m *ModeMask) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModeMask.Merge(m, src)
+}
+func (m *ModeMask) XXX_Size() int {
+	return xxx_messageInfo_ModeMask.Size(m)
+}
+func (m *ModeMask) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModeMask.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModeMask proto.InternalMessageInfo
+
+func (m *ModeMask) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ModeMask) GetSet() uint32 {
+	if m != nil {
+		return m.Set
+	}
+	return 0
+}
+
+func (m *ModeMask) GetClear() uint32 {
+	if m != nil {
+		return m.Clear
+	}
+	return 0
+}
+
 type Command struct {
 	Cmd                  string   `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty"`
 	Args                 []string `protobuf:"bytes,2,rep,name=Args,proto3" json:"Args,omitempty"`
@@ -315,7 +693,7 @@ func (m *Command) Reset()         { *m = Command{} }
 func (m *Command) String() string { return proto.CompactTextString(m) }
 func (*Command) ProtoMessage()    {}
 func (*Command) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{3}
+	return fileDescriptor_eefc82927d57d89b, []int{9}
 }
 
 func (m *Command) XXX_Unmarshal(b []byte) error {
@@ -340,51 +718,326 @@ func (m *Command) GetCmd() string {
 	if m != nil {
 		return m.Cmd
 	}
-	return ""
+	return ""
+}
+
+func (m *Command) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+type PathsChecksum struct {
+	Checksums            []uint32 `protobuf:"varint,1,rep,packed,name=Checksums,proto3" json:"Checksums,omitempty"`
+	Hashed               []bool   `protobuf:"varint,2,rep,packed,name=Hashed,proto3" json:"Hashed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PathsChecksum) Reset()         { *m = PathsChecksum{} }
+func (m *PathsChecksum) String() string { return proto.CompactTextString(m) }
+func (*PathsChecksum) ProtoMessage()    {}
+func (*PathsChecksum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{10}
+}
+
+func (m *PathsChecksum) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PathsChecksum.Unmarshal(m, b)
+}
+func (m *PathsChecksum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PathsChecksum.Marshal(b, m, deterministic)
+}
+func (m *PathsChecksum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PathsChecksum.Merge(m, src)
+}
+func (m *PathsChecksum) XXX_Size() int {
+	return xxx_messageInfo_PathsChecksum.Size(m)
+}
+func (m *PathsChecksum) XXX_DiscardUnknown() {
+	xxx_messageInfo_PathsChecksum.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PathsChecksum proto.InternalMessageInfo
+
+func (m *PathsChecksum) GetChecksums() []uint32 {
+	if m != nil {
+		return m.Checksums
+	}
+	return nil
+}
+
+func (m *PathsChecksum) GetHashed() []bool {
+	if m != nil {
+		return m.Hashed
+	}
+	return nil
+}
+
+type SignatureRequest struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignatureRequest) Reset()         { *m = SignatureRequest{} }
+func (m *SignatureRequest) String() string { return proto.CompactTextString(m) }
+func (*SignatureRequest) ProtoMessage()    {}
+func (*SignatureRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{11}
+}
+
+func (m *SignatureRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignatureRequest.Unmarshal(m, b)
+}
+func (m *SignatureRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignatureRequest.Marshal(b, m, deterministic)
+}
+func (m *SignatureRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignatureRequest.Merge(m, src)
+}
+func (m *SignatureRequest) XXX_Size() int {
+	return xxx_messageInfo_SignatureRequest.Size(m)
+}
+func (m *SignatureRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignatureRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignatureRequest proto.InternalMessageInfo
+
+func (m *SignatureRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+type SignatureChunk struct {
+	BlockSize            int64             `protobuf:"varint,1,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Blocks               []*BlockSignature `protobuf:"bytes,2,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SignatureChunk) Reset()         { *m = SignatureChunk{} }
+func (m *SignatureChunk) String() string { return proto.CompactTextString(m) }
+func (*SignatureChunk) ProtoMessage()    {}
+func (*SignatureChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{12}
+}
+
+func (m *SignatureChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignatureChunk.Unmarshal(m, b)
+}
+func (m *SignatureChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignatureChunk.Marshal(b, m, deterministic)
+}
+func (m *SignatureChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignatureChunk.Merge(m, src)
+}
+func (m *SignatureChunk) XXX_Size() int {
+	return xxx_messageInfo_SignatureChunk.Size(m)
+}
+func (m *SignatureChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignatureChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignatureChunk proto.InternalMessageInfo
+
+func (m *SignatureChunk) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *SignatureChunk) GetBlocks() []*BlockSignature {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type BlockSignature struct {
+	Weak                 uint32   `protobuf:"varint,1,opt,name=Weak,proto3" json:"Weak,omitempty"`
+	Strong               []byte   `protobuf:"bytes,2,opt,name=Strong,proto3" json:"Strong,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=Size,proto3" json:"Size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockSignature) Reset()         { *m = BlockSignature{} }
+func (m *BlockSignature) String() string { return proto.CompactTextString(m) }
+func (*BlockSignature) ProtoMessage()    {}
+func (*BlockSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{13}
+}
+
+func (m *BlockSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockSignature.Unmarshal(m, b)
+}
+func (m *BlockSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockSignature.Marshal(b, m, deterministic)
+}
+func (m *BlockSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockSignature.Merge(m, src)
+}
+func (m *BlockSignature) XXX_Size() int {
+	return xxx_messageInfo_BlockSignature.Size(m)
+}
+func (m *BlockSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockSignature proto.InternalMessageInfo
+
+func (m *BlockSignature) GetWeak() uint32 {
+	if m != nil {
+		return m.Weak
+	}
+	return 0
+}
+
+func (m *BlockSignature) GetStrong() []byte {
+	if m != nil {
+		return m.Strong
+	}
+	return nil
+}
+
+func (m *BlockSignature) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type DeltaChunk struct {
+	Path                 string            `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	MtimeUnix            int64             `protobuf:"varint,2,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
+	Mode                 uint32            `protobuf:"varint,3,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	BlockSize            int64             `protobuf:"varint,4,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Operations           []*DeltaOperation `protobuf:"bytes,5,rep,name=Operations,proto3" json:"Operations,omitempty"`
+	Sha256               []byte            `protobuf:"bytes,6,opt,name=Sha256,proto3" json:"Sha256,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *DeltaChunk) Reset()         { *m = DeltaChunk{} }
+func (m *DeltaChunk) String() string { return proto.CompactTextString(m) }
+func (*DeltaChunk) ProtoMessage()    {}
+func (*DeltaChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{14}
+}
+
+func (m *DeltaChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeltaChunk.Unmarshal(m, b)
+}
+func (m *DeltaChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeltaChunk.Marshal(b, m, deterministic)
+}
+func (m *DeltaChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeltaChunk.Merge(m, src)
+}
+func (m *DeltaChunk) XXX_Size() int {
+	return xxx_messageInfo_DeltaChunk.Size(m)
+}
+func (m *DeltaChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeltaChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeltaChunk proto.InternalMessageInfo
+
+func (m *DeltaChunk) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *DeltaChunk) GetMtimeUnix() int64 {
+	if m != nil {
+		return m.MtimeUnix
+	}
+	return 0
+}
+
+func (m *DeltaChunk) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *DeltaChunk) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *DeltaChunk) GetOperations() []*DeltaOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
 }
 
-func (m *Command) GetArgs() []string {
+func (m *DeltaChunk) GetSha256() []byte {
 	if m != nil {
-		return m.Args
+		return m.Sha256
 	}
 	return nil
 }
 
-type PathsChecksum struct {
-	Checksums            []uint32 `protobuf:"varint,1,rep,packed,name=Checksums,proto3" json:"Checksums,omitempty"`
+type DeltaOperation struct {
+	BlockIndex           int64    `protobuf:"varint,1,opt,name=BlockIndex,proto3" json:"BlockIndex,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *PathsChecksum) Reset()         { *m = PathsChecksum{} }
-func (m *PathsChecksum) String() string { return proto.CompactTextString(m) }
-func (*PathsChecksum) ProtoMessage()    {}
-func (*PathsChecksum) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{4}
+func (m *DeltaOperation) Reset()         { *m = DeltaOperation{} }
+func (m *DeltaOperation) String() string { return proto.CompactTextString(m) }
+func (*DeltaOperation) ProtoMessage()    {}
+func (*DeltaOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{15}
 }
 
-func (m *PathsChecksum) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_PathsChecksum.Unmarshal(m, b)
+func (m *DeltaOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeltaOperation.Unmarshal(m, b)
 }
-func (m *PathsChecksum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_PathsChecksum.Marshal(b, m, deterministic)
+func (m *DeltaOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeltaOperation.Marshal(b, m, deterministic)
 }
-func (m *PathsChecksum) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_PathsChecksum.Merge(m, src)
+func (m *DeltaOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeltaOperation.Merge(m, src)
 }
-func (m *PathsChecksum) XXX_Size() int {
-	return xxx_messageInfo_PathsChecksum.Size(m)
+func (m *DeltaOperation) XXX_Size() int {
+	return xxx_messageInfo_DeltaOperation.Size(m)
 }
-func (m *PathsChecksum) XXX_DiscardUnknown() {
-	xxx_messageInfo_PathsChecksum.DiscardUnknown(m)
+func (m *DeltaOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeltaOperation.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_PathsChecksum proto.InternalMessageInfo
+var xxx_messageInfo_DeltaOperation proto.InternalMessageInfo
 
-func (m *PathsChecksum) GetChecksums() []uint32 {
+func (m *DeltaOperation) GetBlockIndex() int64 {
 	if m != nil {
-		return m.Checksums
+		return m.BlockIndex
+	}
+	return 0
+}
+
+func (m *DeltaOperation) GetData() []byte {
+	if m != nil {
+		return m.Data
 	}
 	return nil
 }
@@ -401,7 +1054,7 @@ func (m *Watch) Reset()         { *m = Watch{} }
 func (m *Watch) String() string { return proto.CompactTextString(m) }
 func (*Watch) ProtoMessage()    {}
 func (*Watch) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{5}
+	return fileDescriptor_eefc82927d57d89b, []int{16}
 }
 
 func (m *Watch) XXX_Unmarshal(b []byte) error {
@@ -447,7 +1100,7 @@ func (m *ChangeAmount) Reset()         { *m = ChangeAmount{} }
 func (m *ChangeAmount) String() string { return proto.CompactTextString(m) }
 func (*ChangeAmount) ProtoMessage()    {}
 func (*ChangeAmount) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{6}
+	return fileDescriptor_eefc82927d57d89b, []int{17}
 }
 
 func (m *ChangeAmount) XXX_Unmarshal(b []byte) error {
@@ -486,7 +1139,7 @@ func (m *ChangeChunk) Reset()         { *m = ChangeChunk{} }
 func (m *ChangeChunk) String() string { return proto.CompactTextString(m) }
 func (*ChangeChunk) ProtoMessage()    {}
 func (*ChangeChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{7}
+	return fileDescriptor_eefc82927d57d89b, []int{18}
 }
 
 func (m *ChangeChunk) XXX_Unmarshal(b []byte) error {
@@ -521,6 +1174,7 @@ type Change struct {
 	MtimeUnixNano        int64      `protobuf:"varint,4,opt,name=MtimeUnixNano,proto3" json:"MtimeUnixNano,omitempty"`
 	Size                 int64      `protobuf:"varint,5,opt,name=Size,proto3" json:"Size,omitempty"`
 	IsDir                bool       `protobuf:"varint,6,opt,name=IsDir,proto3" json:"IsDir,omitempty"`
+	Mode                 uint32     `protobuf:"varint,7,opt,name=Mode,proto3" json:"Mode,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
 	XXX_unrecognized     []byte     `json:"-"`
 	XXX_sizecache        int32      `json:"-"`
@@ -530,7 +1184,7 @@ func (m *Change) Reset()         { *m = Change{} }
 func (m *Change) String() string { return proto.CompactTextString(m) }
 func (*Change) ProtoMessage()    {}
 func (*Change) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{8}
+	return fileDescriptor_eefc82927d57d89b, []int{19}
 }
 
 func (m *Change) XXX_Unmarshal(b []byte) error {
@@ -593,6 +1247,13 @@ func (m *Change) GetIsDir() bool {
 	return false
 }
 
+func (m *Change) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
 type Paths struct {
 	Paths                []string `protobuf:"bytes,1,rep,name=Paths,proto3" json:"Paths,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -604,7 +1265,7 @@ func (m *Paths) Reset()         { *m = Paths{} }
 func (m *Paths) String() string { return proto.CompactTextString(m) }
 func (*Paths) ProtoMessage()    {}
 func (*Paths) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{9}
+	return fileDescriptor_eefc82927d57d89b, []int{20}
 }
 
 func (m *Paths) XXX_Unmarshal(b []byte) error {
@@ -643,7 +1304,7 @@ func (m *Chunk) Reset()         { *m = Chunk{} }
 func (m *Chunk) String() string { return proto.CompactTextString(m) }
 func (*Chunk) ProtoMessage()    {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{10}
+	return fileDescriptor_eefc82927d57d89b, []int{21}
 }
 
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
@@ -681,7 +1342,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{11}
+	return fileDescriptor_eefc82927d57d89b, []int{22}
 }
 
 func (m *Empty) XXX_Unmarshal(b []byte) error {
@@ -709,8 +1370,19 @@ func init() {
 	proto.RegisterType((*LogMessage)(nil), "remote.LogMessage")
 	proto.RegisterType((*SocketDataRequest)(nil), "remote.SocketDataRequest")
 	proto.RegisterType((*SocketDataResponse)(nil), "remote.SocketDataResponse")
+	proto.RegisterType((*SessionID)(nil), "remote.SessionID")
+	proto.RegisterType((*SessionOptions)(nil), "remote.SessionOptions")
+	proto.RegisterType((*UpstreamSessionOptions)(nil), "remote.UpstreamSessionOptions")
+	proto.RegisterType((*Owner)(nil), "remote.Owner")
+	proto.RegisterType((*DownstreamSessionOptions)(nil), "remote.DownstreamSessionOptions")
+	proto.RegisterType((*ModeMask)(nil), "remote.ModeMask")
 	proto.RegisterType((*Command)(nil), "remote.Command")
 	proto.RegisterType((*PathsChecksum)(nil), "remote.PathsChecksum")
+	proto.RegisterType((*SignatureRequest)(nil), "remote.SignatureRequest")
+	proto.RegisterType((*SignatureChunk)(nil), "remote.SignatureChunk")
+	proto.RegisterType((*BlockSignature)(nil), "remote.BlockSignature")
+	proto.RegisterType((*DeltaChunk)(nil), "remote.DeltaChunk")
+	proto.RegisterType((*DeltaOperation)(nil), "remote.DeltaOperation")
 	proto.RegisterType((*Watch)(nil), "remote.Watch")
 	proto.RegisterType((*ChangeAmount)(nil), "remote.ChangeAmount")
 	proto.RegisterType((*ChangeChunk)(nil), "remote.ChangeChunk")
@@ -723,58 +1395,93 @@ func init() {
 func init() { proto.RegisterFile("remote.proto", fileDescriptor_eefc82927d57d89b) }
 
 var fileDescriptor_eefc82927d57d89b = []byte{
-	// 811 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xdd, 0x8e, 0xdb, 0x44,
-	0x14, 0xce, 0xc4, 0xb1, 0x93, 0x9c, 0x24, 0xc5, 0x0c, 0x0b, 0x32, 0xab, 0x22, 0x05, 0xab, 0xaa,
-	0xac, 0xa5, 0x2c, 0x25, 0x55, 0xc5, 0xf5, 0xd6, 0x31, 0x4b, 0xa4, 0x6d, 0x76, 0x35, 0x49, 0xe8,
-	0xb5, 0x49, 0x46, 0x71, 0xb4, 0xb6, 0x27, 0x78, 0xc6, 0xa5, 0xe5, 0x69, 0x78, 0x08, 0xae, 0x79,
-	0x04, 0x6e, 0x79, 0x1d, 0x34, 0x3f, 0x4e, 0xec, 0x0d, 0x55, 0xb9, 0x3b, 0xdf, 0xf9, 0xcb, 0x77,
-	0xbe, 0x73, 0xc6, 0x81, 0x61, 0x41, 0x33, 0x26, 0xe8, 0xe5, 0xbe, 0x60, 0x82, 0x61, 0x47, 0x23,
-	0x7f, 0x09, 0x70, 0xc3, 0xb6, 0xaf, 0x29, 0xe7, 0xf1, 0x96, 0xe2, 0x67, 0xd0, 0x4b, 0xd9, 0xf6,
-	0x86, 0xbe, 0xa5, 0xa9, 0x87, 0xc6, 0x28, 0x78, 0x34, 0x71, 0x2f, 0x4d, 0xd9, 0x8d, 0xf1, 0x93,
-	0x43, 0x06, 0xf6, 0xa0, 0x9b, 0xe9, 0x42, 0xaf, 0x3d, 0x46, 0x41, 0x9f, 0x54, 0xd0, 0xff, 0x07,
-	0xc1, 0xa7, 0x0b, 0xb6, 0xbe, 0xa7, 0x62, 0x1a, 0x8b, 0x98, 0xd0, 0x5f, 0x4b, 0xca, 0x05, 0xc6,
-	0xd0, 0xd9, 0xb3, 0x42, 0xa8, 0xce, 0x36, 0x51, 0x36, 0x7e, 0x0c, 0xfd, 0x42, 0x87, 0x67, 0x1b,
-	0xd3, 0xe5, 0xe8, 0x68, 0xf0, 0xb1, 0x3e, 0xca, 0xe7, 0x19, 0x38, 0x7c, 0x9d, 0xd0, 0x8c, 0x7a,
-	0x1d, 0x95, 0x7b, 0x56, 0xe5, 0x2e, 0xcb, 0x3c, 0xa7, 0xe9, 0x42, 0xc5, 0x88, 0xc9, 0x91, 0x6c,
-	0x36, 0xb1, 0x88, 0x3d, 0x7b, 0x8c, 0x82, 0x21, 0x51, 0x36, 0x1e, 0xc3, 0x80, 0x27, 0xac, 0x4c,
-	0x37, 0x61, 0xca, 0x38, 0xf5, 0x9c, 0x31, 0x0a, 0x7a, 0xa4, 0xee, 0xf2, 0xff, 0x44, 0x80, 0xeb,
-	0x93, 0xf1, 0x3d, 0xcb, 0x39, 0xc5, 0x5f, 0x80, 0x93, 0xc4, 0x3c, 0x2a, 0x0a, 0x35, 0x5c, 0x8f,
-	0x18, 0x84, 0x27, 0x00, 0xe9, 0x41, 0x5e, 0x35, 0xdf, 0x60, 0x82, 0x6b, 0x23, 0x98, 0x08, 0xa9,
-	0x65, 0x35, 0x25, 0xb1, 0x1e, 0x4a, 0x52, 0xd1, 0xee, 0x7c, 0x98, 0xb6, 0x7d, 0x4a, 0xfb, 0x3b,
-	0xe8, 0x86, 0x2c, 0xcb, 0xe2, 0x7c, 0x83, 0x5d, 0xb0, 0xc2, 0x6c, 0xa3, 0x78, 0xf6, 0x89, 0x34,
-	0x65, 0xcb, 0xab, 0x62, 0xcb, 0xbd, 0xf6, 0xd8, 0x0a, 0xfa, 0x44, 0xd9, 0xfe, 0xb7, 0x30, 0xba,
-	0x8b, 0x45, 0xc2, 0xc3, 0x84, 0xae, 0xef, 0x79, 0x99, 0x49, 0x56, 0x95, 0xcd, 0x3d, 0x34, 0xb6,
-	0x82, 0x11, 0x39, 0x3a, 0xfc, 0x97, 0x60, 0xbf, 0x89, 0xc5, 0x3a, 0x91, 0xbd, 0x64, 0x9d, 0x69,
-	0xaf, 0x6c, 0x79, 0x27, 0xd1, 0xbb, 0x75, 0x5a, 0x6e, 0xa8, 0xf9, 0x89, 0x0a, 0xfa, 0x4f, 0x61,
-	0x18, 0x26, 0x71, 0xbe, 0xa5, 0x57, 0x19, 0x2b, 0x73, 0x21, 0x65, 0xd4, 0x96, 0xaa, 0xb7, 0x88,
-	0x41, 0xfe, 0x0f, 0x30, 0xd0, 0x79, 0x61, 0x52, 0xe6, 0xf7, 0x38, 0x80, 0xee, 0x5a, 0x41, 0xcd,
-	0x64, 0x30, 0x79, 0x54, 0x49, 0xaa, 0xb3, 0x48, 0x15, 0xf6, 0xff, 0x42, 0xe0, 0x68, 0x9f, 0x5c,
-	0x85, 0xb6, 0x96, 0xef, 0xf7, 0xd4, 0x5c, 0x37, 0x6e, 0xd6, 0xc9, 0x08, 0xa9, 0x65, 0x1d, 0xa6,
-	0x69, 0xd7, 0xa6, 0x79, 0x0c, 0xfd, 0xd7, 0x62, 0x97, 0xd1, 0x55, 0xbe, 0x7b, 0xa7, 0xd6, 0x63,
-	0x91, 0xa3, 0x03, 0x3f, 0x81, 0xd1, 0x01, 0xcc, 0xe3, 0x9c, 0xa9, 0x3d, 0x59, 0xa4, 0xe9, 0x94,
-	0x7d, 0x17, 0xbb, 0xdf, 0xf5, 0xa6, 0x2c, 0xa2, 0x6c, 0x7c, 0x06, 0xf6, 0x8c, 0x4f, 0x77, 0x85,
-	0xb9, 0x3a, 0x0d, 0xfc, 0xaf, 0xc0, 0x56, 0x7b, 0x90, 0x61, 0x65, 0xa8, 0x89, 0xfb, 0x44, 0x03,
-	0xff, 0x6b, 0xb0, 0xb5, 0x24, 0x9e, 0x5c, 0x70, 0x2e, 0xa8, 0x91, 0x6e, 0x48, 0x2a, 0xe8, 0x77,
-	0xc1, 0x8e, 0xb2, 0xbd, 0x78, 0x7f, 0x31, 0x85, 0x5e, 0xf5, 0x68, 0x70, 0x0f, 0x3a, 0xb3, 0xf9,
-	0x8f, 0xb7, 0x6e, 0x0b, 0x0f, 0xa0, 0xfb, 0x73, 0x44, 0x5e, 0xdd, 0x2e, 0x22, 0x17, 0xe1, 0x3e,
-	0xd8, 0xd3, 0xe8, 0xd5, 0xea, 0xda, 0x6d, 0x4b, 0xff, 0x9b, 0x2b, 0x32, 0x9f, 0xcd, 0xaf, 0x5d,
-	0x4b, 0xfa, 0x23, 0x42, 0x6e, 0x89, 0xdb, 0xb9, 0x18, 0xc3, 0xb0, 0xfe, 0x9c, 0x70, 0x17, 0xac,
-	0x65, 0x78, 0xe7, 0xb6, 0xa4, 0xb1, 0x9a, 0xde, 0xb9, 0xe8, 0xe2, 0x49, 0x5d, 0x68, 0x0c, 0xe0,
-	0x84, 0x3f, 0x5d, 0xcd, 0xaf, 0x23, 0xb7, 0x25, 0xed, 0x69, 0x74, 0x13, 0x2d, 0x23, 0x17, 0x4d,
-	0x16, 0xe0, 0xe8, 0x3e, 0x78, 0x06, 0x30, 0xcb, 0x77, 0xc2, 0xa0, 0x2f, 0xab, 0x95, 0x9c, 0x7c,
-	0x3f, 0xce, 0xcf, 0xff, 0x2b, 0xa4, 0x1f, 0xa0, 0xdf, 0x0a, 0xd0, 0x73, 0x34, 0xf9, 0x1b, 0x01,
-	0x4c, 0xd9, 0x6f, 0x39, 0x17, 0x05, 0x8d, 0x33, 0x7c, 0x09, 0x3d, 0x89, 0x52, 0x16, 0x6f, 0xf0,
-	0xa8, 0x2a, 0x56, 0xc2, 0x9d, 0x8f, 0x8e, 0x9b, 0x2f, 0xf3, 0x7b, 0x5d, 0x8e, 0xbf, 0x87, 0xae,
-	0x66, 0xce, 0x8f, 0xe9, 0x4a, 0xbb, 0xf3, 0xcf, 0x9a, 0x87, 0x62, 0x8a, 0x9e, 0x23, 0xfc, 0xb2,
-	0xba, 0x60, 0x1e, 0xaa, 0x0b, 0x7e, 0x50, 0x77, 0xd6, 0xac, 0x33, 0xe7, 0xdc, 0xc2, 0x4f, 0xa1,
-	0x73, 0xb7, 0xcb, 0xb7, 0x0f, 0xd3, 0x9b, 0xd0, 0x6f, 0x4d, 0xfe, 0x68, 0x43, 0x6f, 0xb5, 0x37,
-	0xe3, 0xbc, 0xa8, 0x3d, 0xc1, 0x87, 0xf3, 0x7c, 0xde, 0x80, 0x55, 0x9a, 0xdf, 0xc2, 0x17, 0xe0,
-	0xac, 0xf6, 0x4d, 0x05, 0x14, 0xfb, 0x93, 0xdf, 0x0a, 0x10, 0x9e, 0x80, 0x4b, 0x28, 0x17, 0x71,
-	0x21, 0xe4, 0xf1, 0xc4, 0xbb, 0x9c, 0x16, 0x1f, 0x63, 0x28, 0xfb, 0x13, 0x9a, 0xb1, 0xb7, 0xf4,
-	0x83, 0x0a, 0x1f, 0xfb, 0x7f, 0x23, 0x3f, 0x04, 0x74, 0x5d, 0x0a, 0x8a, 0x3f, 0x39, 0x90, 0xd1,
-	0x9f, 0xa5, 0xd3, 0xc6, 0xff, 0x53, 0xa2, 0x5f, 0x1c, 0xf5, 0x87, 0xf6, 0xe2, 0xdf, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xcd, 0xd4, 0x0d, 0x3a, 0xe0, 0x06, 0x00, 0x00,
+	// 1365 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0xcd, 0x6f, 0xdb, 0x36,
+	0x14, 0xb7, 0x22, 0x7f, 0x3e, 0x7f, 0x4c, 0x65, 0xb3, 0x40, 0xf3, 0xba, 0xc2, 0xe3, 0x8a, 0xc0,
+	0x48, 0x8b, 0x2c, 0x73, 0xd7, 0x0e, 0xd8, 0x69, 0xa9, 0xe5, 0xa6, 0x06, 0x92, 0xd8, 0xa0, 0x93,
+	0xf5, 0x36, 0x80, 0xb3, 0x09, 0x5b, 0xb0, 0x2c, 0x79, 0x22, 0x9d, 0xb6, 0x3b, 0x0c, 0xbb, 0xef,
+	0xdf, 0xd9, 0x69, 0xa7, 0xdd, 0x76, 0xdf, 0x65, 0xff, 0xce, 0x40, 0x8a, 0x92, 0x2c, 0xc5, 0x41,
+	0xdb, 0xdb, 0xfb, 0xf8, 0xbd, 0xc7, 0xf7, 0x45, 0xea, 0x09, 0x1a, 0x21, 0x5b, 0x05, 0x82, 0x1d,
+	0xaf, 0xc3, 0x40, 0x04, 0xa8, 0x1c, 0x71, 0xf8, 0x0a, 0xe0, 0x3c, 0x98, 0x5f, 0x30, 0xce, 0xe9,
+	0x9c, 0xa1, 0x27, 0x50, 0xf5, 0x82, 0xf9, 0x39, 0xbb, 0x61, 0x9e, 0x6d, 0x74, 0x8c, 0x6e, 0xab,
+	0x67, 0x1d, 0x6b, 0xb3, 0x73, 0x2d, 0x27, 0x09, 0x02, 0xd9, 0x50, 0x59, 0x45, 0x86, 0xf6, 0x5e,
+	0xc7, 0xe8, 0xd6, 0x48, 0xcc, 0xe2, 0xff, 0x0c, 0xb8, 0x37, 0x09, 0xa6, 0x4b, 0x26, 0x1c, 0x2a,
+	0x28, 0x61, 0xbf, 0x6c, 0x18, 0x17, 0x08, 0x41, 0x71, 0x1d, 0x84, 0x42, 0x79, 0x2e, 0x11, 0x45,
+	0xa3, 0x07, 0x50, 0x0b, 0x23, 0xf5, 0x70, 0xa6, 0xbd, 0xa4, 0x82, 0x4c, 0x3c, 0xe6, 0x7b, 0xe3,
+	0x79, 0x02, 0x65, 0x3e, 0x5d, 0xb0, 0x15, 0xb3, 0x8b, 0x0a, 0xbb, 0x1f, 0x63, 0xaf, 0x36, 0xbe,
+	0xcf, 0xbc, 0x89, 0xd2, 0x11, 0x8d, 0x91, 0xd1, 0xcc, 0xa8, 0xa0, 0x76, 0xa9, 0x63, 0x74, 0x1b,
+	0x44, 0xd1, 0xa8, 0x03, 0x75, 0xbe, 0x08, 0x36, 0xde, 0xac, 0xef, 0x05, 0x9c, 0xd9, 0xe5, 0x8e,
+	0xd1, 0xad, 0x92, 0x6d, 0x11, 0xfe, 0xd3, 0x00, 0xb4, 0x9d, 0x19, 0x5f, 0x07, 0x3e, 0x67, 0xe8,
+	0x00, 0xca, 0x0b, 0xca, 0x07, 0x61, 0xa8, 0x92, 0xab, 0x12, 0xcd, 0xa1, 0x1e, 0x80, 0x97, 0x94,
+	0x57, 0xe5, 0x57, 0xef, 0xa1, 0xad, 0x14, 0xb4, 0x86, 0x6c, 0xa1, 0xb2, 0x25, 0x31, 0xf3, 0x25,
+	0x89, 0xc3, 0x2e, 0xde, 0x1d, 0x76, 0xe9, 0x76, 0xd8, 0x9f, 0x43, 0x6d, 0xc2, 0x38, 0x77, 0x03,
+	0x7f, 0xe8, 0xa0, 0x16, 0xec, 0x0d, 0x1d, 0x15, 0x68, 0x8d, 0xec, 0x0d, 0x1d, 0xfc, 0x97, 0x01,
+	0x2d, 0xad, 0x1d, 0xad, 0x85, 0x1b, 0xf8, 0x3c, 0x0f, 0x41, 0xdf, 0x43, 0xf5, 0x7a, 0xcd, 0x45,
+	0xc8, 0xe8, 0x4a, 0x67, 0xf1, 0x30, 0xce, 0x22, 0x96, 0x67, 0x3d, 0x90, 0x04, 0x8f, 0x7e, 0x00,
+	0x70, 0x82, 0x37, 0xbe, 0xb6, 0x36, 0x95, 0x75, 0x27, 0xb6, 0x4e, 0x35, 0x39, 0xfb, 0x2d, 0x1b,
+	0xd4, 0x86, 0x2a, 0x61, 0x74, 0x36, 0xf2, 0xbd, 0x77, 0x2a, 0xef, 0x2a, 0x49, 0x78, 0xfc, 0x87,
+	0x09, 0x07, 0xbb, 0x43, 0x90, 0xa5, 0x1a, 0x53, 0xb1, 0xd0, 0x69, 0x28, 0x5a, 0xce, 0xec, 0xe0,
+	0xed, 0xd4, 0xdb, 0xcc, 0x64, 0x37, 0x4c, 0x39, 0xb3, 0x9a, 0x45, 0x8f, 0xa0, 0xf9, 0xd2, 0xf5,
+	0x58, 0x7f, 0x41, 0xfd, 0x39, 0xeb, 0xaf, 0xe2, 0xd2, 0x67, 0x85, 0xe8, 0x10, 0x5a, 0xa9, 0xe0,
+	0x34, 0x9c, 0x73, 0xbb, 0xa8, 0xdc, 0xe4, 0xa4, 0x08, 0x43, 0xc3, 0x71, 0xc3, 0x7e, 0xc8, 0xa8,
+	0x50, 0xce, 0x4a, 0xca, 0x59, 0x46, 0x26, 0x4f, 0x4c, 0x78, 0xe5, 0xaa, 0xac, 0x5c, 0x65, 0x85,
+	0xe8, 0x04, 0xee, 0x8f, 0x6e, 0x58, 0x18, 0xba, 0x33, 0x36, 0x66, 0xe1, 0xca, 0x55, 0x29, 0x72,
+	0xbb, 0xa2, 0xea, 0xb0, 0x4b, 0x25, 0xfd, 0x8e, 0x43, 0xc6, 0x59, 0x78, 0xc3, 0x2e, 0x82, 0x19,
+	0xe3, 0x76, 0x55, 0x61, 0xb3, 0x42, 0xf4, 0x15, 0x94, 0x46, 0x6f, 0x7c, 0x16, 0xda, 0x35, 0xd5,
+	0x91, 0x66, 0xdc, 0x11, 0x25, 0x24, 0x91, 0x0e, 0x1d, 0x43, 0x4d, 0xa2, 0x2f, 0x28, 0x5f, 0x72,
+	0x1b, 0x3a, 0x66, 0xb7, 0x9e, 0xde, 0xc0, 0x58, 0x41, 0x52, 0x08, 0x7e, 0xac, 0x9d, 0x22, 0x0b,
+	0xcc, 0x6b, 0x3d, 0x41, 0x26, 0x91, 0xa4, 0x94, 0x9c, 0x0d, 0x1d, 0x35, 0x3d, 0x26, 0x91, 0x24,
+	0xfe, 0x0d, 0xec, 0xbb, 0xda, 0xff, 0x91, 0xbd, 0x6b, 0x43, 0xf5, 0x6a, 0x11, 0x06, 0x42, 0x78,
+	0x4c, 0xb5, 0xcd, 0x24, 0x09, 0x2f, 0xad, 0xc6, 0x81, 0xe7, 0xb9, 0xfe, 0x5c, 0xcf, 0x4e, 0xcc,
+	0xe2, 0x97, 0x50, 0x8d, 0x23, 0xdf, 0x79, 0x9e, 0x05, 0xe6, 0x84, 0x09, 0x15, 0x71, 0x93, 0x48,
+	0x12, 0xed, 0x43, 0xa9, 0xef, 0x31, 0x1a, 0xaa, 0x43, 0x9a, 0x24, 0x62, 0xf0, 0xd7, 0x50, 0xe9,
+	0x07, 0xab, 0x15, 0xf5, 0x67, 0xd2, 0x44, 0x76, 0x3b, 0xf2, 0x22, 0x49, 0xe9, 0x58, 0xf5, 0x36,
+	0x8a, 0x58, 0xd1, 0x78, 0x00, 0x4d, 0x79, 0x00, 0xef, 0x2f, 0xd8, 0x74, 0xc9, 0x37, 0x2b, 0x79,
+	0xe5, 0x63, 0x9a, 0xdb, 0x46, 0xc7, 0xec, 0x36, 0x49, 0x2a, 0x90, 0x8f, 0xcb, 0x2b, 0xca, 0x17,
+	0x6c, 0xa6, 0x9c, 0x54, 0x89, 0xe6, 0xf0, 0x21, 0x58, 0x13, 0x77, 0xee, 0x53, 0xb1, 0x09, 0xd9,
+	0xd6, 0x1b, 0x9b, 0xcf, 0x03, 0xff, 0x04, 0xad, 0x04, 0xd7, 0x5f, 0x6c, 0xfc, 0xa5, 0x3c, 0xef,
+	0x85, 0x17, 0x4c, 0x97, 0x13, 0xf7, 0x57, 0xa6, 0x7b, 0x94, 0x0a, 0xd0, 0x31, 0x94, 0x15, 0x13,
+	0x05, 0x5d, 0xef, 0x1d, 0xc4, 0x1d, 0xd7, 0x90, 0xf8, 0x48, 0x8d, 0xc2, 0x63, 0x68, 0x65, 0x35,
+	0x32, 0x8a, 0xd7, 0x8c, 0x2e, 0x95, 0xeb, 0x26, 0x51, 0xb4, 0xcc, 0x62, 0x22, 0xc2, 0xc0, 0x9f,
+	0xab, 0x82, 0x36, 0x88, 0xe6, 0x24, 0x56, 0x85, 0x11, 0xf5, 0x4d, 0xd1, 0xf8, 0x6f, 0x03, 0xc0,
+	0x61, 0x9e, 0xa0, 0x51, 0xb8, 0xbb, 0x9a, 0xf3, 0x00, 0x6a, 0x17, 0xc2, 0x5d, 0xb1, 0x6b, 0xdf,
+	0x7d, 0xab, 0x87, 0x2a, 0x15, 0x48, 0x0b, 0xd9, 0x5a, 0xdd, 0x27, 0x45, 0x67, 0x93, 0x2e, 0xe6,
+	0x93, 0x7e, 0x0e, 0x30, 0x5a, 0xb3, 0x90, 0xaa, 0xf1, 0xb3, 0x4b, 0xd9, 0xc4, 0x55, 0x2c, 0x89,
+	0x9a, 0x6c, 0x21, 0x55, 0x5a, 0x0b, 0xda, 0x7b, 0xf6, 0xdc, 0x2e, 0xeb, 0xb4, 0x14, 0x87, 0x1d,
+	0x68, 0x65, 0xad, 0xd0, 0x43, 0x00, 0x75, 0xdc, 0xd0, 0x9f, 0xb1, 0xb7, 0xba, 0xea, 0x5b, 0x12,
+	0x19, 0xb3, 0xfc, 0xa6, 0xe8, 0xf2, 0x28, 0x1a, 0x3f, 0x83, 0xd2, 0x6b, 0x2a, 0xa6, 0x8b, 0x8f,
+	0xbb, 0x0f, 0xf8, 0x10, 0x1a, 0xfa, 0x2d, 0x5a, 0x05, 0x1b, 0x5f, 0xc8, 0x20, 0x23, 0x4a, 0x1f,
+	0xab, 0x39, 0xfc, 0x1d, 0xd4, 0xf5, 0xd3, 0xa6, 0xea, 0xdc, 0x85, 0xca, 0x54, 0xb1, 0xd1, 0x10,
+	0xd6, 0x7b, 0xad, 0xb8, 0x00, 0x11, 0x8a, 0xc4, 0x6a, 0xfc, 0xaf, 0x01, 0xe5, 0x48, 0x26, 0x3f,
+	0x71, 0x11, 0x75, 0xf5, 0x6e, 0xcd, 0xf4, 0xd6, 0x80, 0xb2, 0x76, 0x52, 0x43, 0xb6, 0x50, 0x49,
+	0x36, 0x7b, 0x77, 0x35, 0xd4, 0xcc, 0x37, 0xf4, 0x11, 0x34, 0x13, 0xe6, 0x92, 0xfa, 0x81, 0x6e,
+	0x60, 0x56, 0x98, 0xcc, 0x52, 0x29, 0x9d, 0x25, 0x79, 0x67, 0x87, 0xdc, 0x71, 0x43, 0xfd, 0x35,
+	0x8f, 0x98, 0x64, 0x40, 0x2a, 0xe9, 0x80, 0xe0, 0x2f, 0xa0, 0xa4, 0xae, 0x25, 0xda, 0xd7, 0x84,
+	0xaa, 0x42, 0x8d, 0x44, 0x0c, 0xfe, 0x12, 0x4a, 0x51, 0x99, 0x6c, 0x79, 0xdf, 0x7d, 0xc1, 0x74,
+	0x39, 0x1b, 0x24, 0x66, 0x71, 0x05, 0x4a, 0x83, 0xd5, 0x5a, 0xbc, 0x3b, 0x72, 0xa0, 0x1a, 0x2f,
+	0x28, 0xa8, 0x0a, 0xc5, 0xe1, 0xe5, 0xcb, 0x91, 0x55, 0x40, 0x75, 0xa8, 0xfc, 0x38, 0x20, 0x2f,
+	0x46, 0x93, 0x81, 0x65, 0xa0, 0x1a, 0x94, 0x9c, 0xc1, 0x8b, 0xeb, 0x33, 0x6b, 0x4f, 0xca, 0x5f,
+	0x9f, 0x92, 0xcb, 0xe1, 0xe5, 0x99, 0x65, 0x4a, 0xf9, 0x80, 0x90, 0x11, 0xb1, 0x8a, 0x47, 0x1d,
+	0x68, 0x6c, 0xaf, 0x2e, 0xa8, 0x02, 0xe6, 0x55, 0x7f, 0x6c, 0x15, 0x24, 0x71, 0xed, 0x8c, 0x2d,
+	0xe3, 0xe8, 0xd1, 0x76, 0xf1, 0x11, 0x40, 0xb9, 0xff, 0xea, 0xf4, 0xf2, 0x6c, 0x60, 0x15, 0x24,
+	0xed, 0x0c, 0xce, 0x07, 0x57, 0x03, 0xcb, 0xe8, 0x4d, 0xa0, 0x1c, 0xf9, 0x41, 0x43, 0x80, 0xa1,
+	0xef, 0x0a, 0xcd, 0x7d, 0x16, 0xb7, 0xe9, 0xd6, 0xae, 0xd6, 0x6e, 0xef, 0x52, 0x45, 0xcb, 0x0e,
+	0x2e, 0x74, 0x8d, 0x13, 0xa3, 0xf7, 0xfb, 0xde, 0xf6, 0x77, 0x1d, 0x1d, 0x43, 0x55, 0x72, 0x5e,
+	0x40, 0x67, 0x28, 0xf9, 0x96, 0xa8, 0xc2, 0xb5, 0x9b, 0xe9, 0x34, 0x6c, 0xfc, 0x65, 0x64, 0x8e,
+	0xbe, 0x81, 0x4a, 0x14, 0x39, 0x4f, 0xe1, 0xaa, 0x76, 0xed, 0xfb, 0xd9, 0xe1, 0xd1, 0x46, 0x27,
+	0x06, 0x7a, 0x16, 0x4f, 0x35, 0xef, 0xab, 0xa9, 0xce, 0xd9, 0xed, 0x67, 0xed, 0xf4, 0x88, 0x17,
+	0xd0, 0x49, 0x7c, 0x87, 0x3e, 0x0c, 0x7f, 0x62, 0xa0, 0x43, 0x28, 0x8e, 0x5d, 0x7f, 0x9e, 0x37,
+	0xc8, 0xb2, 0xb8, 0xd0, 0xfb, 0xc7, 0x4c, 0xd7, 0x22, 0xf4, 0x74, 0xeb, 0x0d, 0xcf, 0x57, 0xe0,
+	0xd3, 0x0c, 0x1b, 0xc3, 0x70, 0x01, 0x3d, 0x81, 0xe2, 0x44, 0x50, 0x91, 0xc7, 0xef, 0x2e, 0x01,
+	0x3a, 0x85, 0x5a, 0xfa, 0xc6, 0xda, 0x49, 0x87, 0x72, 0xdf, 0x80, 0xf6, 0xc1, 0x2d, 0x4d, 0x5a,
+	0xc3, 0x23, 0x28, 0x5f, 0xaf, 0xb3, 0x4d, 0x52, 0xca, 0x5b, 0xc9, 0x75, 0x0d, 0xf4, 0x2d, 0xd4,
+	0x23, 0xac, 0x7a, 0xc8, 0x10, 0xca, 0xbc, 0x86, 0x77, 0x5a, 0xf5, 0xc0, 0x22, 0x8c, 0x0b, 0x1a,
+	0x0a, 0x79, 0x2b, 0xa8, 0x2b, 0xb7, 0x81, 0xf7, 0x14, 0x52, 0x46, 0x45, 0xd8, 0x2a, 0xb8, 0x61,
+	0x77, 0x8e, 0x4e, 0xea, 0xff, 0xb1, 0x7c, 0xf5, 0xd8, 0x74, 0x23, 0x18, 0xfa, 0x24, 0x49, 0x21,
+	0xfa, 0xfc, 0xde, 0x76, 0xfc, 0xa1, 0x9d, 0x9c, 0x43, 0x45, 0x2f, 0x20, 0x72, 0x5c, 0x26, 0x32,
+	0x7a, 0x94, 0x96, 0x31, 0xb3, 0x9a, 0xec, 0x8a, 0xbe, 0x38, 0x11, 0xc1, 0x1a, 0xdd, 0xcb, 0x19,
+	0x0c, 0x9d, 0x5b, 0xd8, 0x9f, 0xcb, 0xea, 0xf7, 0xeb, 0xe9, 0xff, 0x03, 0x00, 0xf3, 0xba, 0xb2,
+	0x30, 0x8e, 0x0d, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -888,6 +1595,7 @@ type DownstreamClient interface {
 	Download(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadClient, error)
 	Changes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesClient, error)
 	ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error)
+	Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_WatchClient, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -971,6 +1679,38 @@ func (c *downstreamClient) ChangesCount(ctx context.Context, in *Empty, opts ...
 	return out, nil
 }
 
+func (c *downstreamClient) Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[2], "/remote.Downstream/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &downstreamWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Downstream_WatchClient interface {
+	Recv() (*ChangeAmount, error)
+	grpc.ClientStream
+}
+
+type downstreamWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamWatchClient) Recv() (*ChangeAmount, error) {
+	m := new(ChangeAmount)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *downstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/remote.Downstream/Ping", in, out, opts...)
@@ -985,6 +1725,7 @@ type DownstreamServer interface {
 	Download(Downstream_DownloadServer) error
 	Changes(*Empty, Downstream_ChangesServer) error
 	ChangesCount(context.Context, *Empty) (*ChangeAmount, error)
+	Watch(*Empty, Downstream_WatchServer) error
 	Ping(context.Context, *Empty) (*Empty, error)
 }
 
@@ -1057,6 +1798,27 @@ func _Downstream_ChangesCount_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Downstream_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DownstreamServer).Watch(m, &downstreamWatchServer{stream})
+}
+
+type Downstream_WatchServer interface {
+	Send(*ChangeAmount) error
+	grpc.ServerStream
+}
+
+type downstreamWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamWatchServer) Send(m *ChangeAmount) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Downstream_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1100,6 +1862,11 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Downstream_Changes_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Downstream_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "remote.proto",
 }
@@ -1109,7 +1876,10 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type UpstreamClient interface {
 	Checksums(ctx context.Context, in *Paths, opts ...grpc.CallOption) (*PathsChecksum, error)
+	Stat(ctx context.Context, in *Paths, opts ...grpc.CallOption) (*ChangeChunk, error)
+	Signature(ctx context.Context, in *SignatureRequest, opts ...grpc.CallOption) (Upstream_SignatureClient, error)
 	Upload(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadClient, error)
+	UploadDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadDeltaClient, error)
 	RestartContainer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error)
 	Execute(ctx context.Context, in *Command, opts ...grpc.CallOption) (*Empty, error)
@@ -1133,8 +1903,49 @@ func (c *upstreamClient) Checksums(ctx context.Context, in *Paths, opts ...grpc.
 	return out, nil
 }
 
+func (c *upstreamClient) Stat(ctx context.Context, in *Paths, opts ...grpc.CallOption) (*ChangeChunk, error) {
+	out := new(ChangeChunk)
+	err := c.cc.Invoke(ctx, "/remote.Upstream/Stat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *upstreamClient) Signature(ctx context.Context, in *SignatureRequest, opts ...grpc.CallOption) (Upstream_SignatureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[0], "/remote.Upstream/Signature", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamSignatureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Upstream_SignatureClient interface {
+	Recv() (*SignatureChunk, error)
+	grpc.ClientStream
+}
+
+type upstreamSignatureClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamSignatureClient) Recv() (*SignatureChunk, error) {
+	m := new(SignatureChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *upstreamClient) Upload(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[0], "/remote.Upstream/Upload", opts...)
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[1], "/remote.Upstream/Upload", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1167,6 +1978,40 @@ func (x *upstreamUploadClient) CloseAndRecv() (*Empty, error) {
 	return m, nil
 }
 
+func (c *upstreamClient) UploadDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadDeltaClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[2], "/remote.Upstream/UploadDelta", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamUploadDeltaClient{stream}
+	return x, nil
+}
+
+type Upstream_UploadDeltaClient interface {
+	Send(*DeltaChunk) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type upstreamUploadDeltaClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamUploadDeltaClient) Send(m *DeltaChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamUploadDeltaClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *upstreamClient) RestartContainer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/remote.Upstream/RestartContainer", in, out, opts...)
@@ -1177,7 +2022,7 @@ func (c *upstreamClient) RestartContainer(ctx context.Context, in *Empty, opts .
 }
 
 func (c *upstreamClient) Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[1], "/remote.Upstream/Remove", opts...)
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[3], "/remote.Upstream/Remove", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1231,7 +2076,10 @@ func (c *upstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallO
 // UpstreamServer is the server API for Upstream service.
 type UpstreamServer interface {
 	Checksums(context.Context, *Paths) (*PathsChecksum, error)
+	Stat(context.Context, *Paths) (*ChangeChunk, error)
+	Signature(*SignatureRequest, Upstream_SignatureServer) error
 	Upload(Upstream_UploadServer) error
+	UploadDelta(Upstream_UploadDeltaServer) error
 	RestartContainer(context.Context, *Empty) (*Empty, error)
 	Remove(Upstream_RemoveServer) error
 	Execute(context.Context, *Command) (*Empty, error)
@@ -1260,6 +2108,45 @@ func _Upstream_Checksums_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Upstream_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Paths)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpstreamServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Upstream/Stat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpstreamServer).Stat(ctx, req.(*Paths))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Upstream_Signature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SignatureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UpstreamServer).Signature(m, &upstreamSignatureServer{stream})
+}
+
+type Upstream_SignatureServer interface {
+	Send(*SignatureChunk) error
+	grpc.ServerStream
+}
+
+type upstreamSignatureServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamSignatureServer) Send(m *SignatureChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Upstream_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(UpstreamServer).Upload(&upstreamUploadServer{stream})
 }
@@ -1286,6 +2173,32 @@ func (x *upstreamUploadServer) Recv() (*Chunk, error) {
 	return m, nil
 }
 
+func _Upstream_UploadDelta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).UploadDelta(&upstreamUploadDeltaServer{stream})
+}
+
+type Upstream_UploadDeltaServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*DeltaChunk, error)
+	grpc.ServerStream
+}
+
+type upstreamUploadDeltaServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamUploadDeltaServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamUploadDeltaServer) Recv() (*DeltaChunk, error) {
+	m := new(DeltaChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Upstream_RestartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1374,6 +2287,10 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Checksums",
 			Handler:    _Upstream_Checksums_Handler,
 		},
+		{
+			MethodName: "Stat",
+			Handler:    _Upstream_Stat_Handler,
+		},
 		{
 			MethodName: "RestartContainer",
 			Handler:    _Upstream_RestartContainer_Handler,
@@ -1388,11 +2305,21 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Signature",
+			Handler:       _Upstream_Signature_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Upload",
 			Handler:       _Upstream_Upload_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "UploadDelta",
+			Handler:       _Upstream_UploadDelta_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "Remove",
 			Handler:       _Upstream_Remove_Handler,
@@ -1401,3 +2328,100 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "remote.proto",
 }
+
+// SessionClient is the client API for Session service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SessionClient interface {
+	Start(ctx context.Context, in *SessionOptions, opts ...grpc.CallOption) (*Empty, error)
+	Stop(ctx context.Context, in *SessionID, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type sessionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSessionClient(cc *grpc.ClientConn) SessionClient {
+	return &sessionClient{cc}
+}
+
+func (c *sessionClient) Start(ctx context.Context, in *SessionOptions, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/remote.Session/Start", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) Stop(ctx context.Context, in *SessionID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/remote.Session/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SessionServer is the server API for Session service.
+type SessionServer interface {
+	Start(context.Context, *SessionOptions) (*Empty, error)
+	Stop(context.Context, *SessionID) (*Empty, error)
+}
+
+func RegisterSessionServer(s *grpc.Server, srv SessionServer) {
+	s.RegisterService(&_Session_serviceDesc, srv)
+}
+
+func _Session_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Session/Start",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).Start(ctx, req.(*SessionOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Session/Stop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).Stop(ctx, req.(*SessionID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Session_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.Session",
+	HandlerType: (*SessionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _Session_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _Session_Stop_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "remote.proto",
+}
@@ -0,0 +1,117 @@
+package remote
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// SessionIDKey is the metadata key that holds the session id if multiple sync sessions share a
+// single helper connection
+const SessionIDKey = "devspace-session-id"
+
+// SessionIDFromContext returns the session id of an incoming request
+func SessionIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(SessionIDKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// NewSessionUpstreamClient creates a new upstream client that sends all requests to the given session
+func NewSessionUpstreamClient(cc *grpc.ClientConn, sessionID string) UpstreamClient {
+	return &sessionUpstreamClient{
+		client:    NewUpstreamClient(cc),
+		sessionID: sessionID,
+	}
+}
+
+type sessionUpstreamClient struct {
+	client    UpstreamClient
+	sessionID string
+}
+
+func (c *sessionUpstreamClient) ctx(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, SessionIDKey, c.sessionID)
+}
+
+func (c *sessionUpstreamClient) Checksums(ctx context.Context, in *Paths, opts ...grpc.CallOption) (*PathsChecksum, error) {
+	return c.client.Checksums(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionUpstreamClient) Stat(ctx context.Context, in *Paths, opts ...grpc.CallOption) (*ChangeChunk, error) {
+	return c.client.Stat(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionUpstreamClient) Signature(ctx context.Context, in *SignatureRequest, opts ...grpc.CallOption) (Upstream_SignatureClient, error) {
+	return c.client.Signature(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionUpstreamClient) Upload(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadClient, error) {
+	return c.client.Upload(c.ctx(ctx), opts...)
+}
+
+func (c *sessionUpstreamClient) UploadDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadDeltaClient, error) {
+	return c.client.UploadDelta(c.ctx(ctx), opts...)
+}
+
+func (c *sessionUpstreamClient) RestartContainer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	return c.client.RestartContainer(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionUpstreamClient) Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error) {
+	return c.client.Remove(c.ctx(ctx), opts...)
+}
+
+func (c *sessionUpstreamClient) Execute(ctx context.Context, in *Command, opts ...grpc.CallOption) (*Empty, error) {
+	return c.client.Execute(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionUpstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	return c.client.Ping(c.ctx(ctx), in, opts...)
+}
+
+// NewSessionDownstreamClient creates a new downstream client that sends all requests to the given session
+func NewSessionDownstreamClient(cc *grpc.ClientConn, sessionID string) DownstreamClient {
+	return &sessionDownstreamClient{
+		client:    NewDownstreamClient(cc),
+		sessionID: sessionID,
+	}
+}
+
+type sessionDownstreamClient struct {
+	client    DownstreamClient
+	sessionID string
+}
+
+func (c *sessionDownstreamClient) ctx(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, SessionIDKey, c.sessionID)
+}
+
+func (c *sessionDownstreamClient) Download(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadClient, error) {
+	return c.client.Download(c.ctx(ctx), opts...)
+}
+
+func (c *sessionDownstreamClient) Changes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesClient, error) {
+	return c.client.Changes(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionDownstreamClient) ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error) {
+	return c.client.ChangesCount(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionDownstreamClient) Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_WatchClient, error) {
+	return c.client.Watch(c.ctx(ctx), in, opts...)
+}
+
+func (c *sessionDownstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	return c.client.Ping(c.ctx(ctx), in, opts...)
+}
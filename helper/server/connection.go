@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/util/stderrlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+type connectionKey struct{}
+
+// connectionHandler tracks the client connections of a session server that listens on a tcp address
+type connectionHandler struct {
+	sessions *Sessions
+
+	activeMutex sync.Mutex
+	active      int
+	lastActive  time.Time
+}
+
+// TagRPC implements the stats handler interface
+func (h *connectionHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements the stats handler interface
+func (h *connectionHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {}
+
+// TagConn implements the stats handler interface
+func (h *connectionHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if info.RemoteAddr == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, connectionKey{}, info.RemoteAddr.String())
+}
+
+// HandleConn implements the stats handler interface
+func (h *connectionHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	h.activeMutex.Lock()
+	defer h.activeMutex.Unlock()
+
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.active++
+	case *stats.ConnEnd:
+		h.active--
+		h.lastActive = time.Now()
+
+		if connection, ok := ctx.Value(connectionKey{}).(string); ok {
+			h.sessions.stopConnection(connection)
+		}
+	}
+}
+
+// stopOnIdle stops the server if there was no open connection for the given timeout
+func (h *connectionHandler) stopOnIdle(s *grpc.Server, idleTimeout time.Duration) {
+	for {
+		time.Sleep(idleTimeout / 10)
+
+		h.activeMutex.Lock()
+		idle := h.active == 0 && time.Since(h.lastActive) > idleTimeout
+		h.activeMutex.Unlock()
+		if idle {
+			stderrlog.Logf("Stop server, because there was no connection for %s", idleTimeout.String())
+			s.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/delta"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// signatureChunkSize is the amount of block signatures sent within a single chunk
+const signatureChunkSize = 1024
+
+// Signature calculates the block signatures of an existing file and streams them to the client.
+// If the file does not exist, no chunks are sent
+func (u *Upstream) Signature(request *remote.SignatureRequest, stream remote.Upstream_SignatureServer) error {
+	absolutePath := filepath.Join(u.options.UploadPath, request.Path)
+	stat, err := os.Stat(absolutePath)
+	if err != nil || !stat.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(absolutePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	signature, err := delta.CreateSignature(f, delta.BlockSizeFor(stat.Size()))
+	if err != nil {
+		return errors.Wrapf(err, "create signature %s", request.Path)
+	}
+
+	chunk := &remote.SignatureChunk{BlockSize: signature.BlockSize}
+	for _, block := range signature.Blocks {
+		chunk.Blocks = append(chunk.Blocks, &remote.BlockSignature{
+			Weak:   block.Weak,
+			Strong: block.Strong,
+			Size:   int64(block.Size),
+		})
+
+		if len(chunk.Blocks) >= signatureChunkSize {
+			err = stream.Send(chunk)
+			if err != nil {
+				return errors.Wrap(err, "send signature")
+			}
+
+			chunk = &remote.SignatureChunk{BlockSize: signature.BlockSize}
+		}
+	}
+
+	return stream.Send(chunk)
+}
+
+// UploadDelta receives the delta operations for a single file and rebuilds the file
+// from the existing file and the received operations. If the existing file was changed since
+// the signature was created, the rebuilt file does not match the checksum of the header and a
+// FailedPrecondition error is returned, so that the client uploads the complete file instead
+func (u *Upstream) UploadDelta(stream remote.Upstream_UploadDeltaServer) error {
+	var (
+		header   *remote.DeltaChunk
+		base     *os.File
+		tempFile *os.File
+		hasher   hash.Hash
+	)
+	defer func() {
+		if base != nil {
+			base.Close()
+		}
+		if tempFile != nil {
+			tempFile.Close()
+			_ = os.Remove(tempFile.Name())
+		}
+	}()
+
+	for {
+		chunk, err := stream.Recv()
+		if chunk != nil {
+			if header == nil {
+				header = chunk
+
+				absolutePath := filepath.Join(u.options.UploadPath, header.Path)
+				base, err = os.Open(absolutePath)
+				if err != nil {
+					return errors.Wrapf(err, "open %s", absolutePath)
+				}
+
+				tempFile, err = ioutil.TempFile(filepath.Dir(absolutePath), ".devspace-delta-")
+				if err != nil {
+					return errors.Wrapf(err, "create temp file for %s", absolutePath)
+				}
+
+				hasher = sha256.New()
+			}
+
+			operations := make([]*delta.Operation, 0, len(chunk.Operations))
+			for _, op := range chunk.Operations {
+				operations = append(operations, &delta.Operation{
+					BlockIndex: op.BlockIndex,
+					Data:       op.Data,
+				})
+			}
+
+			patchErr := delta.Patch(base, header.BlockSize, operations, io.MultiWriter(tempFile, hasher))
+			if patchErr != nil {
+				return status.Errorf(codes.FailedPrecondition, "patch %s: %v", header.Path, patchErr)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if header == nil {
+		return stream.SendAndClose(&remote.Empty{})
+	}
+
+	err := tempFile.Close()
+	if err != nil {
+		return errors.Wrap(err, "close temp file")
+	} else if !bytes.Equal(hasher.Sum(nil), header.Sha256) {
+		return status.Errorf(codes.FailedPrecondition, "checksum mismatch of patched file %s", header.Path)
+	}
+
+	absolutePath := filepath.Join(u.options.UploadPath, header.Path)
+	stat, _ := os.Stat(absolutePath)
+	err = os.Rename(tempFile.Name(), absolutePath)
+	if err != nil {
+		return errors.Wrapf(err, "rename %s", absolutePath)
+	}
+	tempFile = nil
+
+	err = applyFileChange(header.Path, absolutePath, stat, os.FileMode(header.Mode), time.Unix(header.MtimeUnix, 0), u.options)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&remote.Empty{})
+}
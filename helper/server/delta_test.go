@@ -0,0 +1,220 @@
+//go:build !windows
+// +build !windows
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/helper/util/delta"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUploadDelta(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	oldData := random(64 * 1024)
+	newData := append(append([]byte{}, oldData[:10000]...), random(100)...)
+	newData = append(newData, oldData[10000:]...)
+
+	err = ioutil.WriteFile(filepath.Join(toDir, "test.bin"), oldData, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startDeltaUpstream(t, toDir)
+
+	// missing files should not return a signature
+	signatureClient, err := client.Signature(context.Background(), &remote.SignatureRequest{Path: "missing.bin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = signatureClient.Recv()
+	if err != io.EOF {
+		t.Fatalf("Expected no signature for missing file, got %v", err)
+	}
+
+	// retrieve the signature of the existing file
+	signature := getSignature(t, client, "test.bin")
+	if signature.BlockSize != delta.MinBlockSize || len(signature.Blocks) != 32 {
+		t.Fatalf("Unexpected signature with block size %d and %d blocks", signature.BlockSize, len(signature.Blocks))
+	}
+
+	// upload the delta
+	err = uploadDelta(t, client, "test.bin", signature, newData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(toDir, "test.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, newData) {
+		t.Fatal("Uploaded delta does not match the new file")
+	}
+
+	stat, err := os.Stat(filepath.Join(toDir, "test.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.ModTime().Unix() != 1000 {
+		t.Fatalf("Expected mtime 1000, got %d", stat.ModTime().Unix())
+	}
+
+	// no temporary files should be left over
+	files, err := ioutil.ReadDir(toDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file in upload dir, got %d", len(files))
+	}
+}
+
+func TestUploadDeltaChangedRemoteFile(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	oldData := random(64 * 1024)
+	newData := append(append([]byte{}, oldData[:10000]...), random(100)...)
+	newData = append(newData, oldData[10000:]...)
+
+	err = ioutil.WriteFile(filepath.Join(toDir, "test.bin"), oldData, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startDeltaUpstream(t, toDir)
+	signature := getSignature(t, client, "test.bin")
+
+	// the file is changed in the container after the signature was created
+	changedData := random(64 * 1024)
+	err = ioutil.WriteFile(filepath.Join(toDir, "test.bin"), changedData, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = uploadDelta(t, client, "test.bin", signature, newData)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected a failed precondition error, got %v", err)
+	}
+
+	// the changed file must not be replaced and no temporary files should be left over
+	data, err := ioutil.ReadFile(filepath.Join(toDir, "test.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, changedData) {
+		t.Fatal("Changed remote file was replaced by the patched file")
+	}
+
+	files, err := ioutil.ReadDir(toDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file in upload dir, got %d", len(files))
+	}
+}
+
+func startDeltaUpstream(t *testing.T, uploadPath string) remote.UpstreamClient {
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		err := StartUpstreamServer(serverReader, clientWriter, &UpstreamOptions{
+			UploadPath:  uploadPath,
+			ExludePaths: nil,
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return remote.NewUpstreamClient(conn)
+}
+
+func getSignature(t *testing.T, client remote.UpstreamClient, path string) *delta.Signature {
+	signatureClient, err := client.Signature(context.Background(), &remote.SignatureRequest{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := &delta.Signature{}
+	for {
+		chunk, err := signatureClient.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		signature.BlockSize = chunk.BlockSize
+		for _, block := range chunk.Blocks {
+			signature.Blocks = append(signature.Blocks, delta.BlockSignature{
+				Weak:   block.Weak,
+				Strong: block.Strong,
+				Size:   int(block.Size),
+			})
+		}
+	}
+
+	return signature
+}
+
+func uploadDelta(t *testing.T, client remote.UpstreamClient, path string, signature *delta.Signature, newData []byte) error {
+	checksum := sha256.Sum256(newData)
+	chunk := &remote.DeltaChunk{
+		Path:      path,
+		MtimeUnix: 1000,
+		Mode:      0644,
+		BlockSize: signature.BlockSize,
+		Sha256:    checksum[:],
+	}
+	err := delta.Diff(signature, bytes.NewReader(newData), func(operation *delta.Operation) error {
+		chunk.Operations = append(chunk.Operations, &remote.DeltaOperation{
+			BlockIndex: operation.BlockIndex,
+			Data:       operation.Data,
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uploadClient, err := client.UploadDelta(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = uploadClient.Send(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = uploadClient.CloseAndRecv()
+	return err
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -42,55 +41,20 @@ func StartDownstreamServer(reader io.Reader, writer io.Writer, options *Downstre
 	lis := util.NewStdinListener()
 	done := make(chan error)
 
-	// Compile ignore paths
-	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExcludePaths)
+	downStream, err := newDownstream(options)
 	if err != nil {
-		return errors.Wrap(err, "compile paths")
+		return err
 	}
 
 	go func() {
 		s := grpc.NewServer()
-		downStream := &Downstream{
-			options:       options,
-			ignoreMatcher: ignoreMatcher,
-			events:        make(chan notify.EventInfo, 1000),
-			changes:       map[string]bool{},
-		}
 
 		remote.RegisterDownstreamServer(s, downStream)
 		reflection.Register(s)
 
 		// start watcher if this we should use it
 		watchStop := make(chan struct{})
-		if !options.Polling {
-			stderrlog.Logf("Use inotify as watching method in container")
-
-			go func() {
-				// set up a watchpoint listening for events within a directory tree rooted at specified directory
-				err := notify.WatchWithFilter(options.RemotePath+"/...", downStream.events, func(s string) bool {
-					if ignoreMatcher == nil || ignoreMatcher.RequireFullScan() {
-						return false
-					}
-
-					stat, err := os.Stat(s)
-					if err != nil {
-						return false
-					}
-
-					return ignoreMatcher.Matches(s[len(options.RemotePath):], stat.IsDir())
-				}, notify.All)
-				if err != nil {
-					log.Fatalf("error watching path %s: %v", options.RemotePath, err)
-					return
-				}
-				defer notify.Stop(downStream.events)
-
-				// start the watch loop
-				downStream.watch(watchStop)
-			}()
-		} else {
-			stderrlog.Logf("Use polling as watching method in container")
-		}
+		downStream.startWatcher(watchStop)
 
 		done <- s.Serve(lis)
 		close(watchStop)
@@ -100,6 +64,60 @@ func StartDownstreamServer(reader io.Reader, writer io.Writer, options *Downstre
 	return <-done
 }
 
+// newDownstream creates a new downstream server implementation with the given options
+func newDownstream(options *DownstreamOptions) (*Downstream, error) {
+	// Compile ignore paths
+	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExcludePaths)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile paths")
+	}
+
+	return &Downstream{
+		options:       options,
+		ignoreMatcher: ignoreMatcher,
+		events:        make(chan notify.EventInfo, 1000),
+		changes:       map[string]bool{},
+		changed:       make(chan struct{}, 1),
+		watchFailed:   make(chan struct{}),
+	}, nil
+}
+
+// startWatcher starts watching the remote path for changes until stopChan is closed, if
+// polling is not enabled
+func (d *Downstream) startWatcher(stopChan chan struct{}) {
+	if d.options.Polling {
+		stderrlog.Logf("Use polling as watching method in container")
+		return
+	}
+
+	stderrlog.Logf("Use inotify as watching method in container")
+	go func() {
+		// set up a watchpoint listening for events within a directory tree rooted at specified directory
+		err := notify.WatchWithFilter(d.options.RemotePath+"/...", d.events, func(s string) bool {
+			if d.ignoreMatcher == nil || d.ignoreMatcher.RequireFullScan() {
+				return false
+			}
+
+			stat, err := os.Stat(s)
+			if err != nil {
+				return false
+			}
+
+			return d.ignoreMatcher.Matches(s[len(d.options.RemotePath):], stat.IsDir())
+		}, watchEvents)
+		if err != nil {
+			// this usually happens if the inotify watch limit is exceeded
+			stderrlog.Logf("Error watching path %s: %v, fall back to polling", d.options.RemotePath, err)
+			d.fallbackToPolling()
+			return
+		}
+		defer notify.Stop(d.events)
+
+		// start the watch loop
+		d.watch(stopChan)
+	}()
+}
+
 // Downstream is the implementation for the downstream server
 type Downstream struct {
 	options *DownstreamOptions
@@ -121,6 +139,13 @@ type Downstream struct {
 
 	// lastRescan is used to rescan the complete path from time to time
 	lastRescan *time.Time
+
+	// changed is notified when new changes are detected by the watcher
+	changed chan struct{}
+
+	// watchFailed is closed if the watcher could not be started and polling is used instead
+	watchFailed     chan struct{}
+	watchFailedOnce sync.Once
 }
 
 // Download sends the file at the temp download location to the client
@@ -206,29 +231,22 @@ func (d *Downstream) Ping(context.Context, *remote.Empty) (*remote.Empty, error)
 func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.ChangeAmount, error) {
 	newState := make(map[string]*remote.Change)
 	throttle := time.Duration(d.options.Throttle) * time.Millisecond
+	polling := d.isPolling()
 
 	// Walk through the dir
-	if d.options.Polling {
+	if polling {
 		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
 	}
 
 	changeAmount := int64(0)
-	if d.options.Polling {
+	if polling {
 		var err error
 		changeAmount, err = streamChanges(d.options.RemotePath, d.watchedFiles, newState, nil, throttle)
 		if err != nil {
 			return nil, errors.Wrap(err, "count changes")
 		}
 	} else {
-		d.changesMutex.Lock()
-		// if rescan is not set we make sure that we say that there
-		// are changes
-		if d.lastRescan == nil {
-			changeAmount = int64(1)
-		} else {
-			changeAmount = int64(len(d.changes))
-		}
-		d.changesMutex.Unlock()
+		changeAmount = d.watchChangesCount()
 	}
 
 	return &remote.ChangeAmount{
@@ -236,6 +254,69 @@ func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.Chang
 	}, nil
 }
 
+// Watch sends the amount of changes to the client as soon as the watcher detects new changes. If the
+// watcher is not available, an error is returned and the client should poll ChangesCount instead
+func (d *Downstream) Watch(empty *remote.Empty, stream remote.Downstream_WatchServer) error {
+	if d.isPolling() {
+		return errors.New("watching is not available in the container")
+	}
+
+	// send the current amount of changes first, so the client knows that watching is available
+	err := stream.Send(&remote.ChangeAmount{Amount: d.watchChangesCount()})
+	if err != nil {
+		return errors.Wrap(err, "send change amount")
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-d.watchFailed:
+			return errors.New("watching is not available in the container")
+		case <-time.After(rescanPeriod):
+			// make sure the client rescans from time to time
+		case <-d.changed:
+		}
+
+		err = stream.Send(&remote.ChangeAmount{Amount: d.watchChangesCount()})
+		if err != nil {
+			return errors.Wrap(err, "send change amount")
+		}
+	}
+}
+
+// watchChangesCount returns the amount of changes the watcher has detected
+func (d *Downstream) watchChangesCount() int64 {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
+
+	// if rescan is not set or due we make sure that we say that there are changes
+	if d.lastRescan == nil || d.lastRescan.Add(rescanPeriod).Before(time.Now()) {
+		return int64(1)
+	}
+
+	return int64(len(d.changes))
+}
+
+// isPolling returns true if changes are detected by walking the remote path
+func (d *Downstream) isPolling() bool {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
+
+	return d.options.Polling
+}
+
+// fallbackToPolling switches the change detection to polling if the watcher failed
+func (d *Downstream) fallbackToPolling() {
+	d.changesMutex.Lock()
+	d.options.Polling = true
+	d.changesMutex.Unlock()
+
+	d.watchFailedOnce.Do(func() {
+		close(d.watchFailed)
+	})
+}
+
 func (d *Downstream) getWatchState() map[string]*remote.Change {
 	d.changesMutex.Lock()
 	defer d.changesMutex.Unlock()
@@ -282,7 +363,7 @@ func (d *Downstream) Changes(empty *remote.Empty, stream remote.Downstream_Chang
 	throttle := time.Duration(d.options.Throttle) * time.Millisecond
 
 	// Walk through the dir
-	if !d.options.Polling {
+	if !d.isPolling() {
 		newState = d.getWatchState()
 	} else {
 		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
@@ -330,6 +411,12 @@ func (d *Downstream) watch(stopChan chan struct{}) {
 				}
 			}
 			d.changesMutex.Unlock()
+
+			// notify a watching client
+			select {
+			case d.changed <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
@@ -372,6 +459,7 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 				Size:          stat.Size(),
 				MtimeUnix:     stat.ModTime().Unix(),
 				MtimeUnixNano: stat.ModTime().UnixNano(),
+				Mode:          uint32(stat.Mode().Perm()),
 				IsDir:         false,
 			}
 		}
@@ -395,6 +483,7 @@ func copyState(state map[string]*remote.Change) map[string]*remote.Change {
 			MtimeUnix:     v.MtimeUnix,
 			MtimeUnixNano: v.MtimeUnixNano,
 			Size:          v.Size,
+			Mode:          v.Mode,
 			IsDir:         v.IsDir,
 		}
 	}
@@ -418,7 +507,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 		}
 
 		if oldFile, ok := oldState[newFile.Path]; ok {
-			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano {
+			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano || oldFile.Mode != newFile.Mode {
 				if stream != nil {
 					changes = append(changes, &remote.Change{
 						ChangeType:    remote.ChangeType_CHANGE,
@@ -426,6 +515,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 						MtimeUnix:     newFile.MtimeUnix,
 						MtimeUnixNano: newFile.MtimeUnixNano,
 						Size:          newFile.Size,
+						Mode:          newFile.Mode,
 						IsDir:         newFile.IsDir,
 					})
 				}
@@ -440,6 +530,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 					MtimeUnix:     newFile.MtimeUnix,
 					MtimeUnixNano: newFile.MtimeUnixNano,
 					Size:          newFile.Size,
+					Mode:          newFile.Mode,
 					IsDir:         newFile.IsDir,
 				})
 			}
@@ -547,6 +638,7 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 					Size:          stat.Size(),
 					MtimeUnix:     stat.ModTime().Unix(),
 					MtimeUnixNano: stat.ModTime().UnixNano(),
+					Mode:          uint32(stat.Mode().Perm()),
 					IsDir:         false,
 				}
 			}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/util"
@@ -207,3 +208,70 @@ func getAllChanges(changesClient remote.Downstream_ChangesClient) ([]*remote.Cha
 
 	return changes, nil
 }
+
+func TestDownstreamWatch(t *testing.T) {
+	fromDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fromDir)
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		err := StartDownstreamServer(serverReader, clientWriter, &DownstreamOptions{
+			RemotePath:  fromDir,
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	client := remote.NewDownstreamClient(conn)
+	watchClient, err := client.Watch(ctx, &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first message always requests a rescan
+	amount, err := watchClient.Recv()
+	if err != nil {
+		t.Fatal(err)
+	} else if amount.Amount == 0 {
+		t.Fatalf("Unexpected change amount, expected >0, got %d", amount.Amount)
+	}
+
+	changesClient, err := client.Changes(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = getAllChanges(changesClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change file
+	err = ioutil.WriteFile(filepath.Join(fromDir, "test.txt"), []byte("test"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		amount, err = watchClient.Recv()
+		if err != nil {
+			t.Fatal(err)
+		} else if amount.Amount > 0 {
+			break
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/helper/util/modemask"
+	"github.com/loft-sh/devspace/helper/util/stderrlog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+)
+
+// StartSessionServer starts a new server with the given reader and writer that serves multiple
+// sync sessions over a single connection. Upstream and downstream requests are routed to the
+// session that is specified in the request metadata
+func StartSessionServer(reader io.Reader, writer io.Writer, exitOnClose bool) error {
+	pipe := util.NewStdStreamJoint(reader, writer, exitOnClose)
+	lis := util.NewStdinListener()
+	done := make(chan error)
+
+	sessions := &Sessions{
+		sessions: map[string]*session{},
+	}
+
+	go func() {
+		s := newSessionServer(sessions)
+
+		done <- s.Serve(lis)
+		sessions.stopAll()
+	}()
+
+	lis.Ready(pipe)
+	return <-done
+}
+
+// StartSessionServerOnAddress starts the session server on the given tcp address. Sessions are
+// stopped as soon as the connection that started them is closed. If idle timeout is greater than
+// zero, the server exits if there was no open connection for the given duration
+func StartSessionServerOnAddress(address string, idleTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "listen on %s", address)
+	}
+
+	sessions := &Sessions{
+		sessions: map[string]*session{},
+	}
+	handler := &connectionHandler{
+		sessions:   sessions,
+		lastActive: time.Now(),
+	}
+
+	s := newSessionServer(sessions, grpc.StatsHandler(handler))
+	if idleTimeout > 0 {
+		go handler.stopOnIdle(s, idleTimeout)
+	}
+
+	stderrlog.Logf("Serve sync sessions on %s", lis.Addr().String())
+	err = s.Serve(lis)
+	sessions.stopAll()
+	return err
+}
+
+func newSessionServer(sessions *Sessions, options ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(options...)
+
+	remote.RegisterSessionServer(s, sessions)
+	remote.RegisterUpstreamServer(s, &sessionUpstream{sessions: sessions})
+	remote.RegisterDownstreamServer(s, &sessionDownstream{sessions: sessions})
+	reflection.Register(s)
+	return s
+}
+
+// Sessions is the implementation for the session server
+type Sessions struct {
+	sessionsMutex sync.Mutex
+	sessions      map[string]*session
+}
+
+type session struct {
+	upstream   *Upstream
+	downstream *Downstream
+
+	// readOnly sessions reject requests that change files in the container
+	readOnly bool
+
+	// connection is the address of the client that started the session
+	connection string
+
+	// watchStop stops the downstream watcher of the session
+	watchStop chan struct{}
+}
+
+// Start starts a new sync session with the given options. Read only sessions neither create the
+// path nor watch it and reject requests that would change files
+func (s *Sessions) Start(ctx context.Context, options *remote.SessionOptions) (*remote.Empty, error) {
+	if options.ID == "" {
+		return nil, errors.New("session id is missing")
+	} else if options.Upstream == nil || options.Downstream == nil {
+		return nil, errors.Errorf("session %s: upstream and downstream options are required", options.ID)
+	}
+
+	ensurePath := EnsurePath
+	if options.ReadOnly {
+		ensurePath = resolvePath
+	}
+
+	upstreamPath, err := ensurePath(options.Upstream.Path)
+	if err != nil {
+		return nil, err
+	}
+	downstreamPath, err := ensurePath(options.Downstream.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	upstream, err := newSessionUpstream(upstreamPath, options.Upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	downstream, err := newDownstream(&DownstreamOptions{
+		RemotePath:   downstreamPath,
+		ExcludePaths: options.Downstream.Exclude,
+		Throttle:     options.Downstream.Throttle,
+		Polling:      options.Downstream.Polling || options.ReadOnly,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create downstream")
+	}
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	if s.sessions[options.ID] != nil {
+		return nil, errors.Errorf("session %s already exists", options.ID)
+	}
+
+	stderrlog.Logf("Start session %s on %s", options.ID, downstreamPath)
+	newSession := &session{
+		upstream:   upstream,
+		downstream: downstream,
+		readOnly:   options.ReadOnly,
+		watchStop:  make(chan struct{}),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		newSession.connection = p.Addr.String()
+	}
+	newSession.downstream.startWatcher(newSession.watchStop)
+	s.sessions[options.ID] = newSession
+	return &remote.Empty{}, nil
+}
+
+// newSessionUpstream creates the upstream of a session that uploads to the given path
+func newSessionUpstream(upstreamPath string, options *remote.UpstreamSessionOptions) (*Upstream, error) {
+	modeMasks := make([]modemask.Mask, 0, len(options.ModeMasks))
+	for _, mask := range options.ModeMasks {
+		modeMasks = append(modeMasks, modemask.Mask{
+			Path:  mask.Path,
+			Set:   os.FileMode(mask.Set),
+			Clear: os.FileMode(mask.Clear),
+		})
+	}
+	compiledModeMasks, err := modemask.Compile(modeMasks)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamOptions := &UpstreamOptions{
+		UploadPath:  upstreamPath,
+		ExludePaths: options.Exclude,
+
+		FileChangeCmd:  options.FileChangeCmd,
+		FileChangeArgs: options.FileChangeArgs,
+
+		DirCreateCmd:  options.DirCreateCmd,
+		DirCreateArgs: options.DirCreateArgs,
+
+		OverridePermission: options.OverridePermissions,
+		PreserveModes:      options.PreserveModes,
+		ModeMasks:          compiledModeMasks,
+	}
+	if options.Owner != nil {
+		if options.Owner.UID >= 0 {
+			uid := int(options.Owner.UID)
+			upstreamOptions.UID = &uid
+		}
+		if options.Owner.GID >= 0 {
+			gid := int(options.Owner.GID)
+			upstreamOptions.GID = &gid
+		}
+	}
+
+	upstream, err := newUpstream(upstreamOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "create upstream")
+	}
+
+	return upstream, nil
+}
+
+// Stop stops the session with the given id
+func (s *Sessions) Stop(ctx context.Context, id *remote.SessionID) (*remote.Empty, error) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	existing := s.sessions[id.ID]
+	if existing == nil {
+		return nil, errors.Errorf("session %s not found", id.ID)
+	}
+
+	stderrlog.Logf("Stop session %s", id.ID)
+	close(existing.watchStop)
+	delete(s.sessions, id.ID)
+	return &remote.Empty{}, nil
+}
+
+func (s *Sessions) stopAll() {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	for id, existing := range s.sessions {
+		close(existing.watchStop)
+		delete(s.sessions, id)
+	}
+}
+
+// stopConnection stops all sessions that were started by the given client connection
+func (s *Sessions) stopConnection(connection string) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	for id, existing := range s.sessions {
+		if existing.connection != connection {
+			continue
+		}
+
+		stderrlog.Logf("Stop session %s, because connection %s was closed", id, connection)
+		close(existing.watchStop)
+		delete(s.sessions, id)
+	}
+}
+
+// get returns the session the request is sent to
+func (s *Sessions) get(ctx context.Context) (*session, error) {
+	id := remote.SessionIDFromContext(ctx)
+	if id == "" {
+		return nil, errors.New("session id is missing in request")
+	}
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	existing := s.sessions[id]
+	if existing == nil {
+		return nil, errors.Errorf("session %s not found", id)
+	}
+
+	return existing, nil
+}
+
+// sessionUpstream routes upstream requests to the upstream of the requested session
+type sessionUpstream struct {
+	sessions *Sessions
+}
+
+func (u *sessionUpstream) upstream(ctx context.Context) (*Upstream, error) {
+	s, err := u.sessions.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.upstream, nil
+}
+
+// writableUpstream returns the upstream of the requested session if the session is not read only
+func (u *sessionUpstream) writableUpstream(ctx context.Context) (*Upstream, error) {
+	s, err := u.sessions.get(ctx)
+	if err != nil {
+		return nil, err
+	} else if s.readOnly {
+		return nil, errors.Errorf("session %s is read only", remote.SessionIDFromContext(ctx))
+	}
+
+	return s.upstream, nil
+}
+
+// Checksums implements the server
+func (u *sessionUpstream) Checksums(ctx context.Context, paths *remote.Paths) (*remote.PathsChecksum, error) {
+	upstream, err := u.upstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return upstream.Checksums(ctx, paths)
+}
+
+// Stat implements the server
+func (u *sessionUpstream) Stat(ctx context.Context, paths *remote.Paths) (*remote.ChangeChunk, error) {
+	upstream, err := u.upstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return upstream.Stat(ctx, paths)
+}
+
+// Signature implements the server
+func (u *sessionUpstream) Signature(request *remote.SignatureRequest, stream remote.Upstream_SignatureServer) error {
+	upstream, err := u.upstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return upstream.Signature(request, stream)
+}
+
+// Upload implements the server
+func (u *sessionUpstream) Upload(stream remote.Upstream_UploadServer) error {
+	upstream, err := u.writableUpstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return upstream.Upload(stream)
+}
+
+// UploadDelta implements the server
+func (u *sessionUpstream) UploadDelta(stream remote.Upstream_UploadDeltaServer) error {
+	upstream, err := u.writableUpstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return upstream.UploadDelta(stream)
+}
+
+// RestartContainer implements the server
+func (u *sessionUpstream) RestartContainer(ctx context.Context, empty *remote.Empty) (*remote.Empty, error) {
+	upstream, err := u.writableUpstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return upstream.RestartContainer(ctx, empty)
+}
+
+// Remove implements the server
+func (u *sessionUpstream) Remove(stream remote.Upstream_RemoveServer) error {
+	upstream, err := u.writableUpstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return upstream.Remove(stream)
+}
+
+// Execute implements the server
+func (u *sessionUpstream) Execute(ctx context.Context, cmd *remote.Command) (*remote.Empty, error) {
+	upstream, err := u.writableUpstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return upstream.Execute(ctx, cmd)
+}
+
+// Ping implements the server
+func (u *sessionUpstream) Ping(ctx context.Context, empty *remote.Empty) (*remote.Empty, error) {
+	upstream, err := u.upstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return upstream.Ping(ctx, empty)
+}
+
+// sessionDownstream routes downstream requests to the downstream of the requested session
+type sessionDownstream struct {
+	sessions *Sessions
+}
+
+func (d *sessionDownstream) downstream(ctx context.Context) (*Downstream, error) {
+	s, err := d.sessions.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.downstream, nil
+}
+
+// Download implements the server
+func (d *sessionDownstream) Download(stream remote.Downstream_DownloadServer) error {
+	downstream, err := d.downstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return downstream.Download(stream)
+}
+
+// Changes implements the server
+func (d *sessionDownstream) Changes(empty *remote.Empty, stream remote.Downstream_ChangesServer) error {
+	downstream, err := d.downstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return downstream.Changes(empty, stream)
+}
+
+// ChangesCount implements the server
+func (d *sessionDownstream) ChangesCount(ctx context.Context, empty *remote.Empty) (*remote.ChangeAmount, error) {
+	downstream, err := d.downstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return downstream.ChangesCount(ctx, empty)
+}
+
+// Watch implements the server
+func (d *sessionDownstream) Watch(empty *remote.Empty, stream remote.Downstream_WatchServer) error {
+	downstream, err := d.downstream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return downstream.Watch(empty, stream)
+}
+
+// Ping implements the server
+func (d *sessionDownstream) Ping(ctx context.Context, empty *remote.Empty) (*remote.Empty, error) {
+	downstream, err := d.downstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return downstream.Ping(ctx, empty)
+}
+
+// EnsurePath creates the given path if it does not exist and returns the resolved absolute path
+func EnsurePath(path string) (string, error) {
+	// Create the directory if it does not exist
+	_, err := os.Stat(path)
+	if err != nil && os.IsNotExist(err) {
+		err := os.MkdirAll(path, 0755)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return resolvePath(path)
+}
+
+// resolvePath returns the resolved absolute path of the given path without creating it
+func resolvePath(path string) (string, error) {
+	// we have to resolve the real local path, because the watcher gives us the real path always
+	realLocalPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		realLocalPath = path
+	}
+
+	absolutePath, err := filepath.Abs(realLocalPath)
+	if err != nil {
+		return "", err
+	}
+
+	if absolutePath == "/" && path != "/" {
+		return "", fmt.Errorf("you are trying to sync the complete container root (/). By default this is not allowed, because this usually leads to unwanted behaviour. Please specify the correct container directory via the `--container-path` flag or `.containerPath` option")
+	}
+
+	return absolutePath, nil
+}
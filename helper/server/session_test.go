@@ -0,0 +1,251 @@
+//go:build !windows
+// +build !windows
+
+package server
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"google.golang.org/grpc"
+)
+
+func TestSessionServer(t *testing.T) {
+	dirs := []string{}
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		dirs = append(dirs, dir)
+	}
+
+	err := ioutil.WriteFile(filepath.Join(dirs[0], "first.txt"), []byte("first"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		err := StartSessionServer(serverReader, clientWriter, false)
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionClient := remote.NewSessionClient(conn)
+	for i, dir := range dirs {
+		_, err = sessionClient.Start(context.Background(), &remote.SessionOptions{
+			ID:         string(rune('a' + i)),
+			Upstream:   &remote.UpstreamSessionOptions{Path: dir},
+			Downstream: &remote.DownstreamSessionOptions{Path: dir, Polling: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// starting a session with the same id should fail
+	_, err = sessionClient.Start(context.Background(), &remote.SessionOptions{
+		ID:         "a",
+		Upstream:   &remote.UpstreamSessionOptions{Path: dirs[0]},
+		Downstream: &remote.DownstreamSessionOptions{Path: dirs[0]},
+	})
+	if err == nil {
+		t.Fatal("Expected error when starting an existing session")
+	}
+
+	// requests are routed to the path of the session
+	for id, expected := range map[string]remote.ChangeType{"a": remote.ChangeType_CHANGE, "b": remote.ChangeType_DELETE} {
+		stats, err := remote.NewSessionUpstreamClient(conn, id).Stat(context.Background(), &remote.Paths{Paths: []string{"first.txt"}})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(stats.Changes) != 1 || stats.Changes[0].ChangeType != expected {
+			t.Fatalf("Unexpected stat in session %s: %v", id, stats.Changes)
+		}
+	}
+
+	changesClient, err := remote.NewSessionDownstreamClient(conn, "a").Changes(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := getAllChanges(changesClient)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(changes) != 1 || changes[0].Path != "/first.txt" {
+		t.Fatalf("Unexpected changes in session a: %v", changes)
+	}
+
+	// stopped and unknown sessions should return an error
+	_, err = sessionClient.Stop(context.Background(), &remote.SessionID{ID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = remote.NewSessionUpstreamClient(conn, "a").Ping(context.Background(), &remote.Empty{})
+	if err == nil {
+		t.Fatal("Expected error for stopped session")
+	}
+	_, err = remote.NewUpstreamClient(conn).Ping(context.Background(), &remote.Empty{})
+	if err == nil {
+		t.Fatal("Expected error for request without session")
+	}
+	_, err = remote.NewSessionDownstreamClient(conn, "b").Ping(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadOnlySession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		err := StartSessionServer(serverReader, clientWriter, false)
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a read only session should not create the path
+	missingPath := filepath.Join(dir, "missing")
+	_, err = remote.NewSessionClient(conn).Start(context.Background(), &remote.SessionOptions{
+		ID:         "a",
+		Upstream:   &remote.UpstreamSessionOptions{Path: missingPath},
+		Downstream: &remote.DownstreamSessionOptions{Path: missingPath},
+		ReadOnly:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(missingPath)
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected read only session to not create %s: %v", missingPath, err)
+	}
+
+	changesClient, err := remote.NewSessionDownstreamClient(conn, "a").Changes(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := getAllChanges(changesClient)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(changes) != 0 {
+		t.Fatalf("Unexpected changes in read only session: %v", changes)
+	}
+	_, err = remote.NewSessionUpstreamClient(conn, "a").Checksums(context.Background(), &remote.Paths{Paths: []string{"/file"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// requests that change files should be rejected
+	_, err = remote.NewSessionUpstreamClient(conn, "a").Execute(context.Background(), &remote.Command{Cmd: "mkdir", Args: []string{missingPath}})
+	if err == nil {
+		t.Fatal("Expected error for execute in read only session")
+	}
+	_, err = os.Stat(missingPath)
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected read only session to not create %s: %v", missingPath, err)
+	}
+}
+
+func TestSessionServerOnAddress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// find a free port
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	lis.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- StartSessionServerOnAddress(address, time.Second)
+	}()
+
+	var conn *grpc.ClientConn
+	for i := 0; i < 50; i++ {
+		conn, err = grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(100*time.Millisecond))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = remote.NewSessionClient(conn).Start(context.Background(), &remote.SessionOptions{
+		ID:         "a",
+		Upstream:   &remote.UpstreamSessionOptions{Path: dir},
+		Downstream: &remote.DownstreamSessionOptions{Path: dir, Polling: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = remote.NewSessionUpstreamClient(conn, "a").Ping(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	// the session should be stopped with the connection that started it
+	conn, err = grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		_, err = remote.NewSessionUpstreamClient(conn, "a").Ping(context.Background(), &remote.Empty{})
+		if err != nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err == nil {
+		t.Fatal("Expected error for session of closed connection")
+	}
+	conn.Close()
+
+	// the server should exit without connections
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not stop after idle timeout")
+	}
+}
@@ -60,6 +60,12 @@ func createAllFolders(name string, perm os.FileMode, options *UpstreamOptions) e
 			return errors.Errorf("error creating %s: %v", dirToCreate, err)
 		}
 
+		// apply mode masks and owner to the new folder
+		if len(options.ModeMasks) > 0 && strings.HasPrefix(dirToCreate, options.UploadPath+"/") {
+			_ = os.Chmod(dirToCreate, options.ModeMasks.Apply(dirToCreate[len(options.UploadPath):], true, perm))
+		}
+		_ = forceOwner(dirToCreate, options)
+
 		if options.DirCreateCmd != "" {
 			cmdArgs := make([]string, 0, len(options.DirCreateArgs))
 			for _, arg := range options.DirCreateArgs {
@@ -106,6 +112,18 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 			return false, err
 		}
 
+		// set the folder mode if it should be preserved or masked
+		if options.PreserveModes || len(options.ModeMasks) > 0 {
+			mode := os.FileMode(0755)
+			if options.PreserveModes {
+				mode = header.FileInfo().Mode()
+			} else if stat != nil {
+				mode = stat.Mode()
+			}
+
+			_ = os.Chmod(outFileName, options.ModeMasks.Apply(relativePath, true, mode.Perm()))
+		}
+
 		return true, nil
 	}
 
@@ -129,25 +147,39 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 		return false, errors.Wrapf(err, "out file close %s", outFileName)
 	}
 
+	err = applyFileChange(relativePath, outFileName, stat, header.FileInfo().Mode(), header.FileInfo().ModTime(), options)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// applyFileChange sets the permissions, owner and mod time of a newly written file and
+// executes the file change command if configured
+func applyFileChange(relativePath, outFileName string, stat os.FileInfo, mode os.FileMode, mtime time.Time, options *UpstreamOptions) error {
 	// Set old permissions and owner and group
 	if stat != nil {
-		if options.OverridePermission {
+		if options.OverridePermission || options.PreserveModes {
 			// Set permissions
-			_ = os.Chmod(outFileName, header.FileInfo().Mode())
+			_ = os.Chmod(outFileName, options.ModeMasks.Apply(relativePath, false, mode))
 		} else {
 			// Set old permissions correctly
-			_ = os.Chmod(outFileName, stat.Mode())
+			_ = os.Chmod(outFileName, options.ModeMasks.Apply(relativePath, false, stat.Mode()))
 		}
 
 		// Set old owner & group correctly
 		_ = Chown(outFileName, stat)
 	} else {
 		// Set permissions
-		_ = os.Chmod(outFileName, header.FileInfo().Mode())
+		_ = os.Chmod(outFileName, options.ModeMasks.Apply(relativePath, false, mode))
 	}
 
-	// Set mod time from tar header
-	_ = os.Chtimes(outFileName, time.Now(), header.FileInfo().ModTime())
+	// Force owner & group
+	_ = forceOwner(outFileName, options)
+
+	// Set mod time
+	_ = os.Chtimes(outFileName, time.Now(), mtime)
 
 	// Execute command if defined
 	if options.FileChangeCmd != "" {
@@ -162,11 +194,28 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 
 		out, err := exec.Command(options.FileChangeCmd, cmdArgs...).CombinedOutput()
 		if err != nil {
-			return false, errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
+			return errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
 		}
 	}
 
-	return true, nil
+	return nil
+}
+
+// forceOwner sets the owner and group of the given path if configured
+func forceOwner(path string, options *UpstreamOptions) error {
+	if options.UID == nil && options.GID == nil {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if options.UID != nil {
+		uid = *options.UID
+	}
+	if options.GID != nil {
+		gid = *options.GID
+	}
+
+	return os.Chown(path, uid, gid)
 }
 
 func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, tw *tar.Writer, skipFolderContents bool) error {
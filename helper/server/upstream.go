@@ -13,6 +13,7 @@ import (
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/loft-sh/devspace/helper/util/modemask"
 	"github.com/loft-sh/devspace/helper/util/stderrlog"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -32,6 +33,16 @@ type UpstreamOptions struct {
 
 	OverridePermission bool
 	ExitOnClose        bool
+
+	// PreserveModes applies the modes of uploaded files and folders also if they already exist
+	PreserveModes bool
+
+	// UID and GID force the owner of uploaded files and folders if set
+	UID *int
+	GID *int
+
+	// ModeMasks are applied to the modes of uploaded files and folders
+	ModeMasks modemask.Masks
 }
 
 // StartUpstreamServer starts a new upstream server with the given reader and writer
@@ -40,19 +51,15 @@ func StartUpstreamServer(reader io.Reader, writer io.Writer, options *UpstreamOp
 	lis := util.NewStdinListener()
 	done := make(chan error)
 
-	// Compile ignore paths
-	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExludePaths)
+	upStream, err := newUpstream(options)
 	if err != nil {
-		return errors.Wrap(err, "compile paths")
+		return err
 	}
 
 	go func() {
 		s := grpc.NewServer()
 
-		remote.RegisterUpstreamServer(s, &Upstream{
-			options:       options,
-			ignoreMatcher: ignoreMatcher,
-		})
+		remote.RegisterUpstreamServer(s, upStream)
 		reflection.Register(s)
 
 		done <- s.Serve(lis)
@@ -62,6 +69,20 @@ func StartUpstreamServer(reader io.Reader, writer io.Writer, options *UpstreamOp
 	return <-done
 }
 
+// newUpstream creates a new upstream server implementation with the given options
+func newUpstream(options *UpstreamOptions) (*Upstream, error) {
+	// Compile ignore paths
+	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExludePaths)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile paths")
+	}
+
+	return &Upstream{
+		options:       options,
+		ignoreMatcher: ignoreMatcher,
+	}, nil
+}
+
 // Upstream is the implementation for the upstream server
 type Upstream struct {
 	options *UpstreamOptions
@@ -120,22 +141,60 @@ func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
 
 func (u *Upstream) Checksums(ctx context.Context, paths *remote.Paths) (*remote.PathsChecksum, error) {
 	if paths != nil {
-		checksums := make([]uint32, 0, len(paths.Paths))
+		absolutePaths := make([]string, 0, len(paths.Paths))
 		for _, path := range paths.Paths {
-			// Just remove everything inside and ignore any errors
-			absolutePath := filepath.Join(u.options.UploadPath, path)
-			checksum, err := crc32.Checksum(absolutePath)
+			absolutePaths = append(absolutePaths, filepath.Join(u.options.UploadPath, path))
+		}
+
+		// files that cannot be hashed are marked as not hashed
+		checksums, errs := crc32.Checksums(absolutePaths)
+		hashed := make([]bool, len(checksums))
+		for i, err := range errs {
+			hashed[i] = err == nil
 			if err != nil && !os.IsNotExist(err) {
-				stderrlog.Logf("Error checksum %s: %v", path, err)
+				stderrlog.Logf("Error checksum %s: %v", paths.Paths[i], err)
+			}
+		}
+
+		return &remote.PathsChecksum{Checksums: checksums, Hashed: hashed}, nil
+	}
+
+	return &remote.PathsChecksum{Checksums: []uint32{}, Hashed: []bool{}}, nil
+}
+
+// Stat returns the current state of the given paths. Paths that do not exist are returned as delete changes
+func (u *Upstream) Stat(ctx context.Context, paths *remote.Paths) (*remote.ChangeChunk, error) {
+	if paths == nil {
+		return &remote.ChangeChunk{Changes: []*remote.Change{}}, nil
+	}
+
+	changes := make([]*remote.Change, 0, len(paths.Paths))
+	for _, path := range paths.Paths {
+		stat, err := os.Stat(filepath.Join(u.options.UploadPath, path))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				stderrlog.Logf("Error stat %s: %v", path, err)
 			}
 
-			checksums = append(checksums, checksum)
+			changes = append(changes, &remote.Change{
+				ChangeType: remote.ChangeType_DELETE,
+				Path:       path,
+			})
+			continue
 		}
 
-		return &remote.PathsChecksum{Checksums: checksums}, nil
+		changes = append(changes, &remote.Change{
+			ChangeType:    remote.ChangeType_CHANGE,
+			Path:          path,
+			MtimeUnix:     stat.ModTime().Unix(),
+			MtimeUnixNano: stat.ModTime().UnixNano(),
+			Size:          stat.Size(),
+			Mode:          uint32(stat.Mode().Perm()),
+			IsDir:         stat.IsDir(),
+		})
 	}
 
-	return &remote.PathsChecksum{Checksums: []uint32{}}, nil
+	return &remote.ChangeChunk{Changes: changes}, nil
 }
 
 func (u *Upstream) removeRecursive(absolutePath string) error {
@@ -5,6 +5,7 @@ package server
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"io"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/helper/util/modemask"
 	"github.com/pkg/errors"
 )
 
@@ -273,3 +275,80 @@ func TestUpstreamServer(t *testing.T) {
 		t.Fatalf("Expected empty toDir, but still has %d entries", len(files))
 	}
 }
+
+func TestUpstreamModes(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	// existing file with other permissions
+	err = ioutil.WriteFile(filepath.Join(toDir, "existing.sh"), []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modeMasks, err := modemask.Compile([]modemask.Mask{
+		{Path: "*.sh", Set: 0100},
+		{Path: "/secret/", Clear: 0077},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(buf)
+	files := []struct {
+		name    string
+		mode    int64
+		content string
+		isDir   bool
+	}{
+		{name: "existing.sh", mode: 0750, content: "new"},
+		{name: "new.sh", mode: 0600, content: "new"},
+		{name: "secret", mode: 0755, isDir: true},
+		{name: "secret/key", mode: 0644, content: "key"},
+	}
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: file.mode, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if file.isDir {
+			header.Typeflag = tar.TypeDir
+		}
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tarWriter.Write([]byte(file.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarWriter.Close()
+
+	tarReader := tar.NewReader(buf)
+	options := &UpstreamOptions{UploadPath: toDir, PreserveModes: true, ModeMasks: modeMasks}
+	for {
+		more, err := untarNext(tarReader, options)
+		if err != nil {
+			t.Fatal(err)
+		} else if !more {
+			break
+		}
+	}
+
+	expected := map[string]os.FileMode{
+		"existing.sh": 0750,
+		"new.sh":      0700,
+		"secret":      0700,
+		"secret/key":  0600,
+	}
+	for name, mode := range expected {
+		stat, err := os.Stat(filepath.Join(toDir, name))
+		if err != nil {
+			t.Fatal(err)
+		} else if stat.Mode().Perm() != mode {
+			t.Fatalf("Unexpected mode of %s: %v != %v", name, stat.Mode().Perm(), mode)
+		}
+	}
+}
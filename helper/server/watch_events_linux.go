@@ -0,0 +1,7 @@
+package server
+
+import "github.com/loft-sh/notify"
+
+// watchEvents are the events the downstream watches for. Attribute changes are watched
+// as well, so that changed permission bits are picked up
+const watchEvents = notify.All | notify.InAttrib
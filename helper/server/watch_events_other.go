@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package server
+
+import "github.com/loft-sh/notify"
+
+// watchEvents are the events the downstream watches for
+const watchEvents = notify.All
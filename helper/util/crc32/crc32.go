@@ -4,6 +4,8 @@ import (
 	"hash/crc32"
 	"io"
 	"os"
+	"runtime"
+	"sync"
 )
 
 func Checksum(filename string) (uint32, error) {
@@ -21,3 +23,36 @@ func Checksum(filename string) (uint32, error) {
 
 	return tab.Sum32(), nil
 }
+
+// Checksums calculates the checksums of the given files in parallel. The checksum
+// and error of a file are at the same index as the file name
+func Checksums(filenames []string) ([]uint32, []error) {
+	checksums := make([]uint32, len(filenames))
+	errs := make([]error, len(filenames))
+
+	workers := runtime.NumCPU()
+	if workers > len(filenames) {
+		workers = len(filenames)
+	}
+
+	indexes := make(chan int)
+	waitGroup := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for i := range indexes {
+				checksums[i], errs[i] = Checksum(filenames[i])
+			}
+		}()
+	}
+
+	for i := range filenames {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	return checksums, errs
+}
@@ -0,0 +1,282 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinBlockSize is the smallest block size used for signatures
+	MinBlockSize = 2 * 1024
+
+	// MaxBlockSize is the biggest block size used for signatures
+	MaxBlockSize = 128 * 1024
+
+	// maxLiteralSize is the maximum amount of literal bytes that are sent within a single operation
+	maxLiteralSize = 64 * 1024
+
+	// mod is the modulus for the rolling checksum
+	mod = 1 << 16
+)
+
+// BlockSignature holds the weak rolling checksum and the strong hash of a single block
+type BlockSignature struct {
+	Weak   uint32
+	Strong []byte
+	Size   int
+}
+
+// Signature describes an existing file as a list of block signatures
+type Signature struct {
+	BlockSize int64
+	Blocks    []BlockSignature
+}
+
+// Operation is a single instruction to rebuild a file. Either Data is set, which means
+// the data should be written as is, or BlockIndex references a block of the old file
+type Operation struct {
+	BlockIndex int64
+	Data       []byte
+}
+
+// BlockSizeFor returns the block size that should be used for a file of the given size.
+// Similar to rsync the block size grows with the square root of the file size.
+func BlockSizeFor(size int64) int64 {
+	blockSize := int64(math.Sqrt(float64(size)))
+	if blockSize < MinBlockSize {
+		return MinBlockSize
+	} else if blockSize > MaxBlockSize {
+		return MaxBlockSize
+	}
+
+	// round to a multiple of 1024
+	return blockSize / 1024 * 1024
+}
+
+// CreateSignature reads the given reader and calculates the block signatures
+func CreateSignature(reader io.Reader, blockSize int64) (*Signature, error) {
+	if blockSize <= 0 {
+		return nil, errors.Errorf("invalid block size %d", blockSize)
+	}
+
+	signature := &Signature{
+		BlockSize: blockSize,
+		Blocks:    []BlockSignature{},
+	}
+
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			a, b := checksum(buf[:n])
+			strong := md5.Sum(buf[:n])
+			signature.Blocks = append(signature.Blocks, BlockSignature{
+				Weak:   a | b<<16,
+				Strong: strong[:],
+				Size:   n,
+			})
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return signature, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "read block")
+		}
+	}
+}
+
+// Diff compares the contents of reader to the given signature and calls emit for each
+// operation that is needed to rebuild the contents of reader from the old file
+func Diff(signature *Signature, reader io.Reader, emit func(operation *Operation) error) error {
+	blockSize := int(signature.BlockSize)
+	if blockSize <= 0 {
+		return errors.Errorf("invalid block size %d", blockSize)
+	}
+
+	// index the weak checksums
+	table := make(map[uint32][]int, len(signature.Blocks))
+	for idx, block := range signature.Blocks {
+		table[block.Weak] = append(table[block.Weak], idx)
+	}
+
+	var (
+		r       = bufio.NewReaderSize(reader, 64*1024)
+		data    = make([]byte, 4*blockSize)
+		literal = make([]byte, 0, maxLiteralSize)
+		start   = 0
+		end     = 0
+		eof     = false
+
+		a, b   uint32
+		rolled = false
+	)
+
+	// fill makes sure that there is a complete window in data if possible
+	fill := func() error {
+		if end-start >= blockSize || eof {
+			return nil
+		}
+
+		copy(data, data[start:end])
+		end -= start
+		start = 0
+
+		n, err := io.ReadFull(r, data[end:])
+		end += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			eof = true
+			return nil
+		}
+
+		return err
+	}
+	flush := func() error {
+		if len(literal) == 0 {
+			return nil
+		}
+
+		op := &Operation{Data: make([]byte, len(literal))}
+		copy(op.Data, literal)
+		literal = literal[:0]
+		return emit(op)
+	}
+	match := func(window []byte, weak uint32) int {
+		indexes, ok := table[weak]
+		if !ok {
+			return -1
+		}
+
+		strong := md5.Sum(window)
+		for _, idx := range indexes {
+			if signature.Blocks[idx].Size == len(window) && bytes.Equal(signature.Blocks[idx].Strong, strong[:]) {
+				return idx
+			}
+		}
+
+		return -1
+	}
+
+	err := fill()
+	if err != nil {
+		return errors.Wrap(err, "read")
+	}
+
+	for end-start >= blockSize {
+		window := data[start : start+blockSize]
+		if !rolled {
+			a, b = checksum(window)
+			rolled = true
+		}
+
+		if idx := match(window, a|b<<16); idx >= 0 {
+			err = flush()
+			if err != nil {
+				return err
+			}
+
+			err = emit(&Operation{BlockIndex: int64(idx)})
+			if err != nil {
+				return err
+			}
+
+			start += blockSize
+			rolled = false
+			err = fill()
+			if err != nil {
+				return errors.Wrap(err, "read")
+			}
+
+			continue
+		}
+
+		// move the window one byte further
+		out := data[start]
+		literal = append(literal, out)
+		if len(literal) >= maxLiteralSize {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+
+		start++
+		err = fill()
+		if err != nil {
+			return errors.Wrap(err, "read")
+		}
+
+		if end-start >= blockSize {
+			a, b = roll(a, b, out, data[start+blockSize-1], blockSize)
+		}
+	}
+
+	// the tail might still match the last (shorter) block
+	if end > start {
+		tail := data[start:end]
+		ta, tb := checksum(tail)
+		if idx := match(tail, ta|tb<<16); idx >= 0 {
+			err = flush()
+			if err != nil {
+				return err
+			}
+
+			err = emit(&Operation{BlockIndex: int64(idx)})
+			if err != nil {
+				return err
+			}
+		} else {
+			literal = append(literal, tail...)
+		}
+	}
+
+	return flush()
+}
+
+// Patch rebuilds a file from the old file contents and the given operations and writes it to writer
+func Patch(base io.ReaderAt, blockSize int64, operations []*Operation, writer io.Writer) error {
+	buf := make([]byte, blockSize)
+	for _, op := range operations {
+		if len(op.Data) > 0 {
+			_, err := writer.Write(op.Data)
+			if err != nil {
+				return errors.Wrap(err, "write data")
+			}
+
+			continue
+		}
+
+		n, err := base.ReadAt(buf, op.BlockIndex*blockSize)
+		if err != nil && (err != io.EOF || n == 0) {
+			return errors.Wrapf(err, "read block %d", op.BlockIndex)
+		}
+
+		_, err = writer.Write(buf[:n])
+		if err != nil {
+			return errors.Wrap(err, "write block")
+		}
+	}
+
+	return nil
+}
+
+func checksum(data []byte) (uint32, uint32) {
+	var a, b uint32
+	l := uint32(len(data))
+	for i, c := range data {
+		a += uint32(c)
+		b += (l - uint32(i)) * uint32(c)
+	}
+
+	return a % mod, b % mod
+}
+
+func roll(a, b uint32, out, in byte, blockSize int) (uint32, uint32) {
+	a = (a - uint32(out) + uint32(in)) % mod
+	b = (b - uint32(blockSize)*uint32(out) + a) % mod
+	return a, b
+}
@@ -0,0 +1,123 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+type testCase struct {
+	name      string
+	old       []byte
+	new       []byte
+	blockSize int64
+
+	maxLiteral int
+}
+
+func randomBytes(r *rand.Rand, size int) []byte {
+	data := make([]byte, size)
+	_, _ = r.Read(data)
+	return data
+}
+
+func TestDiffAndPatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	base := randomBytes(r, 100*1024+123)
+
+	changed := append([]byte{}, base...)
+	copy(changed[50*1024:], []byte("changed content"))
+
+	inserted := append([]byte{}, base[:30*1024+7]...)
+	inserted = append(inserted, []byte("some inserted bytes")...)
+	inserted = append(inserted, base[30*1024+7:]...)
+
+	appended := append(append([]byte{}, base...), randomBytes(r, 500)...)
+
+	testCases := []testCase{
+		{
+			name:       "Unchanged",
+			old:        base,
+			new:        base,
+			blockSize:  MinBlockSize,
+			maxLiteral: 0,
+		},
+		{
+			name:       "Changed in the middle",
+			old:        base,
+			new:        changed,
+			blockSize:  MinBlockSize,
+			maxLiteral: MinBlockSize,
+		},
+		{
+			name:       "Inserted bytes",
+			old:        base,
+			new:        inserted,
+			blockSize:  MinBlockSize,
+			maxLiteral: 2*MinBlockSize + 19,
+		},
+		{
+			name:       "Appended bytes",
+			old:        base,
+			new:        appended,
+			blockSize:  MinBlockSize,
+			maxLiteral: MinBlockSize + 500,
+		},
+		{
+			name:       "Empty old file",
+			old:        []byte{},
+			new:        base,
+			blockSize:  MinBlockSize,
+			maxLiteral: len(base),
+		},
+		{
+			name:       "Empty new file",
+			old:        base,
+			new:        []byte{},
+			blockSize:  MinBlockSize,
+			maxLiteral: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		signature, err := CreateSignature(bytes.NewReader(testCase.old), testCase.blockSize)
+		if err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, err)
+		}
+
+		operations := []*Operation{}
+		literalSize := 0
+		err = Diff(signature, bytes.NewReader(testCase.new), func(operation *Operation) error {
+			literalSize += len(operation.Data)
+			operations = append(operations, operation)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, err)
+		}
+		if literalSize > testCase.maxLiteral {
+			t.Fatalf("Unexpected literal size in test case %s: %d > %d", testCase.name, literalSize, testCase.maxLiteral)
+		}
+
+		out := &bytes.Buffer{}
+		err = Patch(bytes.NewReader(testCase.old), signature.BlockSize, operations, out)
+		if err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, err)
+		}
+		if !bytes.Equal(out.Bytes(), testCase.new) {
+			t.Fatalf("Patched file does not match new file in test case %s", testCase.name)
+		}
+	}
+}
+
+func TestBlockSizeFor(t *testing.T) {
+	if BlockSizeFor(0) != MinBlockSize {
+		t.Fatalf("Expected min block size for empty files, got %d", BlockSizeFor(0))
+	}
+	if BlockSizeFor(1<<40) != MaxBlockSize {
+		t.Fatalf("Expected max block size for huge files, got %d", BlockSizeFor(1<<40))
+	}
+	if BlockSizeFor(200*1024*1024)%1024 != 0 {
+		t.Fatalf("Expected block size to be a multiple of 1024, got %d", BlockSizeFor(200*1024*1024))
+	}
+}
@@ -0,0 +1,78 @@
+package modemask
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/pkg/errors"
+)
+
+// Mask sets and clears permission bits of all files and folders that match the path pattern
+type Mask struct {
+	// Path is a gitignore style pattern
+	Path string
+
+	// Set are the permission bits that are always set
+	Set os.FileMode
+
+	// Clear are the permission bits that are always cleared
+	Clear os.FileMode
+}
+
+// Masks is a list of compiled masks that are applied in order
+type Masks []*compiledMask
+
+type compiledMask struct {
+	matcher ignoreparser.IgnoreParser
+	set     os.FileMode
+	clear   os.FileMode
+}
+
+// Compile compiles the path patterns of the given masks
+func Compile(masks []Mask) (Masks, error) {
+	compiled := make(Masks, 0, len(masks))
+	for _, mask := range masks {
+		matcher, err := ignoreparser.CompilePaths([]string{mask.Path})
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile mode mask path %s", mask.Path)
+		} else if matcher == nil {
+			continue
+		}
+
+		compiled = append(compiled, &compiledMask{
+			matcher: matcher,
+			set:     mask.Set & os.ModePerm,
+			clear:   mask.Clear & os.ModePerm,
+		})
+	}
+
+	return compiled, nil
+}
+
+// Apply returns the mode with all masks applied that match the given path
+func (m Masks) Apply(relativePath string, isDir bool, mode os.FileMode) os.FileMode {
+	for _, mask := range m {
+		if mask.matcher.Matches(relativePath, isDir) {
+			mode = (mode | mask.set) &^ mask.clear
+		}
+	}
+
+	return mode
+}
+
+// ParseMode parses an octal permission string such as 0755. An empty string is parsed as 0
+func ParseMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid octal mode '%s'", mode)
+	} else if os.FileMode(parsed)&^os.ModePerm != 0 {
+		return 0, errors.Errorf("mode '%s' contains more than permission bits", mode)
+	}
+
+	return os.FileMode(parsed), nil
+}
@@ -0,0 +1,52 @@
+package modemask
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	masks, err := Compile([]Mask{
+		{Path: "*.sh", Set: 0111},
+		{Path: "/secrets/", Clear: 0077},
+		{Path: "/secrets/public.txt", Set: 0044},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path     string
+		isDir    bool
+		mode     os.FileMode
+		expected os.FileMode
+	}{
+		{path: "/test.txt", mode: 0644, expected: 0644},
+		{path: "/scripts/run.sh", mode: 0644, expected: 0755},
+		{path: "/secrets", isDir: true, mode: 0755, expected: 0700},
+		{path: "/secrets/public.txt", mode: 0600, expected: 0644},
+	}
+
+	for _, testCase := range testCases {
+		mode := masks.Apply(testCase.path, testCase.isDir, testCase.mode)
+		if mode != testCase.expected {
+			t.Fatalf("Unexpected mode for %s: expected %o, got %o", testCase.path, testCase.expected, mode)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("0755")
+	if err != nil {
+		t.Fatal(err)
+	} else if mode != 0755 {
+		t.Fatalf("Unexpected mode %o", mode)
+	}
+
+	for _, invalid := range []string{"abc", "0999", "10777"} {
+		_, err = ParseMode(invalid)
+		if err == nil {
+			t.Fatalf("Expected error for mode %s", invalid)
+		}
+	}
+}
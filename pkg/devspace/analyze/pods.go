@@ -81,16 +81,21 @@ func (a *analyzer) pods(namespace string, options Options) ([]string, error) {
 	}
 
 	// Analyzing pods
-	if pods.Items != nil {
-		for _, pod := range pods.Items {
-			problem := checkPod(a.client, &pod, options.IgnorePodRestarts)
-			if problem != nil {
-				problems = append(problems, printPodProblem(problem))
-			}
+	problems = append(problems, PodProblems(a.client, pods.Items, options.IgnorePodRestarts)...)
+	return problems, nil
+}
+
+// PodProblems analyzes the given pods and returns the printed problems of the pods that have problems
+func PodProblems(client kubectl.Client, pods []v1.Pod, ignorePodRestarts bool) []string {
+	problems := []string{}
+	for i := range pods {
+		problem := checkPod(client, &pods[i], ignorePodRestarts)
+		if problem != nil {
+			problems = append(problems, printPodProblem(problem))
 		}
 	}
 
-	return problems, nil
+	return problems
 }
 
 type podProblem struct {
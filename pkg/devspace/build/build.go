@@ -4,7 +4,10 @@ import (
 	"io"
 	"strings"
 
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/util/scanner"
 
@@ -22,6 +25,30 @@ type imageNameAndTag struct {
 	imageTag        string
 }
 
+// buildState tracks the progress of the images that are built by a single Build call
+type buildState struct {
+	graph *imageGraph
+
+	// finished are the images that were built or skipped
+	finished map[string]bool
+
+	// rebuilt are the images that were built
+	rebuilt map[string]bool
+
+	// tags are the tags of the finished images that are used as base images by dependent images
+	tags map[string]string
+
+	// running is the amount of images that are currently built in parallel
+	running int
+	started int
+
+	// summaries describe how the images were built
+	summaries map[string]*imageBuildSummary
+
+	errChan   chan error
+	cacheChan chan imageNameAndTag
+}
+
 // Options describe how images should be build
 type Options struct {
 	SkipPush                  bool
@@ -29,6 +56,9 @@ type Options struct {
 	ForceRebuild              bool
 	Sequential                bool
 	MaxConcurrentBuilds       int
+
+	// Report is filled with a description of every image after the build, if it is set
+	Report *Report
 }
 
 // Controller is the main building interface
@@ -55,11 +85,7 @@ func NewController(config config.Config, dependencies []types.Dependency, client
 func (c *controller) Build(options *Options, log logpkg.Logger) (map[string]string, error) {
 	var (
 		builtImages = make(map[string]string)
-
-		// Parallel build
-		errChan   = make(chan error)
-		cacheChan = make(chan imageNameAndTag)
-		config    = c.config.Config()
+		config      = c.config.Config()
 	)
 
 	// Check if we have at least 1 image to build
@@ -81,59 +107,228 @@ func (c *controller) Build(options *Options, log logpkg.Logger) (map[string]stri
 		}
 	}
 
+	// The --max-concurrent-builds flag overrides the limit of the config
+	maxConcurrentBuilds := options.MaxConcurrentBuilds
+	if maxConcurrentBuilds == 0 && config.BuildSettings != nil {
+		maxConcurrentBuilds = config.BuildSettings.MaxConcurrent
+	}
+
 	// Execute before images build hook
 	pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{}, log, "before:build")
 	if pluginErr != nil {
 		return nil, pluginErr
 	}
 
-	imagesToBuild := 0
-
 	for key, imageConf := range config.Images {
 		if imageConf.Build != nil && imageConf.Build.Disabled {
 			log.Infof("Skipping building image %s", key)
-			continue
 		}
+	}
 
-		// This is necessary for parallel build otherwise we would override the image conf pointer during the loop
-		cImageConf := *imageConf
-		imageName := cImageConf.Image
-		imageConfigName := key
+	// Resolve the order in which the images have to be built
+	graph, err := newImageGraph(config.Images, log)
+	if err != nil {
+		return nil, err
+	}
 
-		// Get image tags
-		imageTags := []string{}
-		if len(imageConf.Tags) > 0 {
-			imageTags = append(imageTags, imageConf.Tags...)
-		} else {
-			imageTags = append(imageTags, randutil.GenerateRandomString(7))
+	state := &buildState{
+		graph:     graph,
+		finished:  map[string]bool{},
+		rebuilt:   map[string]bool{},
+		tags:      map[string]string{},
+		summaries: map[string]*imageBuildSummary{},
+		errChan:   make(chan error, len(graph.order)),
+		cacheChan: make(chan imageNameAndTag, len(graph.order)),
+	}
+	defer printBuildSummary(state.summaries, log)
+	if options.Report != nil {
+		for key, imageConf := range config.Images {
+			if imageConf.Build != nil && imageConf.Build.Disabled {
+				state.summaries[key] = &imageBuildSummary{status: buildStatusSkipped, image: imageConf.Image, skipReason: SkipReasonDisabled}
+			}
 		}
 
-		// replace the # in the tags
-		for i := range imageTags {
-			for strings.Contains(imageTags[i], "#") {
-				imageTags[i] = strings.Replace(imageTags[i], "#", randutil.GenerateRandomString(1), 1)
+		defer func() {
+			options.Report.Images = newReport(state.summaries, log).Images
+		}()
+	}
+
+	// Build the images as soon as the images they depend on are finished
+	pending := append([]string{}, graph.order...)
+	for len(pending) > 0 || state.running > 0 {
+		for i := 0; i < len(pending); {
+			if maxConcurrentBuilds > 0 && state.running >= maxConcurrentBuilds {
+				break
+			} else if !graph.ready(pending[i], state.finished) {
+				i++
+				continue
+			}
+
+			imageConfigName := pending[i]
+			pending = append(pending[:i], pending[i+1:]...)
+			err := c.buildImage(imageConfigName, state, builtImages, options, log)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// wait for the parallel builds
+		if state.running > 0 {
+			err := c.waitForBuild(state, builtImages, log)
+			if err != nil {
+				return nil, err
 			}
+		} else if len(pending) > 0 {
+			return nil, errors.Errorf("cannot build images %s, because the images they depend on were not built", strings.Join(pending, ", "))
 		}
+	}
+
+	// Execute after images build hook
+	pluginErr = hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{}, log, "after:build")
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
 
-		// Create new builder
-		builder, err := c.createBuilder(imageConfigName, &cImageConf, imageTags, options, log)
+	return builtImages, nil
+}
+
+// buildImage builds a single image. If the build is not sequential, the image is built in the background
+// and the result is sent to the channels of the build state
+func (c *controller) buildImage(imageConfigName string, state *buildState, builtImages map[string]string, options *Options, log logpkg.Logger) error {
+	config := c.config.Config()
+
+	// This is necessary for parallel build otherwise we would override the image conf pointer during the loop
+	cImageConf := *config.Images[imageConfigName]
+	imageName := cImageConf.Image
+
+	// Get image tags
+	imageTags := []string{}
+	if len(cImageConf.Tags) > 0 {
+		imageTags = append(imageTags, cImageConf.Tags...)
+	} else {
+		imageTags = append(imageTags, randutil.GenerateRandomString(7))
+	}
+
+	// replace the # in the tags
+	for i := range imageTags {
+		for strings.Contains(imageTags[i], "#") {
+			imageTags[i] = strings.Replace(imageTags[i], "#", randutil.GenerateRandomString(1), 1)
+		}
+	}
+
+	// Use the tags of the images this image depends on
+	baseImages := map[string]string{}
+	rebuiltDependency := ""
+	for _, dependency := range state.graph.dependencies[imageConfigName] {
+		if state.tags[dependency] != "" {
+			baseImages[config.Images[dependency].Image] = config.Images[dependency].Image + ":" + state.tags[dependency]
+		}
+		if state.rebuilt[dependency] {
+			rebuiltDependency = dependency
+		}
+	}
+
+	// Tag the image with its content hash, so it can be reused from the registry
+	useRemoteCache := cImageConf.RemoteCache && cImageConf.RebuildStrategy != latest.RebuildStrategyAlways
+	if useRemoteCache {
+		contentTag, err := helper.GetContentHashTag(&cImageConf, baseImages)
 		if err != nil {
-			return nil, errors.Wrap(err, "create builder")
+			return errors.Wrap(err, "get content hash tag")
 		}
 
+		if len(cImageConf.Tags) > 0 {
+			imageTags = append([]string{contentTag}, imageTags...)
+		} else {
+			imageTags = []string{contentTag}
+		}
+	}
+
+	// Create new builder
+	builder, err := c.createBuilder(imageConfigName, &cImageConf, imageTags, baseImages, options, log)
+	if err != nil {
+		return errors.Wrap(err, "create builder")
+	}
+
+	// Execute before images build hook
+	pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+		"IMAGE_CONFIG_NAME": imageConfigName,
+		"IMAGE_NAME":        imageName,
+		"IMAGE_CONFIG":      cImageConf,
+		"IMAGE_TAGS":        imageTags,
+	}, log, hook.EventsForSingle("before:build", imageConfigName).With("build.beforeBuild")...)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
+	// Check if rebuild is needed
+	var (
+		needRebuild bool
+		skipReason  = SkipReasonUnchanged
+	)
+	if useRemoteCache {
+		needRebuild, skipReason = c.shouldRebuildFromRemoteCache(imageConfigName, imageName, imageTags[0], builtImages, options, log)
+	} else {
+		needRebuild, err = builder.ShouldRebuild(c.config.Generated().GetActive(), options.ForceRebuild)
+	}
+	if err != nil {
+		pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+			"IMAGE_CONFIG_NAME": imageConfigName,
+			"IMAGE_NAME":        imageName,
+			"IMAGE_CONFIG":      cImageConf,
+			"IMAGE_TAGS":        imageTags,
+			"ERROR":             err,
+		}, log, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
+		if pluginErr != nil {
+			return pluginErr
+		}
+		return errors.Errorf("error during shouldRebuild check: %v", err)
+	}
+
+	// Rebuild if an image this image depends on was rebuilt. With the remote cache the
+	// tags of the base images are already part of the content hash
+	if !options.ForceRebuild && !needRebuild && !useRemoteCache && rebuiltDependency != "" {
+		log.Infof("Rebuild image '%s' because image '%s' was rebuilt", imageConfigName, rebuiltDependency)
+		needRebuild = true
+	}
+
+	if !options.ForceRebuild && !needRebuild {
 		// Execute before images build hook
 		pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
 			"IMAGE_CONFIG_NAME": imageConfigName,
 			"IMAGE_NAME":        imageName,
 			"IMAGE_CONFIG":      cImageConf,
 			"IMAGE_TAGS":        imageTags,
-		}, log, hook.EventsForSingle("before:build", imageConfigName).With("build.beforeBuild")...)
+		}, log, hook.EventsForSingle("skip:build", imageConfigName)...)
 		if pluginErr != nil {
-			return nil, pluginErr
+			return pluginErr
+		}
+		log.Infof("Skip building image '%s'", imageConfigName)
+
+		tag := c.config.Generated().GetActive().GetImageCache(imageConfigName).Tag
+		summary := c.newImageBuildSummary(&cImageConf, builder, nil, options)
+		summary.status = buildStatusSkipped
+		summary.skipReason = skipReason
+		if skipReason == SkipReasonRemoteCache {
+			summary.pushed = true
+		}
+		if tag != "" {
+			summary.tags = []string{tag}
 		}
 
-		// Check if rebuild is needed
-		needRebuild, err := builder.ShouldRebuild(c.config.Generated().GetActive(), options.ForceRebuild)
+		state.summaries[imageConfigName] = summary
+		state.finished[imageConfigName] = true
+		state.tags[imageConfigName] = tag
+		return nil
+	}
+
+	summary := c.newImageBuildSummary(&cImageConf, builder, imageTags, options)
+	summary.status = buildStatusBuilding
+	state.summaries[imageConfigName] = summary
+
+	// Sequential or parallel build?
+	if options.Sequential {
+		// Build the image
+		err = buildAndSign(imageConfigName, builder, &cImageConf, imageTags[0], summary, log)
 		if err != nil {
 			pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
 				"IMAGE_CONFIG_NAME": imageConfigName,
@@ -143,168 +338,135 @@ func (c *controller) Build(options *Options, log logpkg.Logger) (map[string]stri
 				"ERROR":             err,
 			}, log, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
 			if pluginErr != nil {
-				return nil, pluginErr
+				return pluginErr
 			}
-			return nil, errors.Errorf("error during shouldRebuild check: %v", err)
+			return errors.Wrapf(err, "error building image %s:%s", imageName, imageTags[0])
 		}
 
-		if !options.ForceRebuild && !needRebuild {
-			// Execute before images build hook
-			pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-				"IMAGE_CONFIG_NAME": imageConfigName,
-				"IMAGE_NAME":        imageName,
-				"IMAGE_CONFIG":      cImageConf,
-				"IMAGE_TAGS":        imageTags,
-			}, log, hook.EventsForSingle("skip:build", imageConfigName)...)
-			if pluginErr != nil {
-				return nil, pluginErr
-			}
-			log.Infof("Skip building image '%s'", imageConfigName)
-			continue
+		// Update cache
+		c.imageBuilt(imageNameAndTag{
+			imageConfigName: imageConfigName,
+			imageName:       imageName,
+			imageTag:        imageTags[0],
+		}, state, builtImages, log)
+
+		// Execute before images build hook
+		pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+			"IMAGE_CONFIG_NAME": imageConfigName,
+			"IMAGE_NAME":        imageName,
+			"IMAGE_CONFIG":      cImageConf,
+			"IMAGE_TAGS":        imageTags,
+		}, log, hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
+		if pluginErr != nil {
+			return pluginErr
 		}
 
-		// Sequential or parallel build?
-		if options.Sequential {
-			// Build the image
-			err = builder.Build(log)
-			if err != nil {
-				pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-					"IMAGE_CONFIG_NAME": imageConfigName,
-					"IMAGE_NAME":        imageName,
-					"IMAGE_CONFIG":      cImageConf,
-					"IMAGE_TAGS":        imageTags,
-					"ERROR":             err,
-				}, log, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
-				if pluginErr != nil {
-					return nil, pluginErr
-				}
-				return nil, errors.Wrapf(err, "error building image %s:%s", imageName, imageTags[0])
-			}
+		return nil
+	}
 
-			// Update cache
-			imageCache := c.config.Generated().GetActive().GetImageCache(imageConfigName)
-			if imageCache.Tag == imageTags[0] {
-				log.Warnf("Newly built image '%s' has the same tag as in the last build (%s), this can lead to problems that the image during deployment is not updated", imageName, imageTags[0])
+	state.running++
+	state.started++
+	color := logpkg.Colors[(len(logpkg.Colors)-1)-(state.started%len(logpkg.Colors))]
+	go func() {
+		// Create a string log
+		reader, writer := io.Pipe()
+		streamLog := logpkg.NewStreamLogger(writer, logrus.InfoLevel)
+		logsLog := logpkg.NewPrefixLogger("["+imageConfigName+"] ", color, log)
+
+		// read from the reader
+		go func() {
+			scanner := scanner.NewScanner(reader)
+			for scanner.Scan() {
+				logsLog.Info(scanner.Text())
 			}
+		}()
 
-			imageCache.ImageName = imageName
-			imageCache.Tag = imageTags[0]
-
-			// Track built images
-			builtImages[imageName] = imageTags[0]
-
-			// Execute before images build hook
-			pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+		// Build the image
+		err := buildAndSign(imageConfigName, builder, &cImageConf, imageTags[0], summary, streamLog)
+		_ = writer.Close()
+		if err != nil {
+			hook.LogExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
 				"IMAGE_CONFIG_NAME": imageConfigName,
 				"IMAGE_NAME":        imageName,
 				"IMAGE_CONFIG":      cImageConf,
 				"IMAGE_TAGS":        imageTags,
-			}, log, hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
-			if pluginErr != nil {
-				return nil, pluginErr
-			}
-		} else {
-			// wait until we are below the MaxConcurrency
-			if options.MaxConcurrentBuilds > 0 && imagesToBuild >= options.MaxConcurrentBuilds {
-				err = c.waitForBuild(errChan, cacheChan, builtImages, log)
-				if err != nil {
-					return nil, err
-				}
-
-				imagesToBuild--
-			}
-
-			imagesToBuild++
-			go func() {
-				// Create a string log
-				reader, writer := io.Pipe()
-				streamLog := logpkg.NewStreamLogger(writer, logrus.InfoLevel)
-				logsLog := logpkg.NewPrefixLogger("["+imageConfigName+"] ", logpkg.Colors[(len(logpkg.Colors)-1)-(imagesToBuild%len(logpkg.Colors))], log)
-
-				// read from the reader
-				go func() {
-					scanner := scanner.NewScanner(reader)
-					for scanner.Scan() {
-						logsLog.Info(scanner.Text())
-					}
-				}()
-
-				// Build the image
-				err := builder.Build(streamLog)
-				_ = writer.Close()
-				if err != nil {
-					hook.LogExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-						"IMAGE_CONFIG_NAME": imageConfigName,
-						"IMAGE_NAME":        imageName,
-						"IMAGE_CONFIG":      cImageConf,
-						"IMAGE_TAGS":        imageTags,
-						"ERROR":             err,
-					}, log, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
-					errChan <- errors.Errorf("error building image %s:%s: %v", imageName, imageTags[0], err)
-					return
-				}
-
-				// Execute plugin hook
-				pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-					"IMAGE_CONFIG_NAME": imageConfigName,
-					"IMAGE_NAME":        imageName,
-					"IMAGE_CONFIG":      cImageConf,
-					"IMAGE_TAGS":        imageTags,
-				}, log, hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
-				if pluginErr != nil {
-					errChan <- pluginErr
-				}
-
-				// Send the response
-				cacheChan <- imageNameAndTag{
-					imageConfigName: imageConfigName,
-					imageName:       imageName,
-					imageTag:        imageTags[0],
-				}
-			}()
+				"ERROR":             err,
+			}, log, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
+			state.errChan <- errors.Errorf("error building image %s:%s: %v", imageName, imageTags[0], err)
+			return
 		}
-	}
 
-	// wait for the builds to finish
-	if !options.Sequential {
-		for imagesToBuild > 0 {
-			err := c.waitForBuild(errChan, cacheChan, builtImages, log)
-			if err != nil {
-				return nil, err
-			}
-
-			imagesToBuild--
+		// Execute plugin hook
+		pluginErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+			"IMAGE_CONFIG_NAME": imageConfigName,
+			"IMAGE_NAME":        imageName,
+			"IMAGE_CONFIG":      cImageConf,
+			"IMAGE_TAGS":        imageTags,
+		}, log, hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
+		if pluginErr != nil {
+			state.errChan <- pluginErr
+			return
 		}
-	}
 
-	// Execute after images build hook
-	pluginErr = hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{}, log, "after:build")
-	if pluginErr != nil {
-		return nil, pluginErr
-	}
+		// Send the response
+		state.cacheChan <- imageNameAndTag{
+			imageConfigName: imageConfigName,
+			imageName:       imageName,
+			imageTag:        imageTags[0],
+		}
+	}()
 
-	return builtImages, nil
+	return nil
 }
 
-func (c *controller) waitForBuild(errChan <-chan error, cacheChan <-chan imageNameAndTag, builtImages map[string]string, log logpkg.Logger) error {
+func (c *controller) waitForBuild(state *buildState, builtImages map[string]string, log logpkg.Logger) error {
 	select {
-	case err := <-errChan:
+	case err := <-state.errChan:
 		return err
-	case done := <-cacheChan:
+	case done := <-state.cacheChan:
 		log.Donef("Done building image %s:%s (%s)", done.imageName, done.imageTag, done.imageConfigName)
 
-		// Update cache
-		imageCache := c.config.Generated().GetActive().GetImageCache(done.imageConfigName)
-		if imageCache.Tag == done.imageTag {
-			log.Warnf("Newly built image '%s' has the same tag as in the last build (%s), this can lead to problems that the image during deployment is not updated", done.imageName, done.imageTag)
-		}
+		state.running--
+		c.imageBuilt(done, state, builtImages, log)
+	}
 
-		imageCache.ImageName = done.imageName
-		imageCache.Tag = done.imageTag
+	return nil
+}
 
-		// Track built images
-		builtImages[done.imageName] = done.imageTag
+// imageBuilt updates the cache and the build state after an image was built
+func (c *controller) imageBuilt(done imageNameAndTag, state *buildState, builtImages map[string]string, log logpkg.Logger) {
+	// Update cache
+	imageCache := c.config.Generated().GetActive().GetImageCache(done.imageConfigName)
+	if imageCache.Tag == done.imageTag {
+		log.Warnf("Newly built image '%s' has the same tag as in the last build (%s), this can lead to problems that the image during deployment is not updated", done.imageName, done.imageTag)
 	}
 
-	return nil
+	imageCache.ImageName = done.imageName
+	imageCache.Tag = done.imageTag
+
+	// Track built images
+	builtImages[done.imageName] = done.imageTag
+	state.finished[done.imageConfigName] = true
+	state.rebuilt[done.imageConfigName] = true
+	state.tags[done.imageConfigName] = done.imageTag
+}
+
+// newImageBuildSummary creates the summary of an image. The size of the context is only determined if a
+// build report was requested, because the whole context has to be walked
+func (c *controller) newImageBuildSummary(imageConf *latest.ImageConfig, builder builder.Interface, imageTags []string, options *Options) *imageBuildSummary {
+	summary := &imageBuildSummary{
+		image:   imageConf.Image,
+		builder: builderName(builder),
+		tags:    imageTags,
+		pushed:  shouldPush(builder),
+	}
+
+	if options.Report != nil && (imageConf.Build == nil || imageConf.Build.Custom == nil) {
+		contextSize, err := helper.GetContextSize(imageConf)
+		if err == nil {
+			summary.contextSize = contextSize
+		}
+	}
+
+	return summary
 }
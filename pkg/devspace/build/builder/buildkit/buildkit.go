@@ -2,6 +2,7 @@ package buildkit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -39,17 +40,17 @@ type Builder struct {
 }
 
 // NewBuilder creates a new docker Builder instance
-func NewBuilder(config config.Config, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, skipPush, skipPushOnLocalKubernetes bool) (*Builder, error) {
+func NewBuilder(config config.Config, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string, skipPush, skipPushOnLocalKubernetes bool) (*Builder, error) {
 	return &Builder{
-		helper:                    helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags),
+		helper:                    helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags, baseImages),
 		skipPush:                  skipPush,
 		skipPushOnLocalKubernetes: skipPushOnLocalKubernetes,
 	}, nil
 }
 
 // Build implements the interface
-func (b *Builder) Build(log logpkg.Logger) error {
-	return b.helper.Build(b, log)
+func (b *Builder) Build(ctx context.Context, log logpkg.Logger) error {
+	return b.helper.Build(ctx, b, log)
 }
 
 // ShouldRebuild determines if an image has to be rebuilt
@@ -57,10 +58,19 @@ func (b *Builder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool)
 	return b.helper.ShouldRebuild(cache, forceRebuild)
 }
 
+// ShouldPush returns true if the built image should be pushed to the registry
+func (b *Builder) ShouldPush() bool {
+	if b.skipPushOnLocalKubernetes && b.helper.KubeClient != nil && b.helper.KubeClient.IsLocalKubernetes() {
+		return false
+	}
+
+	return !b.skipPush && !b.helper.ImageConf.Build.BuildKit.SkipPush
+}
+
 // BuildImage builds a dockerimage with the docker cli
 // contextPath is the absolute path to the context path
 // dockerfilePath is the absolute path to the dockerfile WITHIN the contextPath
-func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
+func (b *Builder) BuildImage(ctx context.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
 	// build options
 	options := &types.ImageBuildOptions{}
 	if b.helper.ImageConf.Build != nil && b.helper.ImageConf.Build.BuildKit != nil && b.helper.ImageConf.Build.BuildKit.Options != nil {
@@ -75,6 +85,10 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		}
 	}
 
+	if len(b.helper.ImageConf.Platforms) > 0 {
+		options.Platform = strings.Join(b.helper.ImageConf.Platforms, ",")
+	}
+
 	buildKitConfig := b.helper.ImageConf.Build.BuildKit
 
 	// create the builder
@@ -105,10 +119,15 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		buildKitConfig.SkipPush = b.skipPush
 	}
 
-	return buildWithCLI(body, writer, b.helper.KubeClient, builder, buildKitConfig, *buildOptions, useMinikubeDocker, log)
+	// Multi-platform images cannot be loaded into the docker daemon
+	if len(b.helper.ImageConf.Platforms) > 1 && buildKitConfig.SkipPush && (buildKitConfig.InCluster == nil || !buildKitConfig.InCluster.NoLoad) {
+		return errors.Errorf("cannot build image %s for multiple platforms without pushing it, because the docker daemon cannot load multi-platform images. Please specify a single platform or enable pushing", b.helper.ImageName)
+	}
+
+	return buildWithCLI(ctx, body, writer, b.helper.KubeClient, builder, buildKitConfig, *buildOptions, useMinikubeDocker, log)
 }
 
-func buildWithCLI(context io.Reader, writer io.Writer, kubeClient kubectl.Client, builder string, imageConf *latest.BuildKitConfig, options types.ImageBuildOptions, useMinikubeDocker bool, log logpkg.Logger) error {
+func buildWithCLI(ctx context.Context, buildContext io.Reader, writer io.Writer, kubeClient kubectl.Client, builder string, imageConf *latest.BuildKitConfig, options types.ImageBuildOptions, useMinikubeDocker bool, log logpkg.Logger) error {
 	environ := os.Environ()
 
 	command := []string{"docker", "buildx"}
@@ -129,6 +148,9 @@ func buildWithCLI(context io.Reader, writer io.Writer, kubeClient kubectl.Client
 	if options.NetworkMode != "" {
 		args = append(args, "--network", options.NetworkMode)
 	}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
 	for _, tag := range options.Tags {
 		args = append(args, "--tag", tag)
 	}
@@ -173,7 +195,7 @@ func buildWithCLI(context io.Reader, writer io.Writer, kubeClient kubectl.Client
 	completeArgs = append(completeArgs, command[1:]...)
 	completeArgs = append(completeArgs, args...)
 
-	cmd := exec.Command(command[0], completeArgs...)
+	cmd := exec.CommandContext(ctx, command[0], completeArgs...)
 	cmd.Env = environ
 	if useMinikubeDocker {
 		minikubeEnv, err := dockerpkg.GetMinikubeEnvironment()
@@ -185,7 +207,7 @@ func buildWithCLI(context io.Reader, writer io.Writer, kubeClient kubectl.Client
 		}
 	}
 
-	cmd.Stdin = context
+	cmd.Stdin = buildContext
 	cmd.Stdout = writer
 	cmd.Stderr = writer
 
@@ -1,6 +1,7 @@
 package custom
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -83,7 +84,7 @@ func (b *Builder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool)
 }
 
 // Build implements interface
-func (b *Builder) Build(log logpkg.Logger) error {
+func (b *Builder) Build(ctx context.Context, log logpkg.Logger) error {
 	// Build arguments
 	args := []string{}
 
@@ -126,7 +127,7 @@ func (b *Builder) Build(log logpkg.Logger) error {
 	commandPath = filepath.FromSlash(commandPath)
 
 	// Create the command
-	cmd := command.NewStreamCommand(commandPath, args)
+	cmd := command.NewStreamCommandContext(ctx, commandPath, args)
 
 	// Determine output writer
 	var writer io.Writer
@@ -140,7 +141,7 @@ func (b *Builder) Build(log logpkg.Logger) error {
 
 	err := cmd.Run(writer, writer, nil)
 	if err != nil {
-		return errors.Errorf("Error building image: %v", err)
+		return errors.Wrap(err, "Error building image")
 	}
 
 	log.Done("Done processing image '" + b.imageConf.Image + "'")
@@ -73,7 +73,7 @@ func TestBuild(t *testing.T) {
 	builder := NewBuilder(imageConfigName, imageConf, imageTag)
 	builder.cmd = &command.FakeCommand{}
 
-	err := builder.Build(log.GetInstance())
+	err := builder.Build(context.Background(), log.GetInstance())
 	if err != nil {
 		t.Fatal(err)
 	}
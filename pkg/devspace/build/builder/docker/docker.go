@@ -55,9 +55,9 @@ type Builder struct {
 }
 
 // NewBuilder creates a new docker Builder instance
-func NewBuilder(config config.Config, client dockerclient.Client, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, skipPush, skipPushOnLocalKubernetes bool) (*Builder, error) {
+func NewBuilder(config config.Config, client dockerclient.Client, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string, skipPush, skipPushOnLocalKubernetes bool) (*Builder, error) {
 	return &Builder{
-		helper:                    helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags),
+		helper:                    helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags, baseImages),
 		client:                    client,
 		skipPush:                  skipPush,
 		skipPushOnLocalKubernetes: skipPushOnLocalKubernetes,
@@ -65,8 +65,8 @@ func NewBuilder(config config.Config, client dockerclient.Client, kubeClient kub
 }
 
 // Build implements the interface
-func (b *Builder) Build(log logpkg.Logger) error {
-	return b.helper.Build(b, log)
+func (b *Builder) Build(ctx context.Context, log logpkg.Logger) error {
+	return b.helper.Build(ctx, b, log)
 }
 
 // ShouldRebuild determines if an image has to be rebuilt
@@ -77,7 +77,7 @@ func (b *Builder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool)
 // BuildImage builds a dockerimage with the docker cli
 // contextPath is the absolute path to the context path
 // dockerfilePath is the absolute path to the dockerfile WITHIN the contextPath
-func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
+func (b *Builder) BuildImage(ctx context.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
 	var (
 		displayRegistryURL = "hub.docker.com"
 	)
@@ -96,8 +96,13 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		b.skipPush = true
 	}
 
+	// Docker can only store images for a single platform
+	if len(b.helper.ImageConf.Platforms) > 1 && !b.ShouldPush() {
+		return errors.Errorf("cannot build image %s for multiple platforms without pushing it, because the docker daemon cannot store multi-platform images. Please specify a single platform or enable pushing", b.helper.ImageName)
+	}
+
 	// Authenticate
-	if !b.skipPush && (b.helper.ImageConf.Build == nil || b.helper.ImageConf.Build.Docker == nil || !b.helper.ImageConf.Build.Docker.SkipPush) {
+	if b.ShouldPush() {
 		log.StartWait("Authenticating (" + displayRegistryURL + ")")
 		_, err = b.Authenticate()
 		log.StopWait()
@@ -122,11 +127,29 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		}
 	}
 
+	// Build an image for every platform and combine them in a manifest list
+	if len(b.helper.ImageConf.Platforms) > 1 {
+		return b.buildMultiPlatform(ctx, contextPath, dockerfilePath, entrypoint, cmd, options, displayRegistryURL, log)
+	} else if len(b.helper.ImageConf.Platforms) == 1 {
+		options.Platform = b.helper.ImageConf.Platforms[0]
+	}
+
+	return b.buildAndPush(ctx, contextPath, dockerfilePath, entrypoint, cmd, options, nil, displayRegistryURL, log)
+}
+
+// buildAndPush builds the image and pushes it if necessary. If tags is nil, the image tags are used
+func (b *Builder) buildAndPush(ctx context.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, options *types.ImageBuildOptions, tags []string, displayRegistryURL string, log logpkg.Logger) error {
 	// create context stream
 	body, writer, outStream, buildOptions, err := CreateContextStream(b.helper, contextPath, dockerfilePath, entrypoint, cmd, options, log)
 	if err != nil {
 		return err
 	}
+	if tags != nil {
+		buildOptions.Tags = []string{}
+		for _, tag := range tags {
+			buildOptions.Tags = append(buildOptions.Tags, b.helper.ImageName+":"+tag)
+		}
+	}
 
 	// Should we build with cli?
 	useBuildKit := false
@@ -139,7 +162,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		}
 	}
 	if useDockerCli || useBuildKit || len(cliArgs) > 0 {
-		err = b.client.ImageBuildCLI(useBuildKit, body, writer, cliArgs, *buildOptions, log)
+		err = b.client.ImageBuildCLI(ctx, useBuildKit, body, writer, cliArgs, *buildOptions, log)
 		if err != nil {
 			return err
 		}
@@ -147,7 +170,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		// make sure to use the correct proxy configuration
 		buildOptions.BuildArgs = b.client.ParseProxyConfig(buildOptions.BuildArgs)
 
-		response, err := b.client.ImageBuild(context.Background(), body, *buildOptions)
+		response, err := b.client.ImageBuild(ctx, body, *buildOptions)
 		if err != nil {
 			return err
 		}
@@ -160,9 +183,9 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 	}
 
 	// Check if we skip push
-	if !b.skipPush && (b.helper.ImageConf.Build == nil || b.helper.ImageConf.Build.Docker == nil || !b.helper.ImageConf.Build.Docker.SkipPush) {
+	if b.ShouldPush() {
 		for _, tag := range buildOptions.Tags {
-			err = b.pushImage(writer, tag)
+			err = b.pushImage(ctx, writer, tag)
 			if err != nil {
 				return errors.Errorf("error during image push: %v", err)
 			}
@@ -176,6 +199,15 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 	return nil
 }
 
+// ShouldPush returns true if the built image should be pushed to the registry
+func (b *Builder) ShouldPush() bool {
+	if b.skipPushOnLocalKubernetes && b.helper.KubeClient != nil && b.helper.KubeClient.IsLocalKubernetes() {
+		return false
+	}
+
+	return !b.skipPush && (b.helper.ImageConf.Build == nil || b.helper.ImageConf.Build.Docker == nil || !b.helper.ImageConf.Build.Docker.SkipPush)
+}
+
 // Authenticate authenticates the client with a remote registry
 func (b *Builder) Authenticate() (*types.AuthConfig, error) {
 	registryURL, err := pullsecrets.GetRegistryFromImageName(b.helper.ImageName + ":" + b.helper.ImageTags[0])
@@ -192,7 +224,7 @@ func (b *Builder) Authenticate() (*types.AuthConfig, error) {
 }
 
 // pushImage pushes an image to the specified registry
-func (b *Builder) pushImage(writer io.Writer, imageName string) error {
+func (b *Builder) pushImage(ctx context.Context, writer io.Writer, imageName string) error {
 	ref, err := reference.ParseNormalizedNamed(imageName)
 	if err != nil {
 		return err
@@ -203,7 +235,7 @@ func (b *Builder) pushImage(writer io.Writer, imageName string) error {
 		return err
 	}
 
-	out, err := b.client.ImagePush(context.Background(), reference.FamiliarString(ref), types.ImagePushOptions{
+	out, err := b.client.ImagePush(ctx, reference.FamiliarString(ref), types.ImagePushOptions{
 		RegistryAuth: encodedAuth,
 	})
 	if err != nil {
@@ -335,6 +367,7 @@ func CreateContextStream(buildHelper *helper.BuildHelper, contextPath, dockerfil
 		BuildArgs:   options.BuildArgs,
 		Target:      options.Target,
 		NetworkMode: options.NetworkMode,
+		Platform:    options.Platform,
 		AuthConfigs: authConfigs,
 	}
 
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"testing"
 
 	"github.com/loft-sh/devspace/pkg/util/log"
@@ -25,7 +26,7 @@ func TestBuildImage(t *testing.T) {
 	for _, testCase := range testCases {
 		builder := &Builder{}
 
-		err := builder.BuildImage(testCase.contextPath, testCase.dockerfilePath, testCase.entrypoint, testCase.cmd, log.Discard)
+		err := builder.BuildImage(context.Background(), testCase.contextPath, testCase.dockerfilePath, testCase.entrypoint, testCase.cmd, log.Discard)
 
 		if testCase.expectedErr == "" {
 			assert.NilError(t, err, "Error  in testCase %s", testCase.name)
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// buildMultiPlatform builds and pushes the image for every platform with a platform specific tag and
+// afterwards pushes a manifest list that references the platform images for every image tag
+func (b *Builder) buildMultiPlatform(ctx context.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, options *types.ImageBuildOptions, displayRegistryURL string, log logpkg.Logger) error {
+	registryClient, err := dockerclient.NewRegistryClient(b.helper.ImageName, b.authConfig, "pull", "push")
+	if err != nil {
+		return errors.Wrap(err, "create registry client")
+	}
+
+	manifests := []*dockerclient.ManifestDescriptor{}
+	for _, platform := range b.helper.ImageConf.Platforms {
+		parsedPlatform, err := dockerclient.ParsePlatform(platform)
+		if err != nil {
+			return err
+		}
+
+		imageTag := platformTag(b.helper.ImageTags[0], platform)
+		platformOptions := *options
+		platformOptions.Platform = platform
+
+		log.Infof("Building image '%s:%s' for platform %s", b.helper.ImageName, imageTag, platform)
+		err = b.buildAndPush(ctx, contextPath, dockerfilePath, entrypoint, cmd, &platformOptions, []string{imageTag}, displayRegistryURL, log)
+		if err != nil {
+			return errors.Wrapf(err, "build platform %s", platform)
+		}
+
+		manifest, err := registryClient.GetManifestDescriptor(ctx, imageTag)
+		if err != nil {
+			return errors.Wrapf(err, "get manifest of %s:%s", b.helper.ImageName, imageTag)
+		} else if manifest.MediaType == dockerclient.MediaTypeManifestList {
+			return errors.Errorf("image %s:%s is already a manifest list", b.helper.ImageName, imageTag)
+		}
+
+		manifest.Platform = parsedPlatform
+		manifests = append(manifests, manifest)
+	}
+
+	for _, tag := range b.helper.ImageTags {
+		err = registryClient.PutManifestList(ctx, tag, manifests)
+		if err != nil {
+			return errors.Wrapf(err, "push manifest list %s:%s", b.helper.ImageName, tag)
+		}
+
+		log.Infof("Manifest list %s:%s for platforms %s pushed to registry (%s)", b.helper.ImageName, tag, strings.Join(b.helper.ImageConf.Platforms, ", "), displayRegistryURL)
+	}
+
+	return nil
+}
+
+// platformTag returns the tag of the image that is built for a single platform of a multi-platform image
+func platformTag(tag, platform string) string {
+	return tag + "-" + strings.Replace(platform, "/", "-", -1)
+}
@@ -0,0 +1,303 @@
+package gobuild
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
+	"github.com/loft-sh/devspace/pkg/util/command"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+
+	dockerterm "github.com/moby/term"
+	"github.com/pkg/errors"
+)
+
+// EngineName is the name of the building engine
+const EngineName = "goBuild"
+
+// Defaults of the go build config
+const (
+	DefaultBaseImage  = "gcr.io/distroless/static:nonroot"
+	DefaultPackage    = "."
+	DefaultGOOS       = "linux"
+	DefaultGOARCH     = "amd64"
+	DefaultGOARM      = "7"
+	DefaultBinaryPath = "/app"
+)
+
+var (
+	_, stdout, _ = dockerterm.StdStreams()
+)
+
+// Builder compiles a go binary and appends it as a new layer to a base image without a docker daemon
+type Builder struct {
+	helper *helper.BuildHelper
+
+	skipPush bool
+}
+
+// NewBuilder creates a new go builder
+func NewBuilder(config config.Config, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string, skipPush bool) *Builder {
+	return &Builder{
+		helper:   helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags, baseImages),
+		skipPush: skipPush || imageConf.Build.GoBuild.SkipPush,
+	}
+}
+
+// ShouldRebuild determines if an image has to be rebuilt
+func (b *Builder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool) (bool, error) {
+	return b.helper.ShouldRebuild(cache, forceRebuild)
+}
+
+// ShouldPush returns true if the built image is pushed to the registry instead of an oci layout
+func (b *Builder) ShouldPush() bool {
+	return !b.skipPush && b.helper.ImageConf.Build.GoBuild.OCILayout == ""
+}
+
+// Build compiles the binary, appends it to the base image and pushes the image to the registry
+// or writes it to an oci layout
+func (b *Builder) Build(ctx context.Context, log logpkg.Logger) error {
+	var (
+		goBuildConfig = b.helper.ImageConf.Build.GoBuild
+		goos, goarch  = GetPlatform(goBuildConfig)
+		baseImageName = b.getBaseImage()
+	)
+
+	// Without a docker daemon the image is only available in the registry or the oci layout
+	if b.skipPush && goBuildConfig.OCILayout == "" {
+		return errors.Errorf("cannot build image %s without pushing it, because there is no docker daemon the image could be loaded into. Please set images.*.build.goBuild.ociLayout or enable pushing", b.helper.ImageName)
+	}
+
+	log.Infof("Building image '%s:%s' with engine '%s'", b.helper.ImageName, b.helper.ImageTags[0], EngineName)
+
+	tempDir, err := ioutil.TempDir("", "devspace-gobuild")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Compile the binary
+	binary := filepath.Join(tempDir, "binary")
+	err = b.compile(ctx, binary, goos, goarch, log)
+	if err != nil {
+		return err
+	}
+
+	binaryPath := GetBinaryPath(goBuildConfig)
+	layer, err := newBinaryLayer(binary, binaryPath)
+	if err != nil {
+		return errors.Wrap(err, "create binary layer")
+	}
+
+	// Resolve the base image
+	log.StartWait("Resolving base image " + baseImageName)
+	source, baseImageRef, err := newRegistryClient(baseImageName, "pull")
+	if err != nil {
+		log.StopWait()
+		return errors.Wrapf(err, "create registry client for base image %s", baseImageName)
+	}
+
+	base, err := getBaseImage(ctx, source, baseImageRef, goos, goarch, GetVariant(goBuildConfig))
+	log.StopWait()
+	if err != nil {
+		return errors.Wrapf(err, "get base image %s", baseImageName)
+	}
+
+	entrypoint := b.helper.Entrypoint
+	if len(entrypoint) == 0 {
+		entrypoint = []string{binaryPath}
+	}
+
+	img, err := newImage(base, layer, entrypoint, b.helper.Cmd, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if goBuildConfig.OCILayout != "" {
+		err = writeOCILayout(ctx, goBuildConfig.OCILayout, source, img, b.helper.ImageTags)
+		if err != nil {
+			return errors.Wrapf(err, "write oci layout %s", goBuildConfig.OCILayout)
+		}
+
+		log.Infof("Image written to oci layout %s", goBuildConfig.OCILayout)
+	} else {
+		target, _, err := newRegistryClient(b.helper.ImageName, "pull", "push")
+		if err != nil {
+			return errors.Wrapf(err, "create registry client for image %s", b.helper.ImageName)
+		}
+
+		log.StartWait("Pushing image " + b.helper.ImageName)
+		err = pushImage(ctx, source, target, img, b.helper.ImageTags)
+		log.StopWait()
+		if err != nil {
+			return errors.Errorf("error during image push: %v", err)
+		}
+
+		registryURL, err := pullsecrets.GetRegistryFromImageName(b.helper.ImageName)
+		if err != nil {
+			return err
+		} else if registryURL == "" {
+			registryURL = "hub.docker.com"
+		}
+
+		log.Info("Image pushed to registry (" + registryURL + ")")
+	}
+
+	log.Done("Done processing image '" + b.helper.ImageName + "'")
+	return nil
+}
+
+// compile runs go build for the configured package in the context directory
+func (b *Builder) compile(ctx context.Context, binary, goos, goarch string, log logpkg.Logger) error {
+	goBuildConfig := b.helper.ImageConf.Build.GoBuild
+	contextPath, err := filepath.Abs(b.helper.ContextPath)
+	if err != nil {
+		return errors.Errorf("Couldn't determine absolute path for %s", b.helper.ContextPath)
+	}
+
+	pkg := DefaultPackage
+	if goBuildConfig.Package != "" {
+		pkg = goBuildConfig.Package
+	}
+
+	args := []string{"build", "-o", binary}
+	args = append(args, goBuildConfig.Flags...)
+	args = append(args, pkg)
+
+	env := map[string]string{
+		"CGO_ENABLED": "0",
+	}
+	for name, value := range goBuildConfig.Env {
+		env[name] = value
+	}
+	env["GOOS"] = goos
+	env["GOARCH"] = goarch
+	if goarch == "arm" {
+		env["GOARM"] = getGOARM(goBuildConfig)
+	}
+
+	// Determine output writer
+	var writer io.Writer
+	if log == logpkg.GetInstance() {
+		writer = stdout
+	} else {
+		writer = log
+	}
+
+	log.Infof("Compile %s for %s/%s", pkg, goos, goarch)
+	err = command.NewStreamCommandContext(ctx, "go", args).RunWithEnv(writer, writer, nil, contextPath, env)
+	if err != nil {
+		return errors.Wrap(err, "go build")
+	}
+
+	return nil
+}
+
+// getBaseImage returns the configured base image or the freshly built image that should be used instead
+func (b *Builder) getBaseImage() string {
+	baseImage := GetBaseImage(b.helper.ImageConf.Build.GoBuild)
+	repository := helper.GetImageRepository(baseImage)
+	for image, replacement := range b.helper.BaseImages {
+		if repository != "" && helper.GetImageRepository(image) == repository {
+			return replacement
+		}
+	}
+
+	return baseImage
+}
+
+// GetBaseImage returns the image the binary is added to
+func GetBaseImage(goBuildConfig *latest.GoBuildConfig) string {
+	if goBuildConfig.BaseImage != "" {
+		return goBuildConfig.BaseImage
+	}
+
+	return DefaultBaseImage
+}
+
+// GetPlatform returns the operating system and the architecture the image is built for
+func GetPlatform(goBuildConfig *latest.GoBuildConfig) (string, string) {
+	goos, goarch := DefaultGOOS, DefaultGOARCH
+	if goBuildConfig.GOOS != "" {
+		goos = goBuildConfig.GOOS
+	}
+	if goBuildConfig.GOARCH != "" {
+		goarch = goBuildConfig.GOARCH
+	}
+
+	return goos, goarch
+}
+
+// GetVariant returns the variant of the base image for the architecture, which is only set for arm
+func GetVariant(goBuildConfig *latest.GoBuildConfig) string {
+	_, goarch := GetPlatform(goBuildConfig)
+	if goarch != "arm" {
+		return ""
+	}
+
+	return "v" + getGOARM(goBuildConfig)
+}
+
+// getGOARM returns the configured arm version, which can also be set in the env of the go build config
+func getGOARM(goBuildConfig *latest.GoBuildConfig) string {
+	if goBuildConfig.GOARM != "" {
+		return goBuildConfig.GOARM
+	} else if goBuildConfig.Env["GOARM"] != "" {
+		return goBuildConfig.Env["GOARM"]
+	}
+
+	return DefaultGOARM
+}
+
+// GetBinaryPath returns the path of the binary in the image
+func GetBinaryPath(goBuildConfig *latest.GoBuildConfig) string {
+	if goBuildConfig.BinaryPath != "" {
+		return goBuildConfig.BinaryPath
+	}
+
+	return DefaultBinaryPath
+}
+
+// newRegistryClient creates a registry client for the repository of the image with the credentials of the
+// docker config and returns the tag or digest the image references
+func newRegistryClient(image string, actions ...string) (*docker.RegistryClient, string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "parse image %s", image)
+	}
+
+	ref := "latest"
+	if digested, ok := named.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+
+	registryURL, err := pullsecrets.GetRegistryFromImageName(image)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authConfig, err := docker.GetDefaultAuthConfig(registryURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := docker.NewRegistryClient(named.Name(), authConfig, actions...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, ref, nil
+}
@@ -0,0 +1,39 @@
+package gobuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+
+	"gotest.tools/assert"
+)
+
+func TestBuildWithoutPush(t *testing.T) {
+	builder := &Builder{
+		helper: &helper.BuildHelper{
+			ImageName: "john/app",
+			ImageTags: []string{"latest"},
+			ImageConf: &latest.ImageConfig{
+				Image: "john/app",
+				Build: &latest.BuildConfig{
+					GoBuild: &latest.GoBuildConfig{},
+				},
+			},
+		},
+		skipPush: true,
+	}
+
+	assert.Equal(t, builder.ShouldPush(), false)
+	err := builder.Build(context.Background(), log.Discard)
+	assert.Error(t, err, "cannot build image john/app without pushing it, because there is no docker daemon the image could be loaded into. Please set images.*.build.goBuild.ociLayout or enable pushing")
+}
+
+func TestGetVariant(t *testing.T) {
+	assert.Equal(t, GetVariant(&latest.GoBuildConfig{}), "")
+	assert.Equal(t, GetVariant(&latest.GoBuildConfig{GOARCH: "arm"}), "v7")
+	assert.Equal(t, GetVariant(&latest.GoBuildConfig{GOARCH: "arm", Env: map[string]string{"GOARM": "6"}}), "v6")
+	assert.Equal(t, GetVariant(&latest.GoBuildConfig{GOARCH: "arm", GOARM: "5", Env: map[string]string{"GOARM": "6"}}), "v5")
+}
@@ -0,0 +1,361 @@
+package gobuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// Media types of docker images. Images with an oci base image use the oci media types instead
+const (
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerConfig   = "application/vnd.docker.container.image.v1+json"
+	mediaTypeDockerLayer    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// manifest is a docker or oci image manifest
+type manifest struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	MediaType     string               `json:"mediaType,omitempty"`
+	Config        ocispec.Descriptor   `json:"config"`
+	Layers        []ocispec.Descriptor `json:"layers"`
+}
+
+// blobSource is the registry the manifests and blobs of the base image are read from
+type blobSource interface {
+	GetRawManifest(ctx context.Context, tagOrDigest string) ([]byte, string, error)
+	GetBlob(ctx context.Context, blobDigest string) (io.ReadCloser, error)
+}
+
+// layer is a gzip compressed image layer
+type layer struct {
+	content []byte
+	digest  digest.Digest
+	diffID  digest.Digest
+}
+
+// newBinaryLayer creates a layer that only contains the binary at the given path. The layer does not
+// contain timestamps, so the same binary always results in the same layer
+func newBinaryLayer(binary string, binaryPath string) (*layer, error) {
+	f, err := os.Open(binary)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		compressed = &bytes.Buffer{}
+		gzipWriter = gzip.NewWriter(compressed)
+		diffID     = digest.Canonical.Digester()
+		tarWriter  = tar.NewWriter(io.MultiWriter(gzipWriter, diffID.Hash()))
+		modTime    = time.Unix(0, 0)
+		name       = strings.TrimPrefix(path.Clean("/"+binaryPath), "/")
+	)
+
+	// Add the parent directories of the binary
+	dirs := strings.Split(path.Dir(name), "/")
+	for i := range dirs {
+		if dirs[i] == "." {
+			break
+		}
+
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     strings.Join(dirs[:i+1], "/") + "/",
+			Mode:     0755,
+			ModTime:  modTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0755,
+		Size:     stat.Size(),
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(tarWriter, f)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &layer{
+		content: compressed.Bytes(),
+		digest:  digest.FromBytes(compressed.Bytes()),
+		diffID:  diffID.Digest(),
+	}, nil
+}
+
+// baseImage is the image the binary layer is appended to
+type baseImage struct {
+	manifest *manifest
+	config   *ocispec.Image
+
+	// rawConfig is the unmodified config, which can contain docker specific fields that
+	// are not part of ocispec.Image
+	rawConfig []byte
+}
+
+// getBaseImage returns the manifest and the config of the base image. If the base image is a manifest
+// list, the image for the given platform is returned. The variant is only matched if it is not empty
+func getBaseImage(ctx context.Context, source blobSource, ref string, goos, goarch, variant string) (*baseImage, error) {
+	content, mediaType, err := source.GetRawManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	isManifestList := mediaType == docker.MediaTypeManifestList || mediaType == ocispec.MediaTypeImageIndex
+	if isManifestList {
+		list := struct {
+			Manifests []*docker.ManifestDescriptor `json:"manifests"`
+		}{}
+		err = json.Unmarshal(content, &list)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode manifest list")
+		}
+
+		var found *docker.ManifestDescriptor
+		for _, m := range list.Manifests {
+			if m.Platform != nil && m.Platform.OS == goos && m.Platform.Architecture == goarch && (variant == "" || m.Platform.Variant == variant) {
+				found = m
+				break
+			}
+		}
+		if found == nil {
+			platform := goos + "/" + goarch
+			if variant != "" {
+				platform += "/" + variant
+			}
+			return nil, errors.Errorf("no image found for platform %s", platform)
+		}
+
+		content, mediaType, err = source.GetRawManifest(ctx, found.Digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if mediaType != mediaTypeDockerManifest && mediaType != ocispec.MediaTypeImageManifest {
+		return nil, errors.Errorf("unsupported manifest type %s", mediaType)
+	}
+
+	m := &manifest{}
+	err = json.Unmarshal(content, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode manifest")
+	}
+	m.MediaType = mediaType
+
+	reader, err := source.GetBlob(ctx, m.Config.Digest.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "get image config")
+	}
+	defer reader.Close()
+
+	rawConfig, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image config")
+	}
+
+	config := &ocispec.Image{}
+	err = json.Unmarshal(rawConfig, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode image config")
+	}
+
+	// A single platform base image has to match the platform of the binary
+	if !isManifestList && (config.OS != goos || config.Architecture != goarch) {
+		return nil, errors.Errorf("base image is built for %s/%s, but the binary is built for %s/%s", config.OS, config.Architecture, goos, goarch)
+	}
+
+	return &baseImage{
+		manifest:  m,
+		config:    config,
+		rawConfig: rawConfig,
+	}, nil
+}
+
+// image is the base image with the binary layer appended
+type image struct {
+	layer *layer
+
+	mediaType string
+	manifest  *manifest
+	content   []byte
+	config    []byte
+}
+
+// newImage appends the layer to the base image and sets the entrypoint and the cmd of the image. Only
+// the changed fields of the base config are patched, so fields that are unknown to ocispec.Image are kept
+func newImage(base *baseImage, layer *layer, entrypoint []string, cmd []string, created time.Time) (*image, error) {
+	config := map[string]json.RawMessage{}
+	err := json.Unmarshal(base.rawConfig, &config)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode image config")
+	}
+
+	runConfig := map[string]json.RawMessage{}
+	err = unmarshalField(config, "config", &runConfig)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range map[string][]string{"Entrypoint": entrypoint, "Cmd": cmd} {
+		err = setField(runConfig, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rootFS := map[string]json.RawMessage{}
+	err = unmarshalField(config, "rootfs", &rootFS)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rootFS["type"]; !ok {
+		rootFS["type"] = json.RawMessage(`"layers"`)
+	}
+	err = setField(rootFS, "diff_ids", append(append([]digest.Digest{}, base.config.RootFS.DiffIDs...), layer.diffID))
+	if err != nil {
+		return nil, err
+	}
+
+	history := []json.RawMessage{}
+	err = unmarshalField(config, "history", &history)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := json.Marshal(&ocispec.History{
+		Created:   &created,
+		CreatedBy: "devspace goBuild",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range map[string]interface{}{
+		"config":  runConfig,
+		"rootfs":  rootFS,
+		"history": append(history, entry),
+		"created": created,
+	} {
+		err = setField(config, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configContent, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	configMediaType, layerMediaType := ocispec.MediaTypeImageConfig, ocispec.MediaTypeImageLayerGzip
+	if base.manifest.MediaType == mediaTypeDockerManifest {
+		configMediaType, layerMediaType = mediaTypeDockerConfig, mediaTypeDockerLayer
+	}
+
+	m := &manifest{
+		SchemaVersion: 2,
+		MediaType:     base.manifest.MediaType,
+		Config: ocispec.Descriptor{
+			MediaType: configMediaType,
+			Digest:    digest.FromBytes(configContent),
+			Size:      int64(len(configContent)),
+		},
+		Layers: append(append([]ocispec.Descriptor{}, base.manifest.Layers...), ocispec.Descriptor{
+			MediaType: layerMediaType,
+			Digest:    layer.digest,
+			Size:      int64(len(layer.content)),
+		}),
+	}
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &image{
+		layer:     layer,
+		mediaType: m.MediaType,
+		manifest:  m,
+		content:   content,
+		config:    configContent,
+	}, nil
+}
+
+// unmarshalField decodes the field of a raw json object into value. Missing and null fields leave value unchanged
+func unmarshalField(object map[string]json.RawMessage, key string, value interface{}) error {
+	raw, ok := object[key]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+
+	return errors.Wrapf(json.Unmarshal(raw, value), "decode %s", key)
+}
+
+// setField sets the field of a raw json object. Empty string slices remove the field
+func setField(object map[string]json.RawMessage, key string, value interface{}) error {
+	if s, ok := value.([]string); ok && len(s) == 0 {
+		delete(object, key)
+		return nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "encode %s", key)
+	}
+
+	object[key] = raw
+	return nil
+}
+
+// blobs returns the descriptors of the config and the layers of the image
+func (i *image) blobs() []ocispec.Descriptor {
+	return append([]ocispec.Descriptor{i.manifest.Config}, i.manifest.Layers...)
+}
+
+// getBlob returns the content of a config or layer blob of the image. The layers of the base image
+// are read from the source
+func (i *image) getBlob(ctx context.Context, source blobSource, blobDigest digest.Digest) (io.ReadCloser, error) {
+	switch blobDigest {
+	case i.layer.digest:
+		return ioutil.NopCloser(bytes.NewReader(i.layer.content)), nil
+	case i.manifest.Config.Digest:
+		return ioutil.NopCloser(bytes.NewReader(i.config)), nil
+	}
+
+	return source.GetBlob(ctx, blobDigest.String())
+}
@@ -0,0 +1,303 @@
+package gobuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+type fakeManifest struct {
+	content   []byte
+	mediaType string
+}
+
+type fakeRegistry struct {
+	manifests map[string]*fakeManifest
+	blobs     map[string][]byte
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		manifests: map[string]*fakeManifest{},
+		blobs:     map[string][]byte{},
+	}
+}
+
+func (f *fakeRegistry) GetRawManifest(ctx context.Context, tagOrDigest string) ([]byte, string, error) {
+	m, ok := f.manifests[tagOrDigest]
+	if !ok {
+		return nil, "", errors.Errorf("manifest %s not found", tagOrDigest)
+	}
+
+	return m.content, m.mediaType, nil
+}
+
+func (f *fakeRegistry) GetBlob(ctx context.Context, blobDigest string) (io.ReadCloser, error) {
+	blob, ok := f.blobs[blobDigest]
+	if !ok {
+		return nil, errors.Errorf("blob %s not found", blobDigest)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (f *fakeRegistry) BlobExists(ctx context.Context, blobDigest string) (bool, error) {
+	_, ok := f.blobs[blobDigest]
+	return ok, nil
+}
+
+func (f *fakeRegistry) PutBlob(ctx context.Context, blobDigest string, size int64, content io.Reader) error {
+	out, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	} else if int64(len(out)) != size || digest.FromBytes(out).String() != blobDigest {
+		return errors.Errorf("invalid blob %s", blobDigest)
+	}
+
+	f.blobs[blobDigest] = out
+	return nil
+}
+
+func (f *fakeRegistry) PutManifest(ctx context.Context, tag string, mediaType string, manifest []byte) error {
+	f.manifests[tag] = &fakeManifest{
+		content:   manifest,
+		mediaType: mediaType,
+	}
+	return nil
+}
+
+func (f *fakeRegistry) addBlob(content []byte) ocispec.Descriptor {
+	f.blobs[digest.FromBytes(content).String()] = content
+	return ocispec.Descriptor{
+		Digest: digest.FromBytes(content),
+		Size:   int64(len(content)),
+	}
+}
+
+// addBaseImage adds a multi platform base image with a single layer for linux/amd64, linux/arm64,
+// linux/arm/v6 and linux/arm/v7. The configs contain a docker specific healthcheck
+func (f *fakeRegistry) addBaseImage(t *testing.T, tag string) {
+	list := struct {
+		SchemaVersion int                          `json:"schemaVersion"`
+		MediaType     string                       `json:"mediaType"`
+		Manifests     []*docker.ManifestDescriptor `json:"manifests"`
+	}{
+		SchemaVersion: 2,
+		MediaType:     docker.MediaTypeManifestList,
+	}
+
+	for _, platform := range []string{"amd64", "arm64", "arm/v6", "arm/v7"} {
+		arch, variant := path.Split(platform)
+		if arch == "" {
+			arch, variant = variant, ""
+		} else {
+			arch = strings.TrimSuffix(arch, "/")
+		}
+
+		layer := f.addBlob([]byte("base layer " + platform))
+		layer.MediaType = mediaTypeDockerLayer
+
+		config, err := json.Marshal(map[string]interface{}{
+			"os":           "linux",
+			"architecture": arch,
+			"config": map[string]interface{}{
+				"User":        "nonroot",
+				"Cmd":         []string{"/bin/sh"},
+				"Healthcheck": map[string]interface{}{"Test": []string{"CMD", "/healthcheck"}},
+			},
+			"rootfs": map[string]interface{}{
+				"type":     "layers",
+				"diff_ids": []digest.Digest{digest.FromString("base layer " + platform)},
+			},
+		})
+		assert.NilError(t, err)
+		configDescriptor := f.addBlob(config)
+		configDescriptor.MediaType = mediaTypeDockerConfig
+
+		content, err := json.Marshal(&manifest{
+			SchemaVersion: 2,
+			MediaType:     mediaTypeDockerManifest,
+			Config:        configDescriptor,
+			Layers:        []ocispec.Descriptor{layer},
+		})
+		assert.NilError(t, err)
+
+		manifestDigest := digest.FromBytes(content).String()
+		f.manifests[manifestDigest] = &fakeManifest{content: content, mediaType: mediaTypeDockerManifest}
+		list.Manifests = append(list.Manifests, &docker.ManifestDescriptor{
+			MediaType: mediaTypeDockerManifest,
+			Size:      int64(len(content)),
+			Digest:    manifestDigest,
+			Platform: &docker.Platform{
+				OS:           "linux",
+				Architecture: arch,
+				Variant:      variant,
+			},
+		})
+	}
+
+	content, err := json.Marshal(list)
+	assert.NilError(t, err)
+	f.manifests[tag] = &fakeManifest{content: content, mediaType: docker.MediaTypeManifestList}
+}
+
+func TestNewBinaryLayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobuild")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	binary := filepath.Join(dir, "binary")
+	assert.NilError(t, ioutil.WriteFile(binary, []byte("binary content"), 0644))
+
+	layer, err := newBinaryLayer(binary, "/usr/local/bin/app")
+	assert.NilError(t, err)
+	assert.Equal(t, layer.digest, digest.FromBytes(layer.content))
+
+	// The same binary has to result in the same layer
+	layer2, err := newBinaryLayer(binary, "/usr/local/bin/app")
+	assert.NilError(t, err)
+	assert.Equal(t, layer2.digest, layer.digest)
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(layer.content))
+	assert.NilError(t, err)
+	uncompressed, err := ioutil.ReadAll(gzipReader)
+	assert.NilError(t, err)
+	assert.Equal(t, layer.diffID, digest.FromBytes(uncompressed))
+
+	entries := []string{}
+	tarReader := tar.NewReader(bytes.NewReader(uncompressed))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+
+		entries = append(entries, header.Name)
+		if header.Typeflag == tar.TypeReg {
+			assert.Equal(t, header.Mode, int64(0755))
+			content, err := ioutil.ReadAll(tarReader)
+			assert.NilError(t, err)
+			assert.Equal(t, string(content), "binary content")
+		}
+	}
+	assert.DeepEqual(t, entries, []string{"usr/", "usr/local/", "usr/local/bin/", "usr/local/bin/app"})
+}
+
+func TestPushImage(t *testing.T) {
+	source := newFakeRegistry()
+	source.addBaseImage(t, "nonroot")
+
+	_, err := getBaseImage(context.Background(), source, "nonroot", "windows", "amd64", "")
+	assert.Error(t, err, "no image found for platform windows/amd64")
+
+	_, err = getBaseImage(context.Background(), source, "nonroot", "linux", "arm", "v5")
+	assert.Error(t, err, "no image found for platform linux/arm/v5")
+
+	base, err := getBaseImage(context.Background(), source, "nonroot", "linux", "arm", "v7")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, base.config.RootFS.DiffIDs, []digest.Digest{digest.FromString("base layer arm/v7")})
+
+	base, err = getBaseImage(context.Background(), source, "nonroot", "linux", "arm64", "")
+	assert.NilError(t, err)
+	assert.Equal(t, base.config.Architecture, "arm64")
+
+	layer := &layer{content: []byte("binary layer"), diffID: digest.FromString("binary")}
+	layer.digest = digest.FromBytes(layer.content)
+	img, err := newImage(base, layer, []string{"/app"}, nil, time.Now())
+	assert.NilError(t, err)
+
+	target := newFakeRegistry()
+	err = pushImage(context.Background(), source, target, img, []string{"latest", "v1"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(target.blobs), 3, "Expected the base layer, the binary layer and the config to be pushed")
+
+	for _, tag := range []string{"latest", "v1"} {
+		pushed := target.manifests[tag]
+		assert.Assert(t, pushed != nil, "Manifest for tag %s was not pushed", tag)
+		assert.Equal(t, pushed.mediaType, mediaTypeDockerManifest)
+
+		m := &manifest{}
+		assert.NilError(t, json.Unmarshal(pushed.content, m))
+		assert.Equal(t, len(m.Layers), 2)
+		assert.Equal(t, m.Layers[1].Digest, layer.digest)
+		assert.Equal(t, m.Layers[1].MediaType, mediaTypeDockerLayer)
+
+		config := &ocispec.Image{}
+		assert.NilError(t, json.Unmarshal(target.blobs[m.Config.Digest.String()], config))
+		assert.DeepEqual(t, config.Config.Entrypoint, []string{"/app"})
+		assert.Equal(t, len(config.Config.Cmd), 0)
+		assert.Equal(t, config.Config.User, "nonroot")
+		assert.DeepEqual(t, config.RootFS.DiffIDs, []digest.Digest{digest.FromString("base layer arm64"), layer.diffID})
+		assert.Equal(t, len(config.History), 1)
+
+		// docker specific fields of the base config have to be kept
+		rawConfig := struct {
+			Config struct {
+				Healthcheck *struct {
+					Test []string
+				}
+			} `json:"config"`
+		}{}
+		assert.NilError(t, json.Unmarshal(target.blobs[m.Config.Digest.String()], &rawConfig))
+		assert.Assert(t, rawConfig.Config.Healthcheck != nil, "Expected the healthcheck of the base image to be kept")
+		assert.DeepEqual(t, rawConfig.Config.Healthcheck.Test, []string{"CMD", "/healthcheck"})
+	}
+}
+
+func TestWriteOCILayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobuild")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := newFakeRegistry()
+	source.addBaseImage(t, "nonroot")
+	base, err := getBaseImage(context.Background(), source, "nonroot", "linux", "amd64", "")
+	assert.NilError(t, err)
+
+	for _, content := range []string{"first", "second"} {
+		layer := &layer{content: []byte(content), diffID: digest.FromString(content)}
+		layer.digest = digest.FromBytes(layer.content)
+		img, err := newImage(base, layer, []string{"/app"}, nil, time.Now())
+		assert.NilError(t, err)
+
+		err = writeOCILayout(context.Background(), dir, source, img, []string{content, "latest"})
+		assert.NilError(t, err)
+
+		for _, blob := range append(img.blobs(), ocispec.Descriptor{Digest: digest.FromBytes(img.content)}) {
+			out, err := ioutil.ReadFile(filepath.Join(dir, "blobs", "sha256", blob.Digest.Hex()))
+			assert.NilError(t, err)
+			assert.Equal(t, digest.FromBytes(out), blob.Digest)
+		}
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "index.json"))
+	assert.NilError(t, err)
+	index := &ocispec.Index{}
+	assert.NilError(t, json.Unmarshal(out, index))
+
+	refs := []string{}
+	for _, m := range index.Manifests {
+		refs = append(refs, m.Annotations[ocispec.AnnotationRefName])
+	}
+	assert.DeepEqual(t, refs, []string{"first", "second", "latest"})
+
+	_, err = os.Stat(filepath.Join(dir, ocispec.ImageLayoutFile))
+	assert.NilError(t, err)
+}
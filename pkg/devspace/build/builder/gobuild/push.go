@@ -0,0 +1,169 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// blobTarget is the registry the image is pushed to
+type blobTarget interface {
+	BlobExists(ctx context.Context, blobDigest string) (bool, error)
+	PutBlob(ctx context.Context, blobDigest string, size int64, content io.Reader) error
+	PutManifest(ctx context.Context, tag string, mediaType string, manifest []byte) error
+}
+
+// pushImage uploads the blobs of the image that do not exist in the target repository yet and
+// pushes the manifest under every tag
+func pushImage(ctx context.Context, source blobSource, target blobTarget, img *image, tags []string) error {
+	for _, blob := range img.blobs() {
+		exists, err := target.BlobExists(ctx, blob.Digest.String())
+		if err != nil {
+			return errors.Wrapf(err, "check blob %s", blob.Digest)
+		} else if exists {
+			continue
+		}
+
+		reader, err := img.getBlob(ctx, source, blob.Digest)
+		if err != nil {
+			return errors.Wrapf(err, "get blob %s", blob.Digest)
+		}
+
+		err = target.PutBlob(ctx, blob.Digest.String(), blob.Size, reader)
+		reader.Close()
+		if err != nil {
+			return errors.Wrapf(err, "upload blob %s", blob.Digest)
+		}
+	}
+
+	for _, tag := range tags {
+		err := target.PutManifest(ctx, tag, img.mediaType, img.content)
+		if err != nil {
+			return errors.Wrapf(err, "push manifest for tag %s", tag)
+		}
+	}
+
+	return nil
+}
+
+// writeOCILayout writes the image to the oci image layout in the given directory and references it
+// with every tag in the index. Other images in the layout are kept
+func writeOCILayout(ctx context.Context, dir string, source blobSource, img *image, tags []string) error {
+	blobsDir := filepath.Join(dir, "blobs", string(digest.Canonical))
+	err := os.MkdirAll(blobsDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	layout, err := json.Marshal(&ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, ocispec.ImageLayoutFile), layout, 0644)
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range img.blobs() {
+		err = writeLayoutBlob(blobsDir, blob.Digest, func() (io.ReadCloser, error) {
+			return img.getBlob(ctx, source, blob.Digest)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "write blob %s", blob.Digest)
+		}
+	}
+
+	manifestDigest := digest.FromBytes(img.content)
+	err = writeLayoutBlob(blobsDir, manifestDigest, func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(img.content)), nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "write manifest")
+	}
+
+	// Replace the images in the index that have the same tags
+	indexPath := filepath.Join(dir, "index.json")
+	index := &ocispec.Index{}
+	out, err := ioutil.ReadFile(indexPath)
+	if err == nil {
+		err = json.Unmarshal(out, index)
+		if err != nil {
+			return errors.Wrap(err, "decode index.json")
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	isTag := map[string]bool{}
+	for _, tag := range tags {
+		isTag[tag] = true
+	}
+
+	manifests := []ocispec.Descriptor{}
+	for _, m := range index.Manifests {
+		if !isTag[m.Annotations[ocispec.AnnotationRefName]] {
+			manifests = append(manifests, m)
+		}
+	}
+	for _, tag := range tags {
+		manifests = append(manifests, ocispec.Descriptor{
+			MediaType: img.mediaType,
+			Digest:    manifestDigest,
+			Size:      int64(len(img.content)),
+			Annotations: map[string]string{
+				ocispec.AnnotationRefName: tag,
+			},
+		})
+	}
+
+	index.Versioned = specs.Versioned{SchemaVersion: 2}
+	index.Manifests = manifests
+	out, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(indexPath, out, 0644)
+}
+
+// writeLayoutBlob writes a blob to the blobs directory of an oci layout if it does not exist yet
+func writeLayoutBlob(blobsDir string, blobDigest digest.Digest, open func() (io.ReadCloser, error)) error {
+	blobPath := filepath.Join(blobsDir, blobDigest.Hex())
+	_, err := os.Stat(blobPath)
+	if err == nil {
+		return nil
+	}
+
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// Write to a temporary file first, so that an interrupted build does not leave a broken blob behind
+	f, err := ioutil.TempFile(blobsDir, ".tmp-"+blobDigest.Hex())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	verifier := blobDigest.Verifier()
+	_, err = io.Copy(io.MultiWriter(f, verifier), reader)
+	f.Close()
+	if err != nil {
+		return err
+	} else if !verifier.Verified() {
+		return errors.Errorf("content does not match digest %s", blobDigest)
+	}
+
+	return os.Rename(f.Name(), blobPath)
+}
@@ -1,13 +1,18 @@
 package helper
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
 
 	"github.com/docker/cli/cli/command/image/build"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/fileutils"
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
@@ -32,20 +37,27 @@ type BuildHelper struct {
 	Entrypoint []string
 	Cmd        []string
 
+	// BaseImages maps image names to freshly built images that should be used instead
+	// in the FROM instructions of the dockerfile
+	BaseImages map[string]string
+
 	KubeClient kubectl.Client
 }
 
 // BuildHelperInterface is the interface the build helper uses to build an image
 type BuildHelperInterface interface {
-	BuildImage(absoluteContextPath string, absoluteDockerfilePath string, entrypoint []string, cmd []string, log log.Logger) error
+	BuildImage(ctx context.Context, absoluteContextPath string, absoluteDockerfilePath string, entrypoint []string, cmd []string, log log.Logger) error
 }
 
 // NewBuildHelper creates a new build helper for a certain engine
-func NewBuildHelper(config config.Config, kubeClient kubectl.Client, engineName string, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string) *BuildHelper {
+func NewBuildHelper(config config.Config, kubeClient kubectl.Client, engineName string, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string) *BuildHelper {
 	var (
 		dockerfilePath, contextPath = GetDockerfileAndContext(imageConf)
 		imageName                   = imageConf.Image
 	)
+	if !UsesDockerfile(imageConf) {
+		dockerfilePath = ""
+	}
 
 	// Check if we should overwrite entrypoint
 	var (
@@ -73,14 +85,15 @@ func NewBuildHelper(config config.Config, kubeClient kubectl.Client, engineName
 
 		Entrypoint: entrypoint,
 		Cmd:        cmd,
+		BaseImages: baseImages,
 		Config:     config,
 
 		KubeClient: kubeClient,
 	}
 }
 
-// Build builds a new image
-func (b *BuildHelper) Build(imageBuilder BuildHelperInterface, log log.Logger) error {
+// Build builds a new image. The build is aborted if the context is canceled
+func (b *BuildHelper) Build(ctx context.Context, imageBuilder BuildHelperInterface, log log.Logger) error {
 	// Get absolute paths
 	absoluteDockerfilePath, err := filepath.Abs(b.DockerfilePath)
 	if err != nil {
@@ -92,10 +105,21 @@ func (b *BuildHelper) Build(imageBuilder BuildHelperInterface, log log.Logger) e
 		return errors.Errorf("Couldn't determine absolute path for %s", b.ContextPath)
 	}
 
+	// Build on top of the freshly built base images
+	if len(b.BaseImages) > 0 {
+		rewrittenDockerfilePath, err := RewriteBaseImages(absoluteDockerfilePath, b.BaseImages)
+		if err != nil {
+			return errors.Wrap(err, "rewrite base images")
+		} else if rewrittenDockerfilePath != "" {
+			defer os.RemoveAll(filepath.Dir(rewrittenDockerfilePath))
+			absoluteDockerfilePath = rewrittenDockerfilePath
+		}
+	}
+
 	log.Infof("Building image '%s:%s' with engine '%s'", b.ImageName, b.ImageTags[0], b.EngineName)
 
 	// Build Image
-	err = imageBuilder.BuildImage(absoluteContextPath, absoluteDockerfilePath, b.Entrypoint, b.Cmd, log)
+	err = imageBuilder.BuildImage(ctx, absoluteContextPath, absoluteDockerfilePath, b.Entrypoint, b.Cmd, log)
 	if err != nil {
 		return err
 	}
@@ -114,13 +138,16 @@ func (b *BuildHelper) ShouldRebuild(cache *generated.CacheConfig, forceRebuild b
 	imageCache := cache.GetImageCache(b.ImageConfigName)
 
 	// Hash dockerfile
-	_, err := os.Stat(b.DockerfilePath)
-	if err != nil {
-		return false, errors.Errorf("Dockerfile %s missing: %v", b.DockerfilePath, err)
-	}
-	dockerfileHash, err := hash.Directory(b.DockerfilePath)
-	if err != nil {
-		return false, errors.Wrap(err, "hash dockerfile")
+	dockerfileHash := ""
+	if b.DockerfilePath != "" {
+		_, err := os.Stat(b.DockerfilePath)
+		if err != nil {
+			return false, errors.Errorf("Dockerfile %s missing: %v", b.DockerfilePath, err)
+		}
+		dockerfileHash, err = hash.Directory(b.DockerfilePath)
+		if err != nil {
+			return false, errors.Wrap(err, "hash dockerfile")
+		}
 	}
 
 	// Hash image config
@@ -159,22 +186,21 @@ func (b *BuildHelper) ShouldRebuild(cache *generated.CacheConfig, forceRebuild b
 	// Check if should consider context path changes for rebuilding
 	if b.ImageConf.RebuildStrategy != latest.RebuildStrategyIgnoreContextChanges {
 		// Hash context path
-		contextDir, relDockerfile, err := build.GetContextFromLocalDir(b.ContextPath, b.DockerfilePath)
-		if err != nil {
-			return false, errors.Wrap(err, "get context from local dir")
-		}
-
-		relDockerfile = archive.CanonicalTarNameForPath(relDockerfile)
-		excludes, err := ReadDockerignore(contextDir, relDockerfile)
+		contextDir, excludes, err := getContextExcludes(b.ContextPath, b.DockerfilePath)
 		if err != nil {
-			return false, errors.Errorf("Error reading .dockerignore: %v", err)
+			return false, err
 		}
 
-		contextHash, err := hash.DirectoryExcludes(contextDir, excludes, false)
+		// Only rehash the files that changed since the last check
+		index := hash.LoadFileIndex(contextIndexPath(b.ImageConfigName), contextDir)
+		contextHash, err := hash.DirectoryExcludesWithIndex(contextDir, excludes, index)
 		if err != nil {
 			return false, errors.Errorf("Error hashing %s: %v", contextDir, err)
 		}
 
+		// The index is only a cache, so we can ignore the error here
+		_ = index.Save()
+
 		mustRebuild = mustRebuild || imageCache.ContextHash != contextHash
 
 		// TODO: This is not an ideal solution since there can be the issue that the user runs
@@ -195,3 +221,150 @@ func (b *BuildHelper) ShouldRebuild(cache *generated.CacheConfig, forceRebuild b
 
 	return mustRebuild, nil
 }
+
+// contextIndexPath returns the path of the file index that caches the context file hashes of the image
+func contextIndexPath(imageConfigName string) string {
+	return filepath.Join(constants.DefaultCacheFolder, "hashes", imageConfigName+".json")
+}
+
+// contentHashTagLength is the length of the tags that are computed by GetContentHashTag
+const contentHashTagLength = 32
+
+// GetContentHashTag returns a tag that is computed from the dockerfile, the context, the image config and
+// the base images of the image. The hash only depends on file contents, file modes and relative paths, so the same
+// sources result in the same tag on every machine
+func GetContentHashTag(imageConf *latest.ImageConfig, baseImages map[string]string) (string, error) {
+	dockerfilePath, contextPath := GetDockerfileAndContext(imageConf)
+	dockerfileHash := ""
+	if UsesDockerfile(imageConf) {
+		var err error
+		dockerfileHash, err = hash.File(dockerfilePath)
+		if err != nil {
+			return "", errors.Errorf("Dockerfile %s missing: %v", dockerfilePath, err)
+		}
+	} else {
+		dockerfilePath = ""
+	}
+
+	// The tags do not change the image itself
+	imageConfCopy := *imageConf
+	imageConfCopy.Tags = nil
+	configStr, err := yaml.Marshal(imageConfCopy)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal image config")
+	}
+
+	hashes := []string{dockerfileHash, hash.String(string(configStr))}
+
+	// Images built on top of a different base image are different as well
+	baseImageNames := make([]string, 0, len(baseImages))
+	for baseImage := range baseImages {
+		baseImageNames = append(baseImageNames, baseImage)
+	}
+	sort.Strings(baseImageNames)
+	for _, baseImage := range baseImageNames {
+		hashes = append(hashes, baseImage+"="+baseImages[baseImage])
+	}
+
+	if imageConf.RebuildStrategy != latest.RebuildStrategyIgnoreContextChanges {
+		contextDir, excludes, err := getContextExcludes(contextPath, dockerfilePath)
+		if err != nil {
+			return "", err
+		}
+
+		contextHash, err := hash.DirectoryContentExcludes(contextDir, excludes)
+		if err != nil {
+			return "", errors.Errorf("Error hashing %s: %v", contextDir, err)
+		}
+
+		hashes = append(hashes, contextHash)
+	}
+
+	return hash.String(strings.Join(hashes, ";"))[:contentHashTagLength], nil
+}
+
+// getContextExcludes returns the absolute context directory and the patterns of the files that are excluded from
+// the context. The dockerfile path is empty for images that are not built from a dockerfile
+func getContextExcludes(contextPath, dockerfilePath string) (string, []string, error) {
+	if dockerfilePath == "" {
+		contextDir, err := filepath.Abs(contextPath)
+		if err != nil {
+			return "", nil, errors.Errorf("Couldn't determine absolute path for %s", contextPath)
+		}
+
+		excludes, err := ReadDockerignore(contextDir, "")
+		if err != nil {
+			return "", nil, errors.Errorf("Error reading .dockerignore: %v", err)
+		}
+
+		return contextDir, excludes, nil
+	}
+
+	contextDir, relDockerfile, err := build.GetContextFromLocalDir(contextPath, dockerfilePath)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "get context from local dir")
+	}
+
+	relDockerfile = archive.CanonicalTarNameForPath(relDockerfile)
+	excludes, err := ReadDockerignore(contextDir, relDockerfile)
+	if err != nil {
+		return "", nil, errors.Errorf("Error reading .dockerignore: %v", err)
+	}
+
+	return contextDir, excludes, nil
+}
+
+// GetContextSize returns the size in bytes of the files in the context of the image that are not excluded by
+// the .dockerignore
+func GetContextSize(imageConf *latest.ImageConfig) (int64, error) {
+	dockerfilePath, contextPath := GetDockerfileAndContext(imageConf)
+	if !UsesDockerfile(imageConf) {
+		dockerfilePath = ""
+	}
+
+	contextDir, excludes, err := getContextExcludes(contextPath, dockerfilePath)
+	if err != nil {
+		return 0, err
+	}
+
+	pm, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(0)
+	err = filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		} else if relPath == "." {
+			return nil
+		}
+
+		skip, err := pm.Matches(relPath)
+		if err != nil {
+			return err
+		} else if skip {
+			// Directories can only be skipped if no exclusion might include a file in them
+			if info.IsDir() && !pm.Exclusions() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "walk context %s", contextDir)
+	}
+
+	return size, nil
+}
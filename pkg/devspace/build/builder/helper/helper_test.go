@@ -1,5 +1,16 @@
 package helper
 
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+
+	"gotest.tools/assert"
+)
+
 /*var expectedAbsoluteContextPath, expectedAbsoluteDockerfilePath string
 var expectedEntryPoint *[]*string
 var expectedLog log.Logger
@@ -9,7 +20,7 @@ var returnErr error
 
 type fakeBuilder struct{}
 
-func (builder fakeBuilder) BuildImage(absoluteContextPath string, absoluteDockerfilePath string, entrypoint []string, cmd []string, log log.Logger) error {
+func (builder fakeBuilder) BuildImage(ctx context.Context, absoluteContextPath string, absoluteDockerfilePath string, entrypoint []string, cmd []string, log log.Logger) error {
 	assert.Equal(usedT, expectedAbsoluteContextPath, absoluteContextPath, "Wrong context path given to builder")
 	assert.Equal(usedT, expectedAbsoluteDockerfilePath, absoluteDockerfilePath, "Wrong dockerfile path given to builder")
 	assert.Equal(usedT, expectedEntryPoint, expectedEntryPoint, "Wrong entryPoints given to builder")
@@ -39,13 +50,13 @@ func TestBuild(t *testing.T) {
 	usedT = t
 	returnErr = nil
 
-	err = helper.Build(fakeBuilder{}, expectedLog)
+	err = helper.Build(context.Background(), fakeBuilder{}, expectedLog)
 	assert.NilError(t, err, "Error building image")
 	assert.Equal(t, true, buildImageCalled, "BuildImage of ImageBuilder is not called")
 
 	returnErr = errors.Errorf("SomeErr")
 	buildImageCalled = false
-	err = helper.Build(fakeBuilder{}, expectedLog)
+	err = helper.Build(context.Background(), fakeBuilder{}, expectedLog)
 	assert.Equal(t, true, buildImageCalled, "BuildImage of ImageBuilder is not called")
 	assert.Error(t, err, "Error during image build: SomeErr", "No or wrong error passed")
 }
@@ -107,3 +118,68 @@ func TestShouldRebuild(t *testing.T) {
 	assert.Equal(t, false, cache.Images["ImageConf"].ImageConfigHash == "", "ImageConfigHash not set")
 	assert.Equal(t, false, cache.Images["ImageConf"].EntrypointHash == "", "EntrypointHash not set")
 }*/
+
+func TestGetContentHashTag(t *testing.T) {
+	wdBackup, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error getting current working directory: %v", err)
+	}
+	defer func() {
+		err = os.Chdir(wdBackup)
+		if err != nil {
+			t.Fatalf("Error changing dir back: %v", err)
+		}
+	}()
+
+	imageConf := &latest.ImageConfig{
+		Image:      "john/image",
+		Dockerfile: "./Dockerfile",
+		Context:    "./",
+	}
+
+	// The same sources in different directories result in the same tag
+	tags := []string{}
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "testDir")
+		if err != nil {
+			t.Fatalf("Error creating temporary directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\nCOPY . /app\n"), 0644))
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+		assert.NilError(t, os.Chdir(dir))
+
+		tag, err := GetContentHashTag(imageConf, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(tag), contentHashTagLength)
+		tags = append(tags, tag)
+	}
+	assert.Equal(t, tags[0], tags[1], "Same sources result in different tags")
+
+	// Tags do not change the content hash
+	tag, err := GetContentHashTag(&latest.ImageConfig{Image: "john/image", Dockerfile: "./Dockerfile", Context: "./", Tags: []string{"latest"}}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, tag, tags[0], "Image tags changed the content hash")
+
+	// Base images change the content hash
+	tag, err = GetContentHashTag(imageConf, map[string]string{"john/base": "john/base:abc"})
+	assert.NilError(t, err)
+	assert.Assert(t, tag != tags[0], "Base images did not change the content hash")
+
+	// Context changes change the content hash
+	assert.NilError(t, ioutil.WriteFile("main.go", []byte("package changed"), 0644))
+	tag, err = GetContentHashTag(imageConf, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, tag != tags[0], "Context changes did not change the content hash")
+
+	// Images that are built without a dockerfile don't need one
+	assert.NilError(t, os.Remove("Dockerfile"))
+	goBuildConf := &latest.ImageConfig{Image: "john/image", Context: "./", Build: &latest.BuildConfig{GoBuild: &latest.GoBuildConfig{}}}
+	tag, err = GetContentHashTag(goBuildConf, nil)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile("main.go", []byte("package main"), 0644))
+	changedTag, err := GetContentHashTag(goBuildConf, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, tag != changedTag, "Context changes did not change the content hash")
+}
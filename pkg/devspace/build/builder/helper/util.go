@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/pkg/fileutils"
 	scanner2 "github.com/loft-sh/devspace/pkg/util/scanner"
 	"github.com/moby/buildkit/frontend/dockerfile/dockerignore"
@@ -72,13 +73,18 @@ func ensureDockerIgnoreAndDockerFile(excludes []string, dockerfile string, useDe
 			excludes = append(excludes, "!.dockerignore")
 		}
 	}
-	if keep, _ := fileutils.Matches(dockerfile, excludes); keep {
+	if keep, _ := fileutils.Matches(dockerfile, excludes); keep && dockerfile != "" {
 		excludes = append(excludes, "!"+dockerfile)
 	}
 	excludes = append(excludes, ".devspace/")
 	return excludes
 }
 
+// UsesDockerfile returns if the image is built from a dockerfile, which is the case for all builders except goBuild
+func UsesDockerfile(imageConf *latest.ImageConfig) bool {
+	return imageConf.Build == nil || imageConf.Build.GoBuild == nil
+}
+
 // GetDockerfileAndContext retrieves the dockerfile and context
 func GetDockerfileAndContext(imageConf *latest.ImageConfig) (string, string) {
 	var (
@@ -264,6 +270,106 @@ func GetDockerfileTargets(dockerfile string) ([]string, error) {
 	return targets, nil
 }
 
+var fromLinePattern = regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--\S+\s+)*)(\S+)(.*)$`)
+var fromStagePattern = regexp.MustCompile(`(?i)\s+AS\s+(\S+)\s*$`)
+
+// GetImageRepository returns the normalized repository of the given image without tag and digest,
+// e.g. myimage:v1 returns docker.io/library/myimage. Returns an empty string if the image is invalid
+func GetImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+
+	return named.Name()
+}
+
+// GetBaseImages returns the images of all FROM instructions in the given dockerfile that don't
+// reference a previous build stage
+func GetBaseImages(dockerfile string) ([]string, error) {
+	data, err := ioutil.ReadFile(dockerfile)
+	if err != nil {
+		return nil, err
+	}
+
+	baseImages := []string{}
+	stages := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		matches := fromLinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		if !stages[strings.ToLower(matches[2])] {
+			baseImages = append(baseImages, matches[2])
+		}
+		if stage := fromStagePattern.FindStringSubmatch(matches[3]); stage != nil {
+			stages[strings.ToLower(stage[1])] = true
+		}
+	}
+
+	return baseImages, nil
+}
+
+// RewriteBaseImages creates a new temporary dockerfile where the images of FROM instructions are replaced with
+// the given base images. The keys of baseImages are image names without tag. If no FROM instruction was replaced,
+// an empty string is returned
+func RewriteBaseImages(dockerfile string, baseImages map[string]string) (string, error) {
+	data, err := ioutil.ReadFile(dockerfile)
+	if err != nil {
+		return "", err
+	}
+
+	repositories := map[string]string{}
+	for image, replacement := range baseImages {
+		if repository := GetImageRepository(image); repository != "" {
+			repositories[repository] = replacement
+		}
+	}
+
+	replaced := false
+	stages := map[string]bool{}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		matches := fromLinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		if replacement, ok := repositories[GetImageRepository(matches[2])]; ok && !stages[strings.ToLower(matches[2])] {
+			lines[i] = matches[1] + replacement + matches[3]
+			replaced = true
+		}
+		if stage := fromStagePattern.FindStringSubmatch(matches[3]); stage != nil {
+			stages[strings.ToLower(stage[1])] = true
+		}
+	}
+	if !replaced {
+		return "", nil
+	}
+
+	tmpDir, err := ioutil.TempDir("", "dockerfile")
+	if err != nil {
+		return "", err
+	}
+
+	tmpfn := filepath.Join(tmpDir, "Dockerfile")
+	err = ioutil.WriteFile(tmpfn, []byte(strings.Join(lines, "\n")), 0666)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+
+	return tmpfn, nil
+}
+
 var nextFromFinder = regexp.MustCompile("(?i)\n\\s*FROM")
 
 func addNewEntrypoint(content string, entrypoint []string, cmd []string, additionalLines []string, target string) (string, error) {
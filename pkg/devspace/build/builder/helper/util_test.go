@@ -3,6 +3,7 @@ package helper
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
@@ -45,3 +46,31 @@ func TestCreateTempDockerfile(t *testing.T) {
 	assert.NilError(t, err, "Temporary Dockerfile not created.")
 	assert.Equal(t, "\n\nENTRYPOINT [\"echo\"]\n\n\nCMD [\"\"]\n", string(dockerfileContent), "Temporary dockerfile has wrong content")
 }
+
+func TestRewriteBaseImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testDir")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	err = ioutil.WriteFile(dockerfile, []byte("FROM --platform=linux/amd64 john/base:latest AS base\nRUN make\nfrom base\nFROM golang:1.16 as build\nFROM john/base@sha256:abc\n"), 0644)
+	assert.NilError(t, err)
+
+	baseImages, err := GetBaseImages(dockerfile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, baseImages, []string{"john/base:latest", "golang:1.16", "john/base@sha256:abc"})
+
+	rewritten, err := RewriteBaseImages(dockerfile, map[string]string{"docker.io/john/base": "john/base:abcdefg"})
+	assert.NilError(t, err)
+	defer os.RemoveAll(filepath.Dir(rewritten))
+
+	content, err := ioutil.ReadFile(rewritten)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "FROM --platform=linux/amd64 john/base:abcdefg AS base\nRUN make\nfrom base\nFROM golang:1.16 as build\nFROM john/base:abcdefg\n")
+
+	rewritten, err = RewriteBaseImages(dockerfile, map[string]string{"john/other": "john/other:abcdefg"})
+	assert.NilError(t, err)
+	assert.Equal(t, rewritten, "")
+}
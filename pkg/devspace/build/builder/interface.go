@@ -1,12 +1,20 @@
 package builder
 
 import (
+	"context"
+
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/util/log"
 )
 
-// Interface defines methods for builders docker, kaniko and custom
+// Interface defines methods for builders docker, kaniko and custom. A build is aborted if the context
+// passed to Build is canceled
 type Interface interface {
 	ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool) (bool, error)
-	Build(log log.Logger) error
+	Build(ctx context.Context, log log.Logger) error
+}
+
+// Pusher is implemented by builders that do not always push the built image to the registry
+type Pusher interface {
+	ShouldPush() bool
 }
@@ -117,6 +117,11 @@ func (b *Builder) getBuildPod(buildID string, options *types.ImageBuildOptions,
 		kanikoArgs = append(kanikoArgs, "--cache=true", "--cache-repo="+ref.Name())
 	}
 
+	// use the cache volume as base image cache
+	if kanikoOptions.CacheVolume != nil {
+		kanikoArgs = append(kanikoArgs, "--cache-dir="+kanikoCacheDir)
+	}
+
 	// extra flags
 	kanikoArgs = append(kanikoArgs, kanikoOptions.Args...)
 
@@ -210,6 +215,22 @@ func (b *Builder) getBuildPod(buildID string, options *types.ImageBuildOptions,
 		})
 	}
 
+	// mount the cache volume
+	if kanikoOptions.CacheVolume != nil {
+		volumes = append(volumes, k8sv1.Volume{
+			Name: "cache",
+			VolumeSource: k8sv1.VolumeSource{
+				PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{
+					ClaimName: GetCacheVolumeName(b.helper.ImageConfigName, kanikoOptions.CacheVolume),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, k8sv1.VolumeMount{
+			Name:      "cache",
+			MountPath: kanikoCacheDir,
+		})
+	}
+
 	// create the build pod
 	pod := &k8sv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -356,6 +377,35 @@ func (b *Builder) getBuildPod(buildID string, options *types.ImageBuildOptions,
 		}
 	}
 
+	// download the base images into the cache volume before the build starts
+	if kanikoOptions.CacheVolume != nil {
+		warmerImages, err := getCacheWarmerImages(dockerfilePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "get base images")
+		}
+
+		if len(warmerImages) > 0 {
+			warmerArgs := []string{"--cache-dir=" + kanikoCacheDir}
+			for _, image := range warmerImages {
+				warmerArgs = append(warmerArgs, "--image="+image)
+			}
+			if b.allowInsecureRegistry {
+				warmerArgs = append(warmerArgs, "--insecure", "--skip-tls-verify")
+			}
+
+			pod.Spec.InitContainers = append(pod.Spec.InitContainers, k8sv1.Container{
+				Name:            "cache-warmer",
+				Image:           kanikoImage,
+				ImagePullPolicy: k8sv1.PullIfNotPresent,
+				Command:         []string{kanikoWarmer},
+				Args:            warmerArgs,
+				Env:             pod.Spec.Containers[0].Env,
+				Resources:       pod.Spec.Containers[0].Resources,
+				VolumeMounts:    volumeMounts[1:],
+			})
+		}
+	}
+
 	// return the build pod
 	return pod, nil
 }
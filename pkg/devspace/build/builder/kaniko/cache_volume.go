@@ -0,0 +1,138 @@
+package kaniko
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/encoding"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	k8sv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultCacheVolumeName is the prefix of the name of the persistent volume claim that is used as cache by default
+const DefaultCacheVolumeName = "devspace-kaniko-cache"
+
+// invalidNameChars matches the characters of an image config name that are not allowed in the name of a claim
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// CacheVolumeLabel is set on the persistent volume claims that are created by DevSpace
+const CacheVolumeLabel = "devspace-kaniko-cache"
+
+// The default size of the cache volume
+const defaultCacheVolumeSize = "10Gi"
+
+// The path the cache volume is mounted to in the build pod
+const kanikoCacheDir = "/cache"
+
+// The kaniko warmer that downloads the base images into the cache. It is part of the kaniko executor image
+const kanikoWarmer = "/kaniko/warmer"
+
+// GetCacheVolumeName returns the name of the persistent volume claim of the cache volume. Every image uses its own
+// claim by default, because the ReadWriteOnce claim cannot be mounted by build pods that run in parallel on different nodes
+func GetCacheVolumeName(imageConfigName string, cacheVolume *latest.KanikoCacheVolume) string {
+	if cacheVolume.Name != "" {
+		return cacheVolume.Name
+	}
+
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(imageConfigName), "-"), "-")
+	return encoding.SafeConcatName(DefaultCacheVolumeName, name)
+}
+
+// ensureCacheVolume creates the persistent volume claim of the cache volume in the build namespace if it does not exist yet
+func (b *Builder) ensureCacheVolume(log logpkg.Logger) error {
+	cacheVolume := b.helper.ImageConf.Build.Kaniko.CacheVolume
+	name := GetCacheVolumeName(b.helper.ImageConfigName, cacheVolume)
+	_, err := b.helper.KubeClient.KubeClient().CoreV1().PersistentVolumeClaims(b.BuildNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	} else if !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "get persistent volume claim %s", name)
+	}
+
+	size := defaultCacheVolumeSize
+	if cacheVolume.Size != "" {
+		size = cacheVolume.Size
+	}
+
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return errors.Wrapf(err, "parse cache volume size %s", size)
+	}
+
+	pvc := &k8sv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				CacheVolumeLabel: "true",
+			},
+		},
+		Spec: k8sv1.PersistentVolumeClaimSpec{
+			AccessModes: []k8sv1.PersistentVolumeAccessMode{k8sv1.ReadWriteOnce},
+			Resources: k8sv1.ResourceRequirements{
+				Requests: k8sv1.ResourceList{
+					k8sv1.ResourceStorage: quantity,
+				},
+			},
+		},
+	}
+	if cacheVolume.StorageClassName != "" {
+		pvc.Spec.StorageClassName = &cacheVolume.StorageClassName
+	}
+
+	_, err = b.helper.KubeClient.KubeClient().CoreV1().PersistentVolumeClaims(b.BuildNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+	if err != nil {
+		// the claim might have been created in the meantime by another build
+		if kerrors.IsAlreadyExists(err) {
+			return nil
+		}
+
+		return errors.Wrapf(err, "create persistent volume claim %s", name)
+	}
+
+	log.Donef("Created kaniko cache volume %s/%s", b.BuildNamespace, name)
+	return nil
+}
+
+// DeleteCacheVolume deletes the persistent volume claim of a cache volume. It returns false if the claim did not exist
+func DeleteCacheVolume(client kubernetes.Interface, namespace, name string) (bool, error) {
+	err := client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// getCacheWarmerImages returns the base images of the dockerfile that can be downloaded into the cache. Images
+// with build args cannot be resolved before the build and are skipped
+func getCacheWarmerImages(dockerfilePath string) ([]string, error) {
+	baseImages, err := helper.GetBaseImages(dockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []string{}
+	seen := map[string]bool{}
+	for _, image := range baseImages {
+		if strings.Contains(image, "$") || strings.ToLower(image) == "scratch" || seen[image] {
+			continue
+		}
+
+		seen[image] = true
+		images = append(images, image)
+	}
+
+	return images, nil
+}
@@ -0,0 +1,97 @@
+package kaniko
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetCacheWarmerImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaniko")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	err = ioutil.WriteFile(dockerfile, []byte(`FROM golang:1.15 AS build
+FROM node:${NODE_VERSION} AS frontend
+FROM golang:1.15
+FROM build
+FROM scratch
+COPY --from=build /app /app
+`), 0644)
+	assert.NilError(t, err)
+
+	images, err := getCacheWarmerImages(dockerfile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, images, []string{"golang:1.15"})
+}
+
+func TestDeleteCacheVolume(t *testing.T) {
+	client := fake.NewSimpleClientset(&k8sv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultCacheVolumeName,
+			Namespace: "default",
+		},
+	})
+
+	deleted, err := DeleteCacheVolume(client, "default", DefaultCacheVolumeName)
+	assert.NilError(t, err)
+	assert.Equal(t, deleted, true)
+
+	_, err = client.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), DefaultCacheVolumeName, metav1.GetOptions{})
+	assert.Assert(t, err != nil, "Cache volume was not deleted")
+
+	deleted, err = DeleteCacheVolume(client, "default", DefaultCacheVolumeName)
+	assert.NilError(t, err)
+	assert.Equal(t, deleted, false)
+}
+
+func TestGetCacheVolumeName(t *testing.T) {
+	assert.Equal(t, GetCacheVolumeName("backend", &latest.KanikoCacheVolume{}), "devspace-kaniko-cache-backend")
+	assert.Equal(t, GetCacheVolumeName("My_Backend", &latest.KanikoCacheVolume{}), "devspace-kaniko-cache-my-backend")
+	assert.Equal(t, GetCacheVolumeName("backend", &latest.KanikoCacheVolume{Name: "my-cache"}), "my-cache")
+}
+
+func TestEnsureCacheVolume(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	builder := &Builder{
+		BuildNamespace: "default",
+		helper: &helper.BuildHelper{
+			ImageConfigName: "backend",
+			ImageConf: &latest.ImageConfig{
+				Build: &latest.BuildConfig{
+					Kaniko: &latest.KanikoConfig{
+						CacheVolume: &latest.KanikoCacheVolume{},
+					},
+				},
+			},
+			KubeClient: &fakekube.Client{Client: client},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	logger := log.NewStreamLogger(output, logrus.InfoLevel)
+	for i := 0; i < 2; i++ {
+		err := builder.ensureCacheVolume(logger)
+		assert.NilError(t, err)
+	}
+
+	pvc, err := client.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "devspace-kaniko-cache-backend", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, pvc.Spec.Resources.Requests.Storage().String(), defaultCacheVolumeSize)
+	assert.Equal(t, strings.Count(output.String(), "Created kaniko cache volume"), 1, "Unexpected output: %s", output.String())
+}
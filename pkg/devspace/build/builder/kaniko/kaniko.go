@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	k8sv1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -63,7 +64,11 @@ type Builder struct {
 const waitTimeout = 20 * time.Minute
 
 // NewBuilder creates a new kaniko.Builder instance
-func NewBuilder(config config.Config, dockerClient docker.Client, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, log logpkg.Logger) (builder.Interface, error) {
+func NewBuilder(config config.Config, dockerClient docker.Client, kubeClient kubectl.Client, imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string, log logpkg.Logger) (builder.Interface, error) {
+	if len(imageConf.Platforms) > 0 {
+		return nil, errors.Errorf("image %s specifies platforms, which kaniko does not support. Please build the image with docker or buildKit instead", imageConfigName)
+	}
+
 	buildNamespace := kubeClient.Namespace()
 	if imageConf.Build.Kaniko.Namespace != "" {
 		buildNamespace = imageConf.Build.Kaniko.Namespace
@@ -87,7 +92,7 @@ func NewBuilder(config config.Config, dockerClient docker.Client, kubeClient kub
 		allowInsecureRegistry: allowInsecurePush,
 
 		dockerClient: dockerClient,
-		helper:       helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags),
+		helper:       helper.NewBuildHelper(config, kubeClient, EngineName, imageConfigName, imageConf, imageTags, baseImages),
 	}
 
 	// create pull secret
@@ -102,8 +107,8 @@ func NewBuilder(config config.Config, dockerClient docker.Client, kubeClient kub
 }
 
 // Build implements the interface
-func (b *Builder) Build(log logpkg.Logger) error {
-	return b.helper.Build(b, log)
+func (b *Builder) Build(ctx context.Context, log logpkg.Logger) error {
+	return b.helper.Build(ctx, b, log)
 }
 
 // ShouldRebuild determines if an image has to be rebuilt
@@ -147,8 +152,8 @@ func (b *Builder) createPullSecret(log logpkg.Logger) error {
 	})
 }
 
-// BuildImage builds a dockerimage within a kaniko pod
-func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
+// BuildImage builds a dockerimage within a kaniko pod. If the context is canceled, the build pod is deleted
+func (b *Builder) BuildImage(ctx context.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, log logpkg.Logger) error {
 	var err error
 
 	// Buildoptions
@@ -183,6 +188,14 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		return errors.Wrap(err, "get build pod")
 	}
 
+	// Create the cache volume if it does not exist yet
+	if b.helper.ImageConf.Build.Kaniko.CacheVolume != nil {
+		err = b.ensureCacheVolume(log)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Delete the build pod when we are done or get interrupted during build
 	deleteBuildPod := func() {
 		gracePeriod := int64(3)
@@ -199,17 +212,28 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		}
 	}
 
+	// Delete the build pod if the build is canceled, which also stops waiting for the pod and streaming its logs
+	stopCancelWatch := make(chan struct{})
+	defer close(stopCancelWatch)
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.deleteBuildPods(buildID, log)
+		case <-stopCancelWatch:
+		}
+	}()
+
 	err = interrupt.Global.RunAlways(func() error {
 		defer log.StopWait()
 
-		buildPodCreated, err := b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Create(context.TODO(), buildPod, metav1.CreateOptions{})
+		buildPodCreated, err := b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Create(ctx, buildPod, metav1.CreateOptions{})
 		if err != nil {
 			return errors.Errorf("unable to create build pod: %s", err.Error())
 		}
 
 		log.StartWait("Waiting for build init container to start")
 		err = wait.PollImmediate(time.Second, waitTimeout, func() (done bool, err error) {
-			buildPod, err = b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(context.TODO(), buildPodCreated.Name, metav1.GetOptions{})
+			buildPod, err = b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(ctx, buildPodCreated.Name, metav1.GetOptions{})
 			if err != nil {
 				if kerrors.IsNotFound(err) {
 					return false, nil
@@ -220,7 +244,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 				status := buildPod.Status.InitContainerStatuses[0]
 				if status.State.Terminated != nil {
 					errorLog := ""
-					reader, _ := b.helper.KubeClient.Logs(context.TODO(), b.BuildNamespace, buildPodCreated.Name, buildPod.Spec.InitContainers[0].Name, false, nil, false)
+					reader, _ := b.helper.KubeClient.Logs(ctx, b.BuildNamespace, buildPodCreated.Name, buildPod.Spec.InitContainers[0].Name, false, nil, false)
 					if reader != nil {
 						out, err := ioutil.ReadAll(reader)
 						if err == nil {
@@ -338,18 +362,32 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 		log.Done("Uploaded files to container")
 		log.StartWait("Waiting for kaniko container to start")
 		err = wait.PollImmediate(time.Second, waitTimeout, func() (done bool, err error) {
-			buildPod, err = b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(context.TODO(), buildPodCreated.Name, metav1.GetOptions{})
+			buildPod, err = b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(ctx, buildPodCreated.Name, metav1.GetOptions{})
 			if err != nil {
 				if kerrors.IsNotFound(err) {
 					return false, nil
 				}
 
 				return false, err
+			} else if status := getFailedInitContainer(buildPod); status != nil {
+				errorLog := ""
+				reader, _ := b.helper.KubeClient.Logs(ctx, b.BuildNamespace, buildPodCreated.Name, status.Name, false, nil, false)
+				if reader != nil {
+					out, err := ioutil.ReadAll(reader)
+					if err == nil {
+						errorLog = string(out)
+					}
+				}
+				if errorLog == "" {
+					errorLog = status.State.Terminated.Message
+				}
+
+				return false, fmt.Errorf("kaniko init container %s in pod %s/%s has unexpectedly exited with code %d: %s", status.Name, buildPod.Namespace, buildPod.Name, status.State.Terminated.ExitCode, errorLog)
 			} else if len(buildPod.Status.ContainerStatuses) > 0 {
 				status := buildPod.Status.ContainerStatuses[0]
 				if status.State.Terminated != nil {
 					errorLog := ""
-					reader, _ := b.helper.KubeClient.Logs(context.TODO(), b.BuildNamespace, buildPodCreated.Name, status.Name, false, nil, false)
+					reader, _ := b.helper.KubeClient.Logs(ctx, b.BuildNamespace, buildPodCreated.Name, status.Name, false, nil, false)
 					if reader != nil {
 						out, err := ioutil.ReadAll(reader)
 						if err == nil {
@@ -398,7 +436,7 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 			time.Sleep(time.Second)
 
 			// Check if build was successful
-			pod, err := b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(context.TODO(), buildPodCreated.Name, metav1.GetOptions{})
+			pod, err := b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Get(ctx, buildPodCreated.Name, metav1.GetOptions{})
 			if err != nil {
 				return errors.Errorf("Error checking if build was successful: %v", err)
 			}
@@ -434,3 +472,36 @@ func (b *Builder) BuildImage(contextPath, dockerfilePath string, entrypoint []st
 
 	return nil
 }
+
+// deleteBuildPods deletes the build pods of the given build
+func (b *Builder) deleteBuildPods(buildID string, log logpkg.Logger) {
+	pods, err := b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "devspace-build-id=" + buildID,
+	})
+	if err != nil {
+		log.Errorf("Failed to list build pods: %v", err)
+		return
+	}
+
+	gracePeriod := int64(3)
+	for _, pod := range pods.Items {
+		err = b.helper.KubeClient.KubeClient().CoreV1().Pods(b.BuildNamespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+		})
+		if err != nil && !kerrors.IsNotFound(err) {
+			log.Errorf("Failed to delete build pod: %v", err)
+		}
+	}
+}
+
+// getFailedInitContainer returns the status of an init container of the build pod that has failed, e.g. the cache warmer
+func getFailedInitContainer(pod *k8sv1.Pod) *k8sv1.ContainerStatus {
+	for i := range pod.Status.InitContainerStatuses {
+		status := &pod.Status.InitContainerStatuses[i]
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return status
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package testing
 
 import (
+	"context"
+
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/util/log"
 )
@@ -15,6 +17,6 @@ func (b *Builder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild, igno
 }
 
 // Build is a fake implementation of the function
-func (b *Builder) Build(log log.Logger) error {
+func (b *Builder) Build(ctx context.Context, log log.Logger) error {
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/custom"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/gobuild"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/kaniko"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
@@ -19,16 +20,18 @@ import (
 )
 
 // createBuilder creates a new builder
-func (c *controller) createBuilder(imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, options *Options, log log.Logger) (builder.Interface, error) {
+func (c *controller) createBuilder(imageConfigName string, imageConf *latest.ImageConfig, imageTags []string, baseImages map[string]string, options *Options, log log.Logger) (builder.Interface, error) {
 	var err error
 	var builder builder.Interface
 
 	if imageConf.Build != nil && imageConf.Build.Custom != nil {
 		builder = custom.NewBuilder(imageConfigName, imageConf, imageTags)
+	} else if imageConf.Build != nil && imageConf.Build.GoBuild != nil {
+		builder = gobuild.NewBuilder(c.config, c.client, imageConfigName, imageConf, imageTags, baseImages, options.SkipPush)
 	} else if imageConf.Build != nil && imageConf.Build.BuildKit != nil {
 		log.StartWait("Creating BuildKit builder")
 		defer log.StopWait()
-		builder, err = buildkit.NewBuilder(c.config, c.client, imageConfigName, imageConf, imageTags, options.SkipPush, options.SkipPushOnLocalKubernetes)
+		builder, err = buildkit.NewBuilder(c.config, c.client, imageConfigName, imageConf, imageTags, baseImages, options.SkipPush, options.SkipPushOnLocalKubernetes)
 		if err != nil {
 			return nil, errors.Errorf("Error creating kaniko builder: %v", err)
 		}
@@ -48,7 +51,7 @@ func (c *controller) createBuilder(imageConfigName string, imageConf *latest.Ima
 
 		log.StartWait("Creating kaniko builder")
 		defer log.StopWait()
-		builder, err = kaniko.NewBuilder(c.config, dockerClient, c.client, imageConfigName, imageConf, imageTags, log)
+		builder, err = kaniko.NewBuilder(c.config, dockerClient, c.client, imageConfigName, imageConf, imageTags, baseImages, log)
 		if err != nil {
 			return nil, errors.Errorf("Error creating kaniko builder: %v", err)
 		}
@@ -82,10 +85,10 @@ func (c *controller) createBuilder(imageConfigName string, imageConf *latest.Ima
 
 			// Fallback to kaniko
 			log.Infof("Couldn't find a running docker daemon. Will fallback to kaniko")
-			return c.createBuilder(imageConfigName, convertDockerConfigToKanikoConfig(imageConf), imageTags, options, log)
+			return c.createBuilder(imageConfigName, convertDockerConfigToKanikoConfig(imageConf), imageTags, baseImages, options, log)
 		}
 
-		builder, err = docker.NewBuilder(c.config, dockerClient, c.client, imageConfigName, imageConf, imageTags, options.SkipPush, options.SkipPushOnLocalKubernetes)
+		builder, err = docker.NewBuilder(c.config, dockerClient, c.client, imageConfigName, imageConf, imageTags, baseImages, options.SkipPush, options.SkipPushOnLocalKubernetes)
 		if err != nil {
 			return nil, errors.Errorf("Error creating docker builder: %v", err)
 		}
@@ -115,6 +118,7 @@ func convertDockerConfigToKanikoConfig(dockerConfig *latest.ImageConfig) *latest
 		RebuildStrategy:     dockerConfig.RebuildStrategy,
 		InjectRestartHelper: dockerConfig.InjectRestartHelper,
 		CreatePullSecret:    dockerConfig.CreatePullSecret,
+		Platforms:           dockerConfig.Platforms,
 		Build: &latest.BuildConfig{
 			Kaniko: kanikoBuildOptions,
 		},
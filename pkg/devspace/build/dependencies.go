@@ -0,0 +1,152 @@
+package build
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/gobuild"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// imageGraph holds the build dependencies between the images that should be built
+type imageGraph struct {
+	// order are the image config names in an order where every image comes after its dependencies
+	order []string
+
+	// dependencies maps an image config name to the image config names it depends on
+	dependencies map[string][]string
+}
+
+// newImageGraph resolves the dependencies of the given images. Dependencies are either specified
+// in dependsOn or detected from the FROM instructions of the dockerfile or the base image of goBuild.
+// Disabled images are ignored
+func newImageGraph(images map[string]*latest.ImageConfig, log logpkg.Logger) (*imageGraph, error) {
+	graph := &imageGraph{
+		dependencies: map[string][]string{},
+	}
+
+	// map the image repositories to the images that are built
+	repositories := map[string]string{}
+	for imageConfigName, imageConf := range images {
+		if imageConf.Build != nil && imageConf.Build.Disabled {
+			continue
+		}
+
+		if repository := helper.GetImageRepository(imageConf.Image); repository != "" {
+			repositories[repository] = imageConfigName
+		}
+	}
+
+	for imageConfigName, imageConf := range images {
+		if imageConf.Build != nil && imageConf.Build.Disabled {
+			continue
+		}
+
+		dependencies := map[string]bool{}
+		for _, dependency := range imageConf.DependsOn {
+			dependencyConf, ok := images[dependency]
+			if !ok {
+				return nil, errors.Errorf("images.%s.dependsOn references unknown image %s", imageConfigName, dependency)
+			} else if dependencyConf.Build != nil && dependencyConf.Build.Disabled {
+				continue
+			}
+
+			dependencies[dependency] = true
+		}
+
+		// detect the images that are used as base images in the dockerfile
+		if imageConf.Build == nil || imageConf.Build.Custom == nil {
+			var baseImages []string
+			if helper.UsesDockerfile(imageConf) {
+				dockerfilePath, _ := helper.GetDockerfileAndContext(imageConf)
+				var err error
+				baseImages, err = helper.GetBaseImages(dockerfilePath)
+				if err != nil {
+					log.Debugf("Error detecting base images of image %s: %v", imageConfigName, err)
+				}
+			} else {
+				baseImages = []string{gobuild.GetBaseImage(imageConf.Build.GoBuild)}
+			}
+
+			for _, baseImage := range baseImages {
+				dependency, ok := repositories[helper.GetImageRepository(baseImage)]
+				if ok && dependency != imageConfigName {
+					dependencies[dependency] = true
+				}
+			}
+		}
+
+		graph.dependencies[imageConfigName] = []string{}
+		for dependency := range dependencies {
+			graph.dependencies[imageConfigName] = append(graph.dependencies[imageConfigName], dependency)
+		}
+		sort.Strings(graph.dependencies[imageConfigName])
+	}
+
+	order, err := graph.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	graph.order = order
+	return graph, nil
+}
+
+// sort sorts the images topologically and returns an error if there is a cyclic dependency
+func (g *imageGraph) sort() ([]string, error) {
+	names := make([]string, 0, len(g.dependencies))
+	for imageConfigName := range g.dependencies {
+		names = append(names, imageConfigName)
+	}
+	sort.Strings(names)
+
+	var (
+		order   = make([]string, 0, len(names))
+		visited = map[string]bool{}
+		visit   func(imageConfigName string, path []string) error
+	)
+	visit = func(imageConfigName string, path []string) error {
+		if visited[imageConfigName] {
+			return nil
+		}
+		for _, parent := range path {
+			if parent == imageConfigName {
+				return errors.Errorf("cyclic image dependency found: %s", strings.Join(append(path, imageConfigName), " -> "))
+			}
+		}
+
+		for _, dependency := range g.dependencies[imageConfigName] {
+			err := visit(dependency, append(path, imageConfigName))
+			if err != nil {
+				return err
+			}
+		}
+
+		visited[imageConfigName] = true
+		order = append(order, imageConfigName)
+		return nil
+	}
+
+	for _, imageConfigName := range names {
+		err := visit(imageConfigName, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// ready returns true if all dependencies of the image are finished
+func (g *imageGraph) ready(imageConfigName string, finished map[string]bool) bool {
+	for _, dependency := range g.dependencies[imageConfigName] {
+		if !finished[dependency] {
+			return false
+		}
+	}
+
+	return true
+}
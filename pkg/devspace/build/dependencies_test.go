@@ -0,0 +1,76 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+
+	"gotest.tools/assert"
+)
+
+func TestImageGraph(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dockerfiles := map[string]string{
+		"base":     "FROM alpine:3.12\nRUN apk add git\n",
+		"backend":  "FROM myregistry.com/base:latest AS build\nFROM build\n",
+		"frontend": "FROM node:14\n",
+		"worker":   "FROM docker.io/john/disabled\n",
+	}
+	for name, content := range dockerfiles {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	images := map[string]*latest.ImageConfig{
+		"base": {
+			Image:      "myregistry.com/base",
+			Dockerfile: filepath.Join(dir, "base"),
+		},
+		"backend": {
+			Image:      "myregistry.com/backend",
+			Dockerfile: filepath.Join(dir, "backend"),
+		},
+		"frontend": {
+			Image:      "myregistry.com/frontend",
+			Dockerfile: filepath.Join(dir, "frontend"),
+			DependsOn:  []string{"backend", "disabled"},
+		},
+		"worker": {
+			Image:      "myregistry.com/worker",
+			Dockerfile: filepath.Join(dir, "worker"),
+		},
+		"disabled": {
+			Image: "john/disabled",
+			Build: &latest.BuildConfig{Disabled: true},
+		},
+	}
+
+	graph, err := newImageGraph(images, log.Discard)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, graph.order, []string{"base", "backend", "frontend", "worker"})
+	assert.DeepEqual(t, graph.dependencies, map[string][]string{
+		"base":     {},
+		"backend":  {"base"},
+		"frontend": {"backend"},
+		"worker":   {},
+	})
+	assert.Equal(t, graph.ready("backend", map[string]bool{}), false)
+	assert.Equal(t, graph.ready("backend", map[string]bool{"base": true}), true)
+
+	// cyclic dependencies
+	images["base"].DependsOn = []string{"frontend"}
+	_, err = newImageGraph(images, log.Discard)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "backend -> base -> frontend -> backend"), "Unexpected error %v", err)
+}
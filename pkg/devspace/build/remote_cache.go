@@ -0,0 +1,91 @@
+package build
+
+import (
+	"context"
+
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// shouldRebuildFromRemoteCache determines if an image that is tagged with its content hash has to be built. If an image
+// with the content hash tag already exists in the registry, it is reused and recorded in the cache instead. If the image
+// does not have to be built, the reason why it is skipped is returned as well
+func (c *controller) shouldRebuildFromRemoteCache(imageConfigName, imageName, contentTag string, builtImages map[string]string, options *Options, log logpkg.Logger) (bool, string) {
+	if options.ForceRebuild {
+		return true, ""
+	}
+
+	// Check if the image was built or reused by the last run
+	cache := c.config.Generated().GetActive()
+	imageCache := cache.GetImageCache(imageConfigName)
+	if imageCache.ImageName == imageName && imageCache.Tag == contentTag {
+		// The image might only exist in the docker daemon of the previous local kubernetes context
+		if c.client == nil || cache.LastContext == nil || cache.LastContext.Context == c.client.CurrentContext() || !kubectl.IsLocalKubernetes(cache.LastContext.Context) {
+			return false, SkipReasonUnchanged
+		}
+	}
+
+	log.StartWait("Checking registry for image " + imageName + ":" + contentTag)
+	exists, err := c.remoteImageExists(imageName, contentTag, log)
+	log.StopWait()
+	if err != nil {
+		log.Warnf("Error checking registry for image %s:%s: %v", imageName, contentTag, err)
+		return true, ""
+	} else if !exists {
+		log.Debugf("Image %s:%s does not exist in the registry", imageName, contentTag)
+		return true, ""
+	}
+
+	log.Infof("Reuse image '%s:%s' from the registry", imageName, contentTag)
+	imageCache.ImageName = imageName
+	imageCache.Tag = contentTag
+	builtImages[imageName] = contentTag
+	return false, SkipReasonRemoteCache
+}
+
+// remoteImageExists checks via the registry api if the tag of the image exists
+func (c *controller) remoteImageExists(imageName, tag string, log logpkg.Logger) (bool, error) {
+	registryClient, err := newRegistryClient(imageName, log, "pull")
+	if err != nil {
+		return false, err
+	}
+
+	return registryClient.TagExists(context.Background(), tag)
+}
+
+// getImageDigest returns the digest of the manifest the tag of the image references in the registry
+func getImageDigest(imageName, tag string, log logpkg.Logger) (string, error) {
+	registryClient, err := newRegistryClient(imageName, log, "pull")
+	if err != nil {
+		return "", err
+	}
+
+	descriptor, err := registryClient.GetManifestDescriptor(context.Background(), tag)
+	if err != nil {
+		return "", err
+	}
+
+	return descriptor.Digest, nil
+}
+
+// newRegistryClient creates a registry client for the repository of the image with the credentials of the docker config
+func newRegistryClient(imageName string, log logpkg.Logger, actions ...string) (*dockerclient.RegistryClient, error) {
+	registryURL, err := pullsecrets.GetRegistryFromImageName(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := dockerclient.NewClient(log)
+	if err != nil {
+		return nil, err
+	}
+
+	authConfig, err := dockerClient.GetAuthConfig(registryURL, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return dockerclient.NewRegistryClient(imageName, authConfig, actions...)
+}
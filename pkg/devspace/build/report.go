@@ -0,0 +1,154 @@
+package build
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/buildkit"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/custom"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/gobuild"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/kaniko"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Reasons why an image was not built
+const (
+	// SkipReasonUnchanged is used if the sources of the image did not change since the last build
+	SkipReasonUnchanged = "unchanged"
+	// SkipReasonRemoteCache is used if an image with the same content hash was found in the registry
+	SkipReasonRemoteCache = "remoteCache"
+	// SkipReasonDisabled is used if building the image is disabled in the config
+	SkipReasonDisabled = "disabled"
+)
+
+// Report is a machine readable description of the images of a single build
+type Report struct {
+	Images []*ImageReport `yaml:"images" json:"images"`
+}
+
+// ImageReport describes how a single image was built
+type ImageReport struct {
+	// Name is the name of the image in the config
+	Name string `yaml:"name" json:"name"`
+	// Image is the image repository
+	Image string `yaml:"image" json:"image"`
+	// Builder is the build engine that was used to build the image
+	Builder string `yaml:"builder,omitempty" json:"builder,omitempty"`
+	// Tags are the tags of the image. The first tag is the one that is used for deployments
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Digest is the digest of the manifest in the registry, if the image was pushed
+	Digest string `yaml:"digest,omitempty" json:"digest,omitempty"`
+	// Status is either built, skipped, failed or building, if the build was aborted
+	Status string `yaml:"status" json:"status"`
+	// SkipReason explains why an image was skipped
+	SkipReason string `yaml:"skipReason,omitempty" json:"skipReason,omitempty"`
+	// Attempts is the amount of build attempts
+	Attempts int `yaml:"attempts,omitempty" json:"attempts,omitempty"`
+	// DurationSeconds is the time all build attempts took
+	DurationSeconds float64 `yaml:"durationSeconds" json:"durationSeconds"`
+	// ContextSize is the size of the build context in bytes
+	ContextSize int64 `yaml:"contextSize,omitempty" json:"contextSize,omitempty"`
+	// Error is the error of the last build attempt
+	Error string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// WriteReport writes the report to the given file. Files with a .yaml or .yml extension are written as yaml,
+// all other files as json
+func WriteReport(report *Report, path string) error {
+	var (
+		out []byte
+		err error
+	)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		out, err = yaml.Marshal(report)
+	default:
+		out, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return errors.Wrap(err, "marshal build report")
+	}
+
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+// newReport creates the build report from the build summaries and looks up the digests of the pushed images
+func newReport(summaries map[string]*imageBuildSummary, log logpkg.Logger) *Report {
+	imageConfigNames := []string{}
+	for imageConfigName := range summaries {
+		imageConfigNames = append(imageConfigNames, imageConfigName)
+	}
+	sort.Strings(imageConfigNames)
+
+	report := &Report{
+		Images: []*ImageReport{},
+	}
+	for _, imageConfigName := range imageConfigNames {
+		summary := summaries[imageConfigName]
+		summary.m.Lock()
+		imageReport := &ImageReport{
+			Name:            imageConfigName,
+			Image:           summary.image,
+			Builder:         summary.builder,
+			Tags:            summary.tags,
+			Status:          summary.status,
+			SkipReason:      summary.skipReason,
+			Attempts:        summary.attempts,
+			DurationSeconds: summary.duration.Seconds(),
+			ContextSize:     summary.contextSize,
+		}
+		if summary.err != nil {
+			imageReport.Error = summary.err.Error()
+		}
+		summary.m.Unlock()
+
+		// Only images in the registry have a digest
+		if summary.pushed && len(imageReport.Tags) > 0 && (imageReport.Status == buildStatusBuilt || imageReport.Status == buildStatusSkipped) {
+			digest, err := getImageDigest(imageReport.Image, imageReport.Tags[0], log)
+			if err != nil {
+				log.Warnf("Error retrieving digest of image %s:%s: %v", imageReport.Image, imageReport.Tags[0], err)
+			} else {
+				imageReport.Digest = digest
+			}
+		}
+
+		report.Images = append(report.Images, imageReport)
+	}
+
+	return report
+}
+
+// builderName returns the name of the build engine of the builder
+func builderName(b builder.Interface) string {
+	switch b.(type) {
+	case *docker.Builder:
+		return docker.EngineName
+	case *buildkit.Builder:
+		return buildkit.EngineName
+	case *kaniko.Builder:
+		return kaniko.EngineName
+	case *gobuild.Builder:
+		return gobuild.EngineName
+	case *custom.Builder:
+		return "custom"
+	}
+
+	return ""
+}
+
+// shouldPush returns true if the builder pushes the image to the registry
+func shouldPush(b builder.Interface) bool {
+	if pusher, ok := b.(builder.Pusher); ok {
+		return pusher.ShouldPush()
+	}
+
+	return true
+}
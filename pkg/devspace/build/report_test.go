@@ -0,0 +1,82 @@
+package build
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"gotest.tools/assert"
+)
+
+func TestReport(t *testing.T) {
+	summaries := map[string]*imageBuildSummary{
+		"frontend": {
+			status:     buildStatusSkipped,
+			image:      "john/frontend",
+			builder:    "docker",
+			tags:       []string{"abc"},
+			skipReason: SkipReasonUnchanged,
+		},
+		"backend": {
+			status:      buildStatusFailed,
+			attempts:    2,
+			duration:    1500 * time.Millisecond,
+			err:         errors.New("connection reset"),
+			image:       "john/backend",
+			builder:     "buildkit",
+			tags:        []string{"def", "latest"},
+			contextSize: 1024,
+		},
+	}
+
+	report := newReport(summaries, log.Discard)
+	assert.DeepEqual(t, report.Images, []*ImageReport{
+		{
+			Name:            "backend",
+			Image:           "john/backend",
+			Builder:         "buildkit",
+			Tags:            []string{"def", "latest"},
+			Status:          buildStatusFailed,
+			Attempts:        2,
+			DurationSeconds: 1.5,
+			ContextSize:     1024,
+			Error:           "connection reset",
+		},
+		{
+			Name:       "frontend",
+			Image:      "john/frontend",
+			Builder:    "docker",
+			Tags:       []string{"abc"},
+			Status:     buildStatusSkipped,
+			SkipReason: SkipReasonUnchanged,
+		},
+	})
+
+	dir, err := ioutil.TempDir("", "report")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The format depends on the file extension
+	for _, file := range []string{"report.json", "report.yaml", "report.yml", "report"} {
+		path := filepath.Join(dir, file)
+		assert.NilError(t, WriteReport(report, path))
+
+		out, err := ioutil.ReadFile(path)
+		assert.NilError(t, err)
+
+		written := &Report{}
+		if filepath.Ext(file) == ".yaml" || filepath.Ext(file) == ".yml" {
+			assert.NilError(t, yaml.UnmarshalStrict(out, written), "Report %s is not yaml", file)
+		} else {
+			assert.NilError(t, json.Unmarshal(out, written), "Report %s is not json", file)
+		}
+		assert.DeepEqual(t, written, report)
+	}
+}
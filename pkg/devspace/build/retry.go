@@ -0,0 +1,210 @@
+package build
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRetryBackoff = 5 * time.Second
+	maxRetryBackoff     = 5 * time.Minute
+)
+
+// sleep is used to wait between build attempts and can be replaced in tests
+var sleep = time.Sleep
+
+// transientErrors are parts of error messages that indicate that a build failed because of a network
+// or registry problem and might succeed if it is retried
+var transientErrors = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"no such host",
+	"server misbehaving",
+	"network is unreachable",
+	"unexpected eof",
+	"timeout exceeded",
+	"too many requests",
+	"toomanyrequests",
+	"service unavailable",
+	"bad gateway",
+	"gateway timeout",
+	"internal server error",
+	"status: 429",
+	"status: 500",
+	"status: 502",
+	"status: 503",
+	"status: 504",
+}
+
+// Build statuses that are shown in the build summary
+const (
+	buildStatusBuilding = "building"
+	buildStatusBuilt    = "built"
+	buildStatusSkipped  = "skipped"
+	buildStatusFailed   = "failed"
+)
+
+// imageBuildSummary describes how an image was built and is printed after all images were built
+type imageBuildSummary struct {
+	m sync.Mutex
+
+	status   string
+	attempts int
+	duration time.Duration
+	err      error
+
+	// The following fields are set before the image is built and are only used for the build report
+	image       string
+	builder     string
+	tags        []string
+	pushed      bool
+	skipReason  string
+	contextSize int64
+}
+
+func (s *imageBuildSummary) set(status string, attempts int, duration time.Duration, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.status = status
+	s.attempts = attempts
+	s.duration = duration
+	s.err = err
+}
+
+// buildWithRetry builds the image with the given builder. A single build attempt is aborted after
+// the configured timeout and builds that failed because of a network or registry error are retried
+// with an exponential backoff
+func buildWithRetry(imageConfigName string, builder builder.Interface, buildConfig *latest.BuildConfig, summary *imageBuildSummary, log logpkg.Logger) error {
+	var (
+		timeout  time.Duration
+		attempts = 1
+		backoff  = defaultRetryBackoff
+	)
+	if buildConfig != nil {
+		timeout = time.Duration(buildConfig.Timeout) * time.Second
+		if buildConfig.Retry != nil {
+			if buildConfig.Retry.Attempts > 1 {
+				attempts = buildConfig.Retry.Attempts
+			}
+			if buildConfig.Retry.Backoff > 0 {
+				backoff = time.Duration(buildConfig.Retry.Backoff) * time.Second
+			}
+		}
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := buildWithTimeout(builder, timeout, log)
+		if err == nil {
+			summary.set(buildStatusBuilt, attempt, time.Since(start), nil)
+			return nil
+		} else if attempt >= attempts || !isRetryableError(err) {
+			summary.set(buildStatusFailed, attempt, time.Since(start), err)
+			return err
+		}
+
+		log.Warnf("Build attempt %d/%d of image '%s' failed: %v. Retrying in %s", attempt, attempts, imageConfigName, err, backoff)
+		sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// buildTimeoutError is returned if a build attempt took longer than the configured timeout
+type buildTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *buildTimeoutError) Error() string {
+	return "build timed out after " + e.timeout.String()
+}
+
+// buildWithTimeout runs a single build attempt. If the build takes longer than the timeout, it is canceled,
+// which kills the build command or deletes the build pod
+func buildWithTimeout(builder builder.Interface, timeout time.Duration, log logpkg.Logger) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := builder.Build(ctx, log)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return &buildTimeoutError{timeout: timeout}
+	}
+
+	return err
+}
+
+// isRetryableError checks if a failed build attempt should be retried. Only errors that indicate a network
+// or registry problem are retried. Timed out builds are never retried, because another attempt would most
+// likely time out as well
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var timeoutErr *buildTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, transientError := range transientErrors {
+		if strings.Contains(message, transientError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printBuildSummary prints the status and the amount of build attempts of every image, if at least one image was built
+func printBuildSummary(summaries map[string]*imageBuildSummary, log logpkg.Logger) {
+	imageConfigNames := []string{}
+	values := map[string][]string{}
+	built := false
+	for imageConfigName, summary := range summaries {
+		summary.m.Lock()
+		if summary.status == buildStatusBuilt || summary.status == buildStatusFailed {
+			built = true
+		}
+		values[imageConfigName] = []string{
+			imageConfigName,
+			summary.status,
+			strconv.Itoa(summary.attempts),
+			summary.duration.Round(time.Second).String(),
+		}
+		summary.m.Unlock()
+
+		imageConfigNames = append(imageConfigNames, imageConfigName)
+	}
+	if !built {
+		return
+	}
+
+	sort.Strings(imageConfigNames)
+	rows := [][]string{}
+	for _, imageConfigName := range imageConfigNames {
+		rows = append(rows, values[imageConfigName])
+	}
+
+	logpkg.PrintTable(log, []string{"Image", "Status", "Attempts", "Duration"}, rows)
+}
@@ -0,0 +1,166 @@
+package build
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+
+	"gotest.tools/assert"
+)
+
+type failingBuilder struct {
+	errors   []error
+	duration time.Duration
+	builds   int
+	canceled bool
+}
+
+func (b *failingBuilder) ShouldRebuild(cache *generated.CacheConfig, forceRebuild bool) (bool, error) {
+	return true, nil
+}
+
+func (b *failingBuilder) Build(ctx context.Context, log log.Logger) error {
+	b.builds++
+	select {
+	case <-time.After(b.duration):
+	case <-ctx.Done():
+		b.canceled = true
+		return ctx.Err()
+	}
+	if b.builds <= len(b.errors) {
+		return b.errors[b.builds-1]
+	}
+
+	return nil
+}
+
+type buildWithRetryTestCase struct {
+	name string
+
+	buildConfig *latest.BuildConfig
+	errors      []error
+	duration    time.Duration
+
+	expectedErr      string
+	expectedCanceled bool
+	expectedStatus   string
+	expectedAttempts int
+	expectedBackoffs []time.Duration
+}
+
+func TestBuildWithRetry(t *testing.T) {
+	networkErr := errors.New("error during image push: read tcp 10.0.0.1:443: connection reset by peer")
+	testCases := []buildWithRetryTestCase{
+		{
+			name:             "Build without retry",
+			expectedStatus:   buildStatusBuilt,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "Don't retry without retry config",
+			errors:           []error{networkErr},
+			expectedErr:      networkErr.Error(),
+			expectedStatus:   buildStatusFailed,
+			expectedAttempts: 1,
+		},
+		{
+			name: "Retry network errors with backoff",
+			buildConfig: &latest.BuildConfig{
+				Retry: &latest.BuildRetryConfig{
+					Attempts: 3,
+				},
+			},
+			errors:           []error{networkErr, networkErr},
+			expectedStatus:   buildStatusBuilt,
+			expectedAttempts: 3,
+			expectedBackoffs: []time.Duration{5 * time.Second, 10 * time.Second},
+		},
+		{
+			name: "Give up after the last attempt",
+			buildConfig: &latest.BuildConfig{
+				Retry: &latest.BuildRetryConfig{
+					Attempts: 2,
+					Backoff:  1,
+				},
+			},
+			errors:           []error{networkErr, networkErr},
+			expectedErr:      networkErr.Error(),
+			expectedStatus:   buildStatusFailed,
+			expectedAttempts: 2,
+			expectedBackoffs: []time.Duration{time.Second},
+		},
+		{
+			name: "Don't retry other errors",
+			buildConfig: &latest.BuildConfig{
+				Retry: &latest.BuildRetryConfig{
+					Attempts: 3,
+				},
+			},
+			errors:           []error{errors.New("dockerfile parse error line 3: unknown instruction: RUNN")},
+			expectedErr:      "dockerfile parse error line 3: unknown instruction: RUNN",
+			expectedStatus:   buildStatusFailed,
+			expectedAttempts: 1,
+		},
+		{
+			name: "Don't retry failed build commands",
+			buildConfig: &latest.BuildConfig{
+				Retry: &latest.BuildRetryConfig{
+					Attempts: 3,
+				},
+			},
+			errors:           []error{&exec.ExitError{ProcessState: &os.ProcessState{}}},
+			expectedErr:      "exit status 0",
+			expectedStatus:   buildStatusFailed,
+			expectedAttempts: 1,
+		},
+		{
+			name: "Don't retry timed out builds",
+			buildConfig: &latest.BuildConfig{
+				Timeout: 1,
+				Retry: &latest.BuildRetryConfig{
+					Attempts: 3,
+				},
+			},
+			duration:         time.Minute,
+			expectedErr:      "build timed out after 1s",
+			expectedCanceled: true,
+			expectedStatus:   buildStatusFailed,
+			expectedAttempts: 1,
+		},
+	}
+
+	defer func() { sleep = time.Sleep }()
+	for _, testCase := range testCases {
+		backoffs := []time.Duration{}
+		sleep = func(d time.Duration) {
+			backoffs = append(backoffs, d)
+		}
+
+		builder := &failingBuilder{
+			errors:   testCase.errors,
+			duration: testCase.duration,
+		}
+		summary := &imageBuildSummary{}
+		err := buildWithRetry("test", builder, testCase.buildConfig, summary, log.Discard)
+		if testCase.expectedErr == "" {
+			assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		} else {
+			assert.Error(t, err, testCase.expectedErr, "Wrong or no error in testCase %s", testCase.name)
+		}
+
+		assert.Equal(t, builder.canceled, testCase.expectedCanceled, "Wrong cancellation in testCase %s", testCase.name)
+		assert.Equal(t, summary.status, testCase.expectedStatus, "Wrong status in testCase %s", testCase.name)
+		assert.Equal(t, summary.attempts, testCase.expectedAttempts, "Wrong attempts in testCase %s", testCase.name)
+		assert.Equal(t, len(backoffs), len(testCase.expectedBackoffs), "Wrong amount of retries in testCase %s", testCase.name)
+		for i := range backoffs {
+			assert.Equal(t, backoffs[i], testCase.expectedBackoffs[i], "Wrong backoff in testCase %s", testCase.name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package build
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/signature"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// buildAndSign builds the image and signs it afterwards, if a private key is configured for the image
+func buildAndSign(imageConfigName string, builder builder.Interface, imageConf *latest.ImageConfig, tag string, summary *imageBuildSummary, log logpkg.Logger) error {
+	err := buildWithRetry(imageConfigName, builder, imageConf.Build, summary, log)
+	if err != nil {
+		return err
+	}
+
+	err = signImage(imageConf, builder, tag, log)
+	if err != nil {
+		summary.m.Lock()
+		summary.status = buildStatusFailed
+		summary.err = err
+		summary.m.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// signImage signs the pushed image with the private key of the image config and stores the signature in the registry
+func signImage(imageConf *latest.ImageConfig, builder builder.Interface, tag string, log logpkg.Logger) error {
+	if imageConf.Signature == nil || imageConf.Signature.PrivateKey == "" {
+		return nil
+	} else if !shouldPush(builder) {
+		log.Warnf("Skip signing image %s:%s, because it was not pushed to a registry", imageConf.Image, tag)
+		return nil
+	}
+
+	key, err := signature.LoadPrivateKey(imageConf.Signature.PrivateKey)
+	if err != nil {
+		return errors.Wrap(err, "load private key")
+	}
+
+	registryClient, err := newRegistryClient(imageConf.Image, log, "pull", "push")
+	if err != nil {
+		return errors.Wrapf(err, "create registry client for image %s", imageConf.Image)
+	}
+
+	log.StartWait("Signing image " + imageConf.Image + ":" + tag)
+	digest, err := signature.Sign(context.Background(), registryClient, imageConf.Image, tag, key)
+	log.StopWait()
+	if err != nil {
+		return errors.Wrapf(err, "sign image %s:%s", imageConf.Image, tag)
+	}
+
+	log.Donef("Signed image %s@%s", imageConf.Image, digest)
+	return nil
+}
@@ -45,6 +45,11 @@ type ImageCache struct {
 
 	ImageName string `yaml:"imageName,omitempty"`
 	Tag       string `yaml:"tag,omitempty"`
+
+	// VerifiedTag and VerifiedDigest are the tag and digest of the image whose signature was verified before
+	// deploying. They are only kept in memory, so the deployments reference exactly the verified image
+	VerifiedTag    string `yaml:"-"`
+	VerifiedDigest string `yaml:"-"`
 }
 
 // DeploymentCache holds the information about a specific deployment
@@ -55,5 +60,18 @@ type DeploymentCache struct {
 	HelmChartHash       string `yaml:"helmChartHash,omitempty"`
 	HelmReleaseRevision string `yaml:"helmReleaseRevision,omitempty"`
 
-	KubectlManifestsHash string `yaml:"kubectlManifestsHash,omitempty"`
+	KubectlManifestsHash string           `yaml:"kubectlManifestsHash,omitempty"`
+	KubectlObjects       []*KubectlObject `yaml:"kubectlObjects,omitempty"`
+
+	// KubectlRollbackManifests are the manifests of the last successful deployment of an atomic kubectl deployment.
+	// Secrets are not stored, because this config is written to disk as plain text
+	KubectlRollbackManifests []string `yaml:"kubectlRollbackManifests,omitempty"`
+}
+
+// KubectlObject identifies an object that was applied by a kubectl deployment
+type KubectlObject struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Namespace  string `yaml:"namespace,omitempty"`
+	Name       string `yaml:"name"`
 }
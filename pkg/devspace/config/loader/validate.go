@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	jsonyaml "github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/helper/util/modemask"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm/merge"
 	"github.com/loft-sh/devspace/pkg/util/log"
@@ -13,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // ValidInitialSyncStrategy checks if strategy is valid
@@ -26,6 +28,22 @@ func ValidInitialSyncStrategy(strategy latest.InitialSyncStrategy) bool {
 		strategy == latest.InitialSyncStrategyPreferNewest
 }
 
+// ValidConflictStrategy checks if the conflict strategy is valid
+func ValidConflictStrategy(strategy latest.ConflictStrategy) bool {
+	return strategy == "" ||
+		strategy == latest.ConflictStrategyPreferLocal ||
+		strategy == latest.ConflictStrategyPreferRemote ||
+		strategy == latest.ConflictStrategyKeepBoth ||
+		strategy == latest.ConflictStrategyAsk
+}
+
+// ValidSyncTransport checks if the sync transport is valid
+func ValidSyncTransport(transport latest.SyncTransport) bool {
+	return transport == "" ||
+		transport == latest.SyncTransportExec ||
+		transport == latest.SyncTransportPortForward
+}
+
 // ValidContainerArch checks if the target container arch is valid
 func ValidContainerArch(arch latest.ContainerArchitecture) bool {
 	return arch == "" ||
@@ -230,6 +248,15 @@ func validateDeployments(config *latest.Config) error {
 		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Manifests == nil {
 			return errors.Errorf("deployments[%d].kubectl.manifests is required", index)
 		}
+		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Native != nil && *deployConfig.Kubectl.Native {
+			kubectlConfig := deployConfig.Kubectl
+			if kubectlConfig.CmdPath != "" || len(kubectlConfig.ApplyArgs) > 0 || len(kubectlConfig.CreateArgs) > 0 || len(kubectlConfig.DeleteArgs) > 0 || len(kubectlConfig.KustomizeArgs) > 0 {
+				return errors.Errorf("deployments[%d].kubectl.cmdPath, applyArgs, createArgs, deleteArgs and kustomizeArgs cannot be used with deployments[%d].kubectl.native", index, index)
+			}
+		}
+		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Timeout != nil && *deployConfig.Kubectl.Timeout <= 0 {
+			return errors.Errorf("deployments[%d].kubectl.timeout must be greater than 0", index)
+		}
 		if deployConfig.Helm != nil && deployConfig.Helm.ComponentChart != nil && *deployConfig.Helm.ComponentChart {
 			// Load override values from path
 			overwriteValues := map[interface{}]interface{}{}
@@ -264,6 +291,22 @@ func validateDeployments(config *latest.Config) error {
 				return errors.Errorf("deployments[%d].helm.componentChart: component values are incorrect: %v", index, err)
 			}
 		}
+		for _, dependency := range deployConfig.DependsOn {
+			if dependency == deployConfig.Name {
+				return errors.Errorf("deployments[%d].dependsOn cannot reference the deployment itself", index)
+			}
+
+			found := false
+			for _, otherConfig := range config.Deployments {
+				if otherConfig.Name == dependency {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.Errorf("deployments[%d].dependsOn references unknown deployment %s", index, dependency)
+			}
+		}
 	}
 
 	return nil
@@ -280,8 +323,15 @@ func validatePullSecrets(config *latest.Config) error {
 }
 
 func validateImages(config *latest.Config) error {
+	if config.BuildSettings != nil && config.BuildSettings.MaxConcurrent < 0 {
+		return errors.Errorf("buildSettings.maxConcurrent cannot be negative")
+	}
+
 	// images lists all the image names in order to check for duplicates
 	images := map[string]bool{}
+
+	// cacheVolumes maps the configured kaniko cache volume names to the images that use them
+	cacheVolumes := map[string]string{}
 	for imageConfigName, imageConf := range config.Images {
 		if imageConfigName == "" {
 			return errors.Errorf("images keys cannot be an empty string")
@@ -314,6 +364,73 @@ func validateImages(config *latest.Config) error {
 				}
 			}
 		}
+		if imageConf.Build != nil && imageConf.Build.Kaniko != nil && imageConf.Build.Kaniko.CacheVolume != nil && imageConf.Build.Kaniko.CacheVolume.Size != "" {
+			_, err := resource.ParseQuantity(imageConf.Build.Kaniko.CacheVolume.Size)
+			if err != nil {
+				return errors.Errorf("images.%s.build.kaniko.cacheVolume.size is invalid: %v", imageConfigName, err)
+			}
+		}
+		if imageConf.Build != nil && imageConf.Build.Kaniko != nil && imageConf.Build.Kaniko.CacheVolume != nil && imageConf.Build.Kaniko.CacheVolume.Name != "" {
+			name := imageConf.Build.Kaniko.Namespace + "/" + imageConf.Build.Kaniko.CacheVolume.Name
+			if other, ok := cacheVolumes[name]; ok {
+				first, second := other, imageConfigName
+				if second < first {
+					first, second = second, first
+				}
+
+				return errors.Errorf("images.%s and images.%s use the same kaniko cache volume %s, but the volume can only be mounted by the build pods on a single node at a time. Please remove images.*.build.kaniko.cacheVolume.name to use a cache volume per image", first, second, imageConf.Build.Kaniko.CacheVolume.Name)
+			}
+
+			cacheVolumes[name] = imageConfigName
+		}
+		for _, dependency := range imageConf.DependsOn {
+			if dependency == imageConfigName {
+				return errors.Errorf("images.%s.dependsOn cannot reference the image itself", imageConfigName)
+			} else if config.Images[dependency] == nil {
+				return errors.Errorf("images.%s.dependsOn references unknown image %s", imageConfigName, dependency)
+			}
+		}
+		if imageConf.RemoteCache && imageConf.Build != nil && imageConf.Build.Custom != nil {
+			return errors.Errorf("images.%s.remoteCache is not supported for custom builds", imageConfigName)
+		}
+		for _, platform := range imageConf.Platforms {
+			parts := strings.Split(platform, "/")
+			if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" || (len(parts) == 3 && parts[2] == "") {
+				return errors.Errorf("images.%s.platforms: invalid platform %s, expected os/arch[/variant] (e.g. linux/amd64)", imageConfigName, platform)
+			}
+		}
+		if len(imageConf.Platforms) > 0 && imageConf.Build != nil && imageConf.Build.Custom != nil {
+			return errors.Errorf("images.%s.platforms is not supported for custom builds", imageConfigName)
+		}
+		if len(imageConf.Platforms) > 0 && imageConf.Build != nil && imageConf.Build.Docker == nil && imageConf.Build.BuildKit == nil && imageConf.Build.Kaniko != nil {
+			return errors.Errorf("images.%s.platforms is not supported for kaniko builds, please use docker or buildKit instead", imageConfigName)
+		}
+		if imageConf.Build != nil && imageConf.Build.GoBuild != nil {
+			if len(imageConf.Platforms) > 0 {
+				return errors.Errorf("images.%s.platforms is not supported for goBuild builds, please use images.%s.build.goBuild.goos and images.%s.build.goBuild.goarch instead", imageConfigName, imageConfigName, imageConfigName)
+			}
+			if imageConf.InjectRestartHelper {
+				return errors.Errorf("images.%s.injectRestartHelper is not supported for goBuild builds", imageConfigName)
+			}
+			if len(imageConf.AppendDockerfileInstructions) > 0 {
+				return errors.Errorf("images.%s.appendDockerfileInstructions is not supported for goBuild builds", imageConfigName)
+			}
+			if imageConf.Build.GoBuild.SkipPush && imageConf.Build.GoBuild.OCILayout == "" {
+				return errors.Errorf("images.%s.build.goBuild.skipPush requires images.%s.build.goBuild.ociLayout, because there is no docker daemon the image could be loaded into", imageConfigName, imageConfigName)
+			}
+		}
+		if imageConf.Build != nil && imageConf.Build.Timeout < 0 {
+			return errors.Errorf("images.%s.build.timeout cannot be negative", imageConfigName)
+		}
+		if imageConf.Build != nil && imageConf.Build.Retry != nil && (imageConf.Build.Retry.Attempts < 0 || imageConf.Build.Retry.Backoff < 0) {
+			return errors.Errorf("images.%s.build.retry.attempts and images.%s.build.retry.backoff cannot be negative", imageConfigName, imageConfigName)
+		}
+		if imageConf.Signature != nil && imageConf.Signature.PrivateKey == "" && imageConf.Signature.PublicKey == "" {
+			return errors.Errorf("images.%s.signature needs either a privateKey or a publicKey", imageConfigName)
+		}
+		if imageConf.Signature != nil && imageConf.Signature.PrivateKey != "" && imageConf.Build != nil && imageConf.Build.GoBuild != nil && imageConf.Build.GoBuild.OCILayout != "" {
+			return errors.Errorf("images.%s.signature.privateKey cannot be used with images.%s.build.goBuild.ociLayout, because only images in a registry can be signed", imageConfigName, imageConfigName)
+		}
 		images[imageConf.Image] = true
 	}
 
@@ -397,9 +514,31 @@ func validateDev(config *latest.Config) error {
 			if !ValidInitialSyncStrategy(sync.InitialSync) {
 				return errors.Errorf("Error in config: sync.initialSync is not valid '%s' at index %d", sync.InitialSync, index)
 			}
+			if !ValidConflictStrategy(sync.ConflictStrategy) {
+				return errors.Errorf("Error in config: sync.conflictStrategy is not valid '%s' at index %d", sync.ConflictStrategy, index)
+			}
+			if !ValidSyncTransport(sync.Transport) {
+				return errors.Errorf("Error in config: sync.transport is not valid '%s' at index %d", sync.Transport, index)
+			}
+			if sync.TransportPort != nil && (*sync.TransportPort <= 0 || *sync.TransportPort > 65535) {
+				return errors.Errorf("Error in config: sync.transportPort is not valid '%d' at index %d", *sync.TransportPort, index)
+			}
 			if !ValidContainerArch(sync.Arch) {
 				return errors.Errorf("Error in config: sync.arch is not valid '%s' at index %d", sync.Arch, index)
 			}
+			if sync.Permissions != nil {
+				for maskIndex, mask := range sync.Permissions.ModeMasks {
+					if mask.Path == "" {
+						return errors.Errorf("Error in config: sync.permissions.modeMasks[%d].path is empty at index %d", maskIndex, index)
+					}
+					if _, err := modemask.ParseMode(mask.Set); err != nil {
+						return errors.Errorf("Error in config: sync.permissions.modeMasks[%d].set at index %d: %v", maskIndex, index, err)
+					}
+					if _, err := modemask.ParseMode(mask.Clear); err != nil {
+						return errors.Errorf("Error in config: sync.permissions.modeMasks[%d].clear at index %d: %v", maskIndex, index, err)
+					}
+				}
+			}
 		}
 	}
 
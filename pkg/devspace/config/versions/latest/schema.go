@@ -44,6 +44,9 @@ type Config struct {
 	// Images holds configuration of how devspace should build images
 	Images map[string]*ImageConfig `yaml:"images,omitempty" json:"images,omitempty"`
 
+	// BuildSettings holds settings that apply to the builds of all images
+	BuildSettings *BuildSettingsConfig `yaml:"buildSettings,omitempty" json:"buildSettings,omitempty"`
+
 	// Deployments is an ordered list of deployments to deploy via helm, kustomize or kubectl.
 	Deployments []*DeploymentConfig `yaml:"deployments,omitempty" json:"deployments,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
 
@@ -147,10 +150,40 @@ type ImageConfig struct {
 	// This option is ignored for custom builds.
 	RebuildStrategy RebuildStrategy `yaml:"rebuildStrategy,omitempty" json:"rebuildStrategy,omitempty"`
 
+	// DependsOn are the names of other images that have to be built before this image. Images
+	// that are used in a FROM instruction of the dockerfile are detected automatically and
+	// are replaced with the freshly built image
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
+
+	// RemoteCache tags the image with a hash of the dockerfile, the context and the image config.
+	// Before building, DevSpace checks if an image with this tag already exists in the registry and
+	// reuses it instead of building the image again. This option is not supported for custom builds.
+	RemoteCache bool `yaml:"remoteCache,omitempty" json:"remoteCache,omitempty"`
+
+	// Platforms are the platforms the image should be built for, e.g. linux/amd64 or linux/arm64.
+	// If more than one platform is specified, a manifest list is pushed. This option is only
+	// supported for docker and buildKit builds.
+	Platforms []string `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+
+	// Signature signs the image after it was pushed and verifies the signature before the image is deployed
+	Signature *SignatureConfig `yaml:"signature,omitempty" json:"signature,omitempty"`
+
 	// Specific build options how to build the specified image
 	Build *BuildConfig `yaml:"build,omitempty" json:"build,omitempty"`
 }
 
+// SignatureConfig defines the keys that are used to sign and verify an image. The signature is stored
+// as an artifact next to the image in the registry
+type SignatureConfig struct {
+	// PrivateKey is the path to a pem encoded private key. If specified, DevSpace signs the image
+	// after it was built and pushed
+	PrivateKey string `yaml:"privateKey,omitempty" json:"privateKey,omitempty"`
+
+	// PublicKey is the path to a pem encoded public key. If specified, DevSpace verifies the signature
+	// of the image before it is deployed and fails the deployment if the signature is invalid
+	PublicKey string `yaml:"publicKey,omitempty" json:"publicKey,omitempty"`
+}
+
 // RebuildStrategy is the type of a image rebuild strategy
 type RebuildStrategy string
 
@@ -177,9 +210,69 @@ type BuildConfig struct {
 	// a custom script.
 	Custom *CustomConfig `yaml:"custom,omitempty" json:"custom,omitempty"`
 
+	// If goBuild is specified, DevSpace will compile a go binary locally and append it as a new layer
+	// to a base image without using a docker daemon
+	GoBuild *GoBuildConfig `yaml:"goBuild,omitempty" json:"goBuild,omitempty"`
+
 	// This overrides other options and is able to disable the build for this image.
 	// Useful if you just want to select the image in a sync path or via devspace enter --image
 	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+
+	// Timeout is the amount of seconds a single build attempt of the image may take. Defaults to no timeout
+	Timeout int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Retry defines how often a build that failed because of a network or registry error is retried
+	Retry *BuildRetryConfig `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// BuildRetryConfig defines how failed builds of an image are retried
+type BuildRetryConfig struct {
+	// Attempts is the maximum amount of build attempts including the first one
+	Attempts int `yaml:"attempts,omitempty" json:"attempts,omitempty"`
+
+	// Backoff is the amount of seconds to wait before the first retry. The wait time is doubled
+	// after every retry. Defaults to 5 seconds
+	Backoff int64 `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+}
+
+// BuildSettingsConfig defines settings that apply to the builds of all images
+type BuildSettingsConfig struct {
+	// MaxConcurrent is the maximum number of images that are built in parallel. Defaults to no limit and
+	// is overridden by the --max-concurrent-builds flag
+	MaxConcurrent int `yaml:"maxConcurrent,omitempty" json:"maxConcurrent,omitempty"`
+}
+
+// GoBuildConfig tells the DevSpace CLI to build the image by compiling a go binary and appending it to a base image
+type GoBuildConfig struct {
+	// Package is the main package that is compiled, relative to the context. Defaults to .
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+
+	// BaseImage is the image the binary is added to. Defaults to gcr.io/distroless/static:nonroot
+	BaseImage string `yaml:"baseImage,omitempty" json:"baseImage,omitempty"`
+
+	// GOOS and GOARCH are the operating system and architecture the binary and the image are built for.
+	// Defaults to linux and amd64
+	GOOS   string `yaml:"goos,omitempty" json:"goos,omitempty"`
+	GOARCH string `yaml:"goarch,omitempty" json:"goarch,omitempty"`
+
+	// GOARM is the arm version the binary is built for if GOARCH is arm, which also selects the variant
+	// of the base image. Defaults to 7
+	GOARM string `yaml:"goarm,omitempty" json:"goarm,omitempty"`
+
+	// Flags are additional flags for go build, e.g. -ldflags or -tags
+	Flags []string `yaml:"flags,omitempty" json:"flags,omitempty"`
+
+	// Env are additional environment variables for go build. CGO_ENABLED defaults to 0
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// BinaryPath is the path of the binary in the image, which is also used as entrypoint. Defaults to /app
+	BinaryPath string `yaml:"binaryPath,omitempty" json:"binaryPath,omitempty"`
+
+	// OCILayout is a directory the image is written to as OCI image layout instead of pushing it to the registry
+	OCILayout string `yaml:"ociLayout,omitempty" json:"ociLayout,omitempty"`
+
+	// SkipPush skips pushing the image to the registry
+	SkipPush bool `yaml:"skipPush,omitempty" json:"skipPush,omitempty"`
 }
 
 // DockerConfig tells the DevSpace CLI to build with Docker on Minikube or on localhost
@@ -311,6 +404,10 @@ type KanikoConfig struct {
 	// additional mounts that will be added to the build pod
 	AdditionalMounts []KanikoAdditionalMount `yaml:"additionalMounts,omitempty" json:"additionalMounts,omitempty"`
 
+	// CacheVolume mounts a persistent volume claim into the build pod that caches the base images
+	// of the dockerfile across builds. DevSpace creates the claim in the build namespace if it does not exist
+	CacheVolume *KanikoCacheVolume `yaml:"cacheVolume,omitempty" json:"cacheVolume,omitempty"`
+
 	// the resources that should be set on the kaniko pod
 	Resources *KanikoPodResources `yaml:"resources,omitempty" json:"resources,omitempty"`
 
@@ -318,6 +415,19 @@ type KanikoConfig struct {
 	Options *BuildOptions `yaml:"options,omitempty" json:"options,omitempty"`
 }
 
+// KanikoCacheVolume describes the persistent volume claim that is used as kaniko cache
+type KanikoCacheVolume struct {
+	// Name of the persistent volume claim. Defaults to devspace-kaniko-cache-IMAGE_KEY
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Size of the persistent volume claim if it is created. Defaults to 10Gi
+	Size string `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// StorageClassName of the persistent volume claim if it is created. Defaults to the
+	// default storage class of the cluster
+	StorageClassName string `yaml:"storageClassName,omitempty" json:"storageClassName,omitempty"`
+}
+
 // KanikoPodResources describes the resources section of the started kaniko pod
 type KanikoPodResources struct {
 	// The requests part of the resources
@@ -450,6 +560,10 @@ type DeploymentConfig struct {
 	Namespace string         `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 	Helm      *HelmConfig    `yaml:"helm,omitempty" json:"helm,omitempty"`
 	Kubectl   *KubectlConfig `yaml:"kubectl,omitempty" json:"kubectl,omitempty"`
+
+	// DependsOn are the names of other deployments that have to be deployed before this
+	// deployment. Deployments that do not depend on each other are deployed in parallel
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
 }
 
 // ComponentConfig holds the component information
@@ -673,6 +787,18 @@ type KubectlConfig struct {
 	CreateArgs       []string `yaml:"createArgs,omitempty" json:"createArgs,omitempty"`
 	ApplyArgs        []string `yaml:"applyArgs,omitempty" json:"applyArgs,omitempty"`
 	CmdPath          string   `yaml:"cmdPath,omitempty" json:"cmdPath,omitempty"`
+
+	// Native renders and applies the manifests in-process with server side apply instead of
+	// calling the kubectl and kustomize binaries
+	Native *bool `yaml:"native,omitempty" json:"native,omitempty"`
+
+	// Wait waits until the applied deployments, stateful sets, daemon sets and jobs are ready
+	Wait bool `yaml:"wait,omitempty" json:"wait,omitempty"`
+	// Timeout is the amount of seconds to wait for the applied objects to become ready. Defaults to 300
+	Timeout *int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Atomic waits for the applied objects and re-applies the manifests of the last successful deployment
+	// if they do not become ready
+	Atomic bool `yaml:"atomic,omitempty" json:"atomic,omitempty"`
 }
 
 // DevConfig defines the devspace deployment
@@ -756,6 +882,28 @@ type SyncConfig struct {
 
 	Polling bool `yaml:"polling,omitempty" json:"polling,omitempty"`
 
+	// If true, changed files that already exist in the container will be uploaded as delta and
+	// only the changed blocks are transferred
+	DeltaTransfer bool `yaml:"deltaTransfer,omitempty" json:"deltaTransfer,omitempty"`
+
+	// If true, the sync state is persisted in the .devspace folder and a restarted sync to the
+	// same container only reconciles the changes since the last sync instead of a full initial sync
+	PersistState bool `yaml:"persistState,omitempty" json:"persistState,omitempty"`
+
+	// ConflictStrategy defines how files are handled that were changed locally and in the container
+	// since they were last in sync. Defaults to preferLocal
+	ConflictStrategy ConflictStrategy `yaml:"conflictStrategy,omitempty" json:"conflictStrategy,omitempty"`
+
+	// Permissions defines how file modes and ownership are handled by the sync
+	Permissions *SyncPermissions `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+
+	// Transport defines how DevSpace connects to the sync helper in the container. Defaults to exec
+	Transport SyncTransport `yaml:"transport,omitempty" json:"transport,omitempty"`
+
+	// TransportPort is the loopback port of the container the sync helper listens on if the portForward transport is used.
+	// The index of the container in the pod is added to the port, because all containers of a pod share the network namespace
+	TransportPort *int `yaml:"transportPort,omitempty" json:"transportPort,omitempty"`
+
 	WaitInitialSync *bool            `yaml:"waitInitialSync,omitempty" json:"waitInitialSync,omitempty"`
 	BandwidthLimits *BandwidthLimits `yaml:"bandwidthLimits,omitempty" json:"bandwidthLimits,omitempty"`
 
@@ -769,6 +917,32 @@ type SyncConfig struct {
 	OnDownload *SyncOnDownload `yaml:"onDownload,omitempty" json:"onDownload,omitempty"`
 }
 
+// SyncPermissions defines how file modes and ownership are synced
+type SyncPermissions struct {
+	// If true, the full permission bits of files and folders are synced in both directions,
+	// also if the file or folder already exists on the other side
+	PreserveModes bool `yaml:"preserveModes,omitempty" json:"preserveModes,omitempty"`
+
+	// UID and GID force the owner of uploaded files and folders in the container
+	UID *int64 `yaml:"uid,omitempty" json:"uid,omitempty"`
+	GID *int64 `yaml:"gid,omitempty" json:"gid,omitempty"`
+
+	// ModeMasks are applied in order to the modes of synced files and folders that match the path
+	ModeMasks []*SyncModeMask `yaml:"modeMasks,omitempty" json:"modeMasks,omitempty"`
+}
+
+// SyncModeMask sets and clears permission bits of the synced files and folders that match the path
+type SyncModeMask struct {
+	// Path is a gitignore style pattern, e.g. *.sh
+	Path string `yaml:"path" json:"path"`
+
+	// Set are the octal permission bits that should be set, e.g. 0111
+	Set string `yaml:"set,omitempty" json:"set,omitempty"`
+
+	// Clear are the octal permission bits that should be cleared, e.g. 0022
+	Clear string `yaml:"clear,omitempty" json:"clear,omitempty"`
+}
+
 type ContainerArchitecture string
 
 const (
@@ -836,8 +1010,29 @@ type InitialSyncCompareBy string
 
 // List of values that compare by can take
 const (
-	InitialSyncCompareByMTime InitialSyncCompareBy = "mtime"
-	InitialSyncCompareBySize  InitialSyncCompareBy = "size"
+	InitialSyncCompareByMTime    InitialSyncCompareBy = "mtime"
+	InitialSyncCompareBySize     InitialSyncCompareBy = "size"
+	InitialSyncCompareByChecksum InitialSyncCompareBy = "checksum"
+)
+
+// ConflictStrategy is the type of how a sync conflict should be resolved
+type ConflictStrategy string
+
+// List of values that conflict strategy can take
+const (
+	ConflictStrategyPreferLocal  ConflictStrategy = "preferLocal"
+	ConflictStrategyPreferRemote ConflictStrategy = "preferRemote"
+	ConflictStrategyKeepBoth     ConflictStrategy = "keepBoth"
+	ConflictStrategyAsk          ConflictStrategy = "ask"
+)
+
+// SyncTransport is the type of how DevSpace connects to the sync helper
+type SyncTransport string
+
+// List of values that sync transport can take
+const (
+	SyncTransportExec        SyncTransport = "exec"
+	SyncTransportPortForward SyncTransport = "portForward"
 )
 
 // BandwidthLimits defines the struct for specifying the sync bandwidth limits
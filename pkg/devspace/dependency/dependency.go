@@ -488,6 +488,12 @@ func (d *Dependency) Deploy(forceDependencies, skipBuild, skipDeploy, forceDeplo
 			BuiltImages: builtImages,
 		}, log)
 		if err != nil {
+			// save the generated config anyways, so the objects of a failed deployment are pruned by the next deployment
+			saveErr := d.generatedSaver.Save(d.localConfig.Generated())
+			if saveErr != nil {
+				log.Warnf("Error saving generated config: %v", saveErr)
+			}
+
 			return err
 		}
 	}
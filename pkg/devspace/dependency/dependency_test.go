@@ -20,6 +20,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
 	"github.com/loft-sh/devspace/pkg/util/hash"
 	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
 
 	"gotest.tools/assert"
 )
@@ -699,3 +700,69 @@ func TestDeploy(t *testing.T) {
 		}
 	}
 }
+
+func TestDeploySavesGeneratedConfigOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testFolder")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wdBackup, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error getting current working directory: %v", err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatalf("Error changing working directory: %v", err)
+	}
+
+	// Delete temp folder
+	defer func() {
+		err = os.Chdir(wdBackup)
+		if err != nil {
+			t.Fatalf("Error changing dir back: %v", err)
+		}
+		err = os.RemoveAll(dir)
+		if err != nil {
+			t.Fatalf("Error removing dir: %v", err)
+		}
+	}()
+
+	// the failed deployment keeps the applied objects in the inventory, so they are pruned by the next deployment
+	objects := []*generated.KubectlObject{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "test", Name: "new-config"},
+	}
+	cache := generated.New()
+	cache.GetActive().GetDeploymentCache("backend").KubectlObjects = objects
+
+	dependency := &Dependency{
+		localPath:        "./",
+		dependencyConfig: &latest.DependencyConfig{},
+		dependencyCache: &generated.Config{
+			Profiles: map[string]*generated.CacheConfig{
+				"": {
+					Dependencies: map[string]string{
+						"": "",
+					},
+				},
+			},
+		},
+		localConfig:      config.NewConfig(nil, &latest.Config{}, cache, nil, constants.DefaultConfigPath),
+		kubeClient:       &fakekube.Client{},
+		registryClient:   &fakeregistry.Client{},
+		buildController:  &fakebuild.FakeController{},
+		deployController: &fakedeploy.FakeController{Err: errors.New("rollout failed")},
+		generatedSaver:   generated.NewConfigLoaderFromDevSpacePath("", constants.DefaultConfigPath),
+	}
+
+	err = dependency.Deploy(true, false, false, false, &build.Options{}, log.Discard)
+	assert.Error(t, err, "rollout failed")
+
+	saved, err := generated.NewConfigLoaderFromDevSpacePath("", filepath.Join(dir, constants.DefaultConfigPath)).Load()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, saved.GetActive().Deployments["backend"].KubectlObjects, objects)
+}
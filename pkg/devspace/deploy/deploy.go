@@ -26,12 +26,21 @@ type Options struct {
 	ForceDeploy bool
 	BuiltImages map[string]string
 	Deployments []string
+
+	// Parallel deploys the deployments that do not depend on each other in parallel. Deployments are
+	// also deployed in parallel if any deployment declares dependsOn
+	Parallel bool
+	// MaxConcurrentDeployments limits the amount of deployments that are deployed in parallel (0 for infinite)
+	MaxConcurrentDeployments int
+	// NoPrune keeps the objects that were removed from the manifests of kubectl deployments
+	NoPrune bool
 }
 
 // Controller is the main deploying interface
 type Controller interface {
 	Deploy(options *Options, log log.Logger) error
 	Render(options *Options, out io.Writer, log log.Logger) error
+	Diff(options *Options, out io.Writer, log log.Logger) error
 	Purge(deployments []string, log log.Logger) error
 }
 
@@ -163,99 +172,64 @@ func (c *controller) Deploy(options *Options, log log.Logger) error {
 			return err
 		}
 
-		for _, deployConfig := range config.Deployments {
-			if len(options.Deployments) > 0 {
-				shouldSkip := true
-
-				for _, deployment := range options.Deployments {
-					if deployment == strings.TrimSpace(deployConfig.Name) {
-						shouldSkip = false
-						break
-					}
-				}
+		// Only deploy images that are signed with the configured keys
+		err = c.verifyImages(log)
+		if err != nil {
+			return err
+		}
 
-				if shouldSkip {
-					continue
-				}
-			}
+		// Resolve the order in which the deployments have to be deployed
+		graph, err := newDeploymentGraph(filterDeployments(config.Deployments, options.Deployments))
+		if err != nil {
+			return err
+		}
 
-			var (
-				deployClient deployer.Interface
-				err          error
-				method       string
-			)
+		// Deploy in the order of the config, unless parallel deployments were requested or the config
+		// declares the order with dependsOn. Deploy not in parallel when we only have one deployment
+		parallel := options.Parallel || declaresDependsOn(config.Deployments)
+		sequential := !parallel || len(graph.order) <= 1 || options.MaxConcurrentDeployments == 1
+		state := &deployState{
+			graph:    graph,
+			finished: map[string]bool{},
+			errChan:  make(chan error, len(graph.order)),
+			doneChan: make(chan string, len(graph.order)),
+		}
 
-			if deployConfig.Kubectl != nil {
-				deployClient, err = kubectl.New(c.config, c.dependencies, c.client, deployConfig, log)
-				if err != nil {
-					return errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		// Deploy the deployments as soon as the deployments they depend on are deployed. If a deployment
+		// fails, no new deployments are started, but the running deployments are awaited
+		var deployErr error
+		pending := append([]string{}, graph.order...)
+		for (deployErr == nil && len(pending) > 0) || state.running > 0 {
+			for i := 0; deployErr == nil && i < len(pending); {
+				if options.MaxConcurrentDeployments > 0 && state.running >= options.MaxConcurrentDeployments {
+					break
+				} else if !graph.ready(pending[i], state.finished) {
+					i++
+					continue
 				}
 
-				method = "kubectl"
-			} else if deployConfig.Helm != nil {
-				// Get helm client
-				helmClient, err := GetCachedHelmClient(c.config.Config(), deployConfig, c.client, helmV2Clients, false, log)
+				deployConfig, err := getDeployConfig(config.Deployments, pending[i])
 				if err != nil {
 					return err
 				}
 
-				deployClient, err = helm.New(c.config, c.dependencies, helmClient, c.client, deployConfig, log)
-				if err != nil {
-					return errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
-				}
-
-				method = "helm"
-			} else {
-				return errors.Errorf("error deploying: deployment %s has no deployment method", deployConfig.Name)
-			}
-
-			// Execute before deployment deploy hook
-			err = hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-				"DEPLOY_NAME":   deployConfig.Name,
-				"DEPLOY_CONFIG": deployConfig,
-			}, log, hook.EventsForSingle("before:deploy", deployConfig.Name).With("deploy.beforeDeploy")...)
-			if err != nil {
-				return err
-			}
-
-			wasDeployed, err := deployClient.Deploy(options.ForceDeploy, options.BuiltImages)
-			if err != nil {
-				hookErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-					"DEPLOY_NAME":   deployConfig.Name,
-					"DEPLOY_CONFIG": deployConfig,
-					"ERROR":         err,
-				}, log, hook.EventsForSingle("error:deploy", deployConfig.Name).With("deploy.errorDeploy")...)
-				if hookErr != nil {
-					return hookErr
-				}
-
-				return errors.Errorf("error deploying %s: %v", deployConfig.Name, err)
+				pending = append(pending[:i], pending[i+1:]...)
+				deployErr = c.startDeployment(deployConfig, state, helmV2Clients, sequential, options, log)
 			}
 
-			if wasDeployed {
-				log.Donef("Successfully deployed %s with %s", deployConfig.Name, method)
-
-				// Execute after deployment deploy hook
-				err = hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-					"DEPLOY_NAME":   deployConfig.Name,
-					"DEPLOY_CONFIG": deployConfig,
-				}, log, hook.EventsForSingle("after:deploy", deployConfig.Name).With("deploy.afterDeploy")...)
-				if err != nil {
-					return err
-				}
-			} else {
-				log.Infof("Skipping deployment %s", deployConfig.Name)
-
-				// Execute after deployment deploy hook
-				err = hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
-					"DEPLOY_NAME":   deployConfig.Name,
-					"DEPLOY_CONFIG": deployConfig,
-				}, log, hook.EventsForSingle("skip:deploy", deployConfig.Name)...)
-				if err != nil {
-					return err
+			// wait for the parallel deployments
+			if state.running > 0 {
+				err := c.waitForDeployment(state)
+				if err != nil && deployErr == nil {
+					deployErr = err
 				}
+			} else if deployErr == nil && len(pending) > 0 {
+				return errors.Errorf("cannot deploy %s, because the deployments they depend on were not deployed", strings.Join(pending, ", "))
 			}
 		}
+		if deployErr != nil {
+			return deployErr
+		}
 
 		// Execute after deployments deploy hook
 		err = hook.ExecuteHooks(c.client, c.config, c.dependencies, nil, log, "after:deploy")
@@ -283,13 +257,18 @@ func (c *controller) Purge(deployments []string, log log.Logger) error {
 			return err
 		}
 
-		// Reverse them
-		for i := len(config.Deployments) - 1; i >= 0; i-- {
-			var (
-				err          error
-				deployClient deployer.Interface
-				deployConfig = config.Deployments[i]
-			)
+		// Purge the deployments before the deployments they depend on
+		graph, err := newDeploymentGraph(config.Deployments)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range graph.reverseOrder() {
+			var deployClient deployer.Interface
+			deployConfig, err := getDeployConfig(config.Deployments, name)
+			if err != nil {
+				return err
+			}
 
 			// Check if we should skip deleting deployment
 			if deployments != nil {
@@ -2,7 +2,11 @@ package kubectl
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -11,6 +15,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
 )
 
 // Builder is the manifest builder interface
@@ -105,6 +111,97 @@ func (k *kubectlBuilder) Build(manifest string, cmd RunCommand) ([]*unstructured
 	return stringToUnstructuredArray(string(output))
 }
 
+type nativeBuilder struct {
+	config *latest.DeploymentConfig
+}
+
+// NewNativeBuilder creates a new manifest builder that reads the manifests and builds the kustomizations
+// without calling the kubectl or kustomize binaries
+func NewNativeBuilder(config *latest.DeploymentConfig) Builder {
+	return &nativeBuilder{
+		config: config,
+	}
+}
+
+func (n *nativeBuilder) Build(manifest string, _ RunCommand) ([]*unstructured.Unstructured, error) {
+	if n.config.Kubectl.Kustomize != nil && *n.config.Kubectl.Kustomize {
+		resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "build kustomization %s", manifest)
+		}
+
+		output, err := resMap.AsYaml()
+		if err != nil {
+			return nil, errors.Wrapf(err, "build kustomization %s", manifest)
+		}
+
+		return stringToUnstructuredArray(string(output))
+	}
+
+	output, err := readManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return stringToUnstructuredArray(output)
+}
+
+// readManifests reads the manifests from an url, a file or the yaml and json files within a directory
+func readManifests(manifest string) (string, error) {
+	if strings.HasPrefix(manifest, "http://") || strings.HasPrefix(manifest, "https://") {
+		resp, err := http.Get(manifest)
+		if err != nil {
+			return "", errors.Wrapf(err, "get %s", manifest)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", errors.Errorf("get %s: unexpected status code %d", manifest, resp.StatusCode)
+		}
+
+		out, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", errors.Wrapf(err, "read %s", manifest)
+		}
+
+		return string(out), nil
+	}
+
+	stat, err := os.Stat(manifest)
+	if err != nil {
+		return "", err
+	} else if !stat.IsDir() {
+		out, err := ioutil.ReadFile(manifest)
+		if err != nil {
+			return "", err
+		}
+
+		return string(out), nil
+	}
+
+	files, err := ioutil.ReadDir(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	manifests := []string{}
+	for _, file := range files {
+		extension := filepath.Ext(file.Name())
+		if file.IsDir() || (extension != ".yaml" && extension != ".yml" && extension != ".json") {
+			continue
+		}
+
+		out, err := ioutil.ReadFile(filepath.Join(manifest, file.Name()))
+		if err != nil {
+			return "", err
+		}
+
+		manifests = append(manifests, string(out))
+	}
+
+	return strings.Join(manifests, "\n---\n"), nil
+}
+
 var diffSeparator = regexp.MustCompile(`\n---`)
 
 // stringToUnstructuredArray splits a YAML file into unstructured objects. Returns a list of all unstructured objects
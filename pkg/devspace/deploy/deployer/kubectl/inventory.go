@@ -0,0 +1,239 @@
+package kubectl
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DeploymentAnnotation holds the name of the deployment that applied an object. Objects are only pruned by
+// the deployment that applied them
+const DeploymentAnnotation = "devspace.sh/deployment"
+
+// inventoryMutex guards the inventories of the deployment caches, because deployments are deployed in parallel
+var inventoryMutex sync.Mutex
+
+// inventory returns the objects of the given replaced manifests. Objects without a namespace are recorded
+// with the namespace of the deployment, unless they are cluster scoped
+func (d *DeployConfig) inventory(manifests []string) ([]*generated.KubectlObject, error) {
+	cluster := d.getCluster()
+	objects := []*generated.KubectlObject{}
+	seen := map[string]bool{}
+	for _, manifest := range manifests {
+		objs, err := diff.ParseObjects(manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespaced := true
+				if cluster != nil {
+					namespaced, err = cluster.IsNamespaced(obj.GroupVersionKind())
+					if err != nil {
+						return nil, err
+					}
+				}
+				if namespaced {
+					namespace = d.Namespace
+				}
+			}
+
+			object := &generated.KubectlObject{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Namespace:  namespace,
+				Name:       obj.GetName(),
+			}
+			if seen[objectKey(object)] {
+				continue
+			}
+
+			seen[objectKey(object)] = true
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+// removedObjects returns the previously applied objects that are not part of the applied objects anymore
+func removedObjects(previous, applied []*generated.KubectlObject) []*generated.KubectlObject {
+	keys := map[string]bool{}
+	for _, object := range applied {
+		keys[objectKey(object)] = true
+	}
+
+	removed := []*generated.KubectlObject{}
+	for _, object := range previous {
+		if !keys[objectKey(object)] {
+			removed = append(removed, object)
+		}
+	}
+
+	return removed
+}
+
+// prune deletes the given objects from the cluster in reverse order and returns the deleted objects. Objects
+// that are part of the inventory of another deployment or that were applied by another deployment are kept
+func (d *DeployConfig) prune(objects []*generated.KubectlObject) ([]*generated.KubectlObject, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	cluster := d.getCluster()
+	if cluster == nil {
+		d.Log.Warnf("Skipping pruning of %d objects, because there is no connection to the cluster to check if they were applied by %s", len(objects), d.DeploymentConfig.Name)
+		return nil, nil
+	}
+
+	claimed := d.claimedObjects()
+	pruned := []*generated.KubectlObject{}
+	for i := len(objects) - 1; i >= 0; i-- {
+		object := objects[i]
+		if claimed[objectKey(object)] {
+			d.Log.Infof("Skipping pruning of %s, because it belongs to another deployment", objectName(object))
+			continue
+		}
+
+		live, err := cluster.Get(groupVersionKind(object), object.Namespace, object.Name)
+		if err != nil {
+			return nil, errors.Errorf("error pruning %s: %v", objectName(object), err)
+		} else if live == nil {
+			continue
+		} else if live.GetAnnotations()[DeploymentAnnotation] != d.DeploymentConfig.Name {
+			d.Log.Infof("Skipping pruning of %s, because it was not applied by %s", objectName(object), d.DeploymentConfig.Name)
+			continue
+		}
+
+		metadata := map[string]interface{}{
+			"name": object.Name,
+		}
+		if d.isNative() && object.Namespace != "" {
+			metadata["namespace"] = object.Namespace
+		}
+
+		manifest, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": object.APIVersion,
+			"kind":       object.Kind,
+			"metadata":   metadata,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal yaml")
+		}
+
+		if d.isNative() {
+			applier, err := d.getNativeApplier()
+			if err != nil {
+				return nil, err
+			}
+
+			err = applier.Delete(string(manifest))
+			if err != nil {
+				return nil, errors.Errorf("error pruning %s: %v", objectName(object), err)
+			}
+		} else {
+			args := d.getCmdArgsInNamespace(object.Namespace, "delete", "--ignore-not-found=true")
+			args = append(args, d.DeploymentConfig.Kubectl.DeleteArgs...)
+
+			cmd := d.commandExecuter.GetCommand(d.CmdPath, args)
+			err = cmd.Run(d.Log, d.Log, strings.NewReader(string(manifest)))
+			if err != nil {
+				return nil, errors.Errorf("error pruning %s: %v", objectName(object), err)
+			}
+		}
+
+		pruned = append(pruned, object)
+	}
+
+	return pruned, nil
+}
+
+// claimedObjects returns the keys of the objects in the inventories of the other deployments
+func (d *DeployConfig) claimedObjects() map[string]bool {
+	inventoryMutex.Lock()
+	defer inventoryMutex.Unlock()
+
+	claimed := map[string]bool{}
+	for name, deployCache := range d.config.Generated().GetActive().Deployments {
+		if name == d.DeploymentConfig.Name || deployCache == nil {
+			continue
+		}
+
+		for _, object := range deployCache.KubectlObjects {
+			claimed[objectKey(object)] = true
+		}
+	}
+
+	return claimed
+}
+
+// setInventory replaces the inventory of the deployment cache
+func setInventory(deployCache *generated.DeploymentCache, objects []*generated.KubectlObject) {
+	inventoryMutex.Lock()
+	defer inventoryMutex.Unlock()
+
+	deployCache.KubectlObjects = objects
+}
+
+// getCluster returns the cluster to retrieve live objects from or nil if there is no connection to a cluster
+func (d *DeployConfig) getCluster() diff.Cluster {
+	if d.cluster == nil && d.KubeClient != nil && d.KubeClient.KubeClient() != nil {
+		d.cluster = diff.NewCluster(d.KubeClient.KubeClient())
+	}
+
+	return d.cluster
+}
+
+// objectKey identifies an object independent of its api group and version. Kinds that moved to another
+// group, like Deployments from extensions to apps, are served as the same object by the cluster and
+// must not be pruned
+func objectKey(object *generated.KubectlObject) string {
+	return object.Kind + "/" + object.Namespace + "/" + object.Name
+}
+
+// objectName returns a human readable name of the object
+func objectName(object *generated.KubectlObject) string {
+	name := groupVersionKind(object).GroupKind().String() + "/"
+	if object.Namespace != "" {
+		name += object.Namespace + "/"
+	}
+
+	return name + object.Name
+}
+
+// objectNames returns the human readable names of the objects
+func objectNames(objects []*generated.KubectlObject) string {
+	names := []string{}
+	for _, object := range objects {
+		names = append(names, objectName(object))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func groupVersionKind(object *generated.KubectlObject) schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(object.APIVersion, object.Kind)
+}
+
+// InventoryObjects returns the objects that were applied by the last deployment of a kubectl deployment
+func InventoryObjects(deployCache *generated.DeploymentCache) []*unstructured.Unstructured {
+	objs := []*unstructured.Unstructured{}
+	for _, object := range deployCache.KubectlObjects {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(object.APIVersion)
+		obj.SetKind(object.Kind)
+		obj.SetNamespace(object.Namespace)
+		obj.SetName(object.Name)
+		objs = append(objs, obj)
+	}
+
+	return objs
+}
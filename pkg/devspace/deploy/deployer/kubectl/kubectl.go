@@ -8,6 +8,7 @@ import (
 
 	config2 "github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/helm/downloader"
 	"github.com/mitchellh/go-homedir"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
@@ -41,6 +43,9 @@ type DeployConfig struct {
 	IsInCluster bool
 	Manifests   []string
 
+	// NoPrune keeps the objects that were removed from the manifests since the last deployment
+	NoPrune bool
+
 	DeploymentConfig *latest.DeploymentConfig
 	Log              log.Logger
 
@@ -48,6 +53,8 @@ type DeployConfig struct {
 	dependencies []types.Dependency
 
 	commandExecuter commandExecuter
+	nativeApplier   *nativeApplier
+	cluster         diff.Cluster
 }
 
 // New creates a new deploy config for kubectl
@@ -68,7 +75,9 @@ func New(config config2.Config, dependencies []types.Dependency, kubeClient kube
 		}
 		cmdPath string
 	)
-	if deployConfig.Kubectl.CmdPath != "" {
+	if deployConfig.Kubectl.Native != nil && *deployConfig.Kubectl.Native {
+		// the native engine does not need the kubectl binary
+	} else if deployConfig.Kubectl.CmdPath != "" {
 		cmdPath = deployConfig.Kubectl.CmdPath
 	} else {
 		home, err := homedir.Dir()
@@ -184,9 +193,14 @@ func (d *DeployConfig) Status() (*deployer.StatusResult, error) {
 
 // Delete deletes all matched manifests from kubernetes
 func (d *DeployConfig) Delete() error {
-	d.Log.StartWait("Deleting manifests with kubectl")
+	if d.isNative() {
+		d.Log.StartWait("Deleting manifests")
+	} else {
+		d.Log.StartWait("Deleting manifests with kubectl")
+	}
 	defer d.Log.StopWait()
 
+	replacedManifests := []string{}
 	for i := len(d.Manifests) - 1; i >= 0; i-- {
 		manifest := d.Manifests[i]
 		_, replacedManifest, err := d.getReplacedManifest(manifest, nil)
@@ -194,6 +208,21 @@ func (d *DeployConfig) Delete() error {
 			return err
 		}
 
+		replacedManifests = append(replacedManifests, replacedManifest)
+		if d.isNative() {
+			applier, err := d.getNativeApplier()
+			if err != nil {
+				return err
+			}
+
+			err = applier.Delete(replacedManifest)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		args := d.getCmdArgs("delete", "--ignore-not-found=true")
 		args = append(args, d.DeploymentConfig.Kubectl.DeleteArgs...)
 
@@ -205,6 +234,20 @@ func (d *DeployConfig) Delete() error {
 		}
 	}
 
+	// Delete the objects that were not pruned, because they were deployed with --no-prune
+	deployCache, ok := d.config.Generated().GetActive().Deployments[d.DeploymentConfig.Name]
+	if ok && len(deployCache.KubectlObjects) > 0 {
+		objects, err := d.inventory(replacedManifests)
+		if err != nil {
+			return err
+		}
+
+		_, err = d.prune(removedObjects(deployCache.KubectlObjects, objects))
+		if err != nil {
+			return err
+		}
+	}
+
 	delete(d.config.Generated().GetActive().Deployments, d.DeploymentConfig.Name)
 	return nil
 }
@@ -243,10 +286,15 @@ func (d *DeployConfig) Deploy(_ bool, builtImages map[string]string) (bool, erro
 	// forceDeploy = forceDeploy || deployCache.KubectlManifestsHash != manifestsHash || deployCache.DeploymentConfigHash != deploymentConfigHash
 	forceDeploy := true
 
-	d.Log.StartWait("Applying manifests with kubectl")
+	if d.isNative() {
+		d.Log.StartWait("Applying manifests with server side apply")
+	} else {
+		d.Log.StartWait("Applying manifests with kubectl")
+	}
 	defer d.Log.StopWait()
 
 	wasDeployed := false
+	replacedManifests := []string{}
 
 	for _, manifest := range d.Manifests {
 		shouldRedeploy, replacedManifest, err := d.getReplacedManifest(manifest, builtImages)
@@ -254,15 +302,12 @@ func (d *DeployConfig) Deploy(_ bool, builtImages map[string]string) (bool, erro
 			return false, errors.Errorf("%v\nPlease make sure `kubectl apply` does work locally with manifest `%s`", err, manifest)
 		}
 
-		if shouldRedeploy || forceDeploy {
-			stringReader := strings.NewReader(replacedManifest)
-			args := d.getCmdArgs("apply", "--force")
-			args = append(args, d.DeploymentConfig.Kubectl.ApplyArgs...)
+		replacedManifests = append(replacedManifests, replacedManifest)
 
-			cmd := d.commandExecuter.GetCommand(d.CmdPath, args)
-			err = cmd.Run(d.Log, d.Log, stringReader)
+		if shouldRedeploy || forceDeploy {
+			err = d.apply(manifest, replacedManifest)
 			if err != nil {
-				return false, errors.Errorf("%v\nPlease make sure the command `kubectl apply` does work locally with manifest `%s`", err, manifest)
+				return false, err
 			}
 
 			wasDeployed = true
@@ -271,12 +316,115 @@ func (d *DeployConfig) Deploy(_ bool, builtImages map[string]string) (bool, erro
 		}
 	}
 
+	objects, err := d.inventory(replacedManifests)
+	if err != nil {
+		return false, err
+	}
+
+	// Wait for the applied objects and roll back to the last successful deployment if they do not become ready
+	if d.DeploymentConfig.Kubectl.Wait || d.DeploymentConfig.Kubectl.Atomic {
+		err = d.waitForRollout(objects)
+		if err != nil {
+			if d.DeploymentConfig.Kubectl.Atomic {
+				return false, d.rollback(deployCache, objects, err)
+			}
+
+			// Keep the previously applied objects in the inventory, so they are pruned by the next deployment
+			setInventory(deployCache, append(objects, removedObjects(deployCache.KubectlObjects, objects)...))
+			return false, err
+		}
+	}
+
+	// Delete the objects that were removed from the manifests since the last deployment
+	removed := removedObjects(deployCache.KubectlObjects, objects)
+	if len(removed) > 0 && d.NoPrune {
+		// Keep the objects in the inventory, so they are pruned by the next deployment
+		objects = append(objects, removed...)
+		d.Log.Infof("Skipping pruning of %d objects that were removed from the manifests: %s", len(removed), objectNames(removed))
+	} else if len(removed) > 0 {
+		pruned, err := d.prune(removed)
+		if err != nil {
+			return false, err
+		} else if len(pruned) > 0 {
+			d.Log.Donef("Pruned %d objects that were removed from the manifests: %s", len(pruned), objectNames(pruned))
+			wasDeployed = true
+		}
+	}
+
 	deployCache.KubectlManifestsHash = manifestsHash
 	deployCache.DeploymentConfigHash = deploymentConfigHash
+	setInventory(deployCache, objects)
+	deployCache.KubectlRollbackManifests = nil
+	if d.DeploymentConfig.Kubectl.Atomic {
+		deployCache.KubectlRollbackManifests, err = rollbackManifests(replacedManifests)
+		if err != nil {
+			return false, err
+		}
+	}
 
 	return wasDeployed, nil
 }
 
+// apply applies the replaced manifest with the native engine or kubectl
+func (d *DeployConfig) apply(manifest, replacedManifest string) error {
+	if d.isNative() {
+		applier, err := d.getNativeApplier()
+		if err != nil {
+			return err
+		}
+
+		err = applier.Apply(replacedManifest)
+		if err != nil {
+			return errors.Errorf("%v\nPlease make sure the manifest `%s` is valid", err, manifest)
+		}
+
+		return nil
+	}
+
+	stringReader := strings.NewReader(replacedManifest)
+	args := d.getCmdArgs("apply", "--force")
+	args = append(args, d.DeploymentConfig.Kubectl.ApplyArgs...)
+
+	cmd := d.commandExecuter.GetCommand(d.CmdPath, args)
+	err := cmd.Run(d.Log, d.Log, stringReader)
+	if err != nil {
+		return errors.Errorf("%v\nPlease make sure the command `kubectl apply` does work locally with manifest `%s`", err, manifest)
+	}
+
+	return nil
+}
+
+// rollback re-applies the manifests of the last successful deployment and deletes the objects that were created
+// by the failed deployment. The returned error contains the cause of the rollback
+func (d *DeployConfig) rollback(deployCache *generated.DeploymentCache, objects []*generated.KubectlObject, cause error) error {
+	if len(deployCache.KubectlRollbackManifests) == 0 {
+		setInventory(deployCache, append(objects, removedObjects(deployCache.KubectlObjects, objects)...))
+		return errors.Errorf("%v\nCannot roll back %s, because there is no successful deployment yet", cause, d.DeploymentConfig.Name)
+	}
+
+	d.Log.Warnf("Rolling back %s to the last successful deployment", d.DeploymentConfig.Name)
+	for _, manifest := range deployCache.KubectlRollbackManifests {
+		err := d.apply("last successful deployment of "+d.DeploymentConfig.Name, manifest)
+		if err != nil {
+			return errors.Errorf("%v\nError rolling back %s: %v", cause, d.DeploymentConfig.Name, err)
+		}
+	}
+
+	created := removedObjects(objects, deployCache.KubectlObjects)
+	if len(created) > 0 {
+		if d.NoPrune {
+			setInventory(deployCache, append(deployCache.KubectlObjects, created...))
+		} else {
+			_, err := d.prune(created)
+			if err != nil {
+				return errors.Errorf("%v\nError rolling back %s: %v", cause, d.DeploymentConfig.Name, err)
+			}
+		}
+	}
+
+	return errors.Errorf("%v\nRolled back %s to the last successful deployment", cause, d.DeploymentConfig.Name)
+}
+
 func (d *DeployConfig) getReplacedManifest(manifest string, builtImages map[string]string) (bool, string, error) {
 	objects, err := d.buildManifests(manifest)
 	if err != nil {
@@ -303,6 +451,14 @@ func (d *DeployConfig) getReplacedManifest(manifest string, builtImages map[stri
 			}
 		}
 
+		// mark the object as applied by this deployment, so it is only pruned by this deployment
+		annotations := resource.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[DeploymentAnnotation] = d.DeploymentConfig.Name
+		resource.SetAnnotations(annotations)
+
 		replacedManifest, err := yaml.Marshal(resource)
 		if err != nil {
 			return false, "", errors.Wrap(err, "marshal yaml")
@@ -315,12 +471,16 @@ func (d *DeployConfig) getReplacedManifest(manifest string, builtImages map[stri
 }
 
 func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []string {
+	return d.getCmdArgsInNamespace(d.Namespace, method, additionalArgs...)
+}
+
+func (d *DeployConfig) getCmdArgsInNamespace(namespace, method string, additionalArgs ...string) []string {
 	args := []string{}
 	if d.Context != "" && !d.IsInCluster {
 		args = append(args, "--context", d.Context)
 	}
-	if d.Namespace != "" {
-		args = append(args, "--namespace", d.Namespace)
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
 	}
 
 	args = append(args, method)
@@ -333,6 +493,10 @@ func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []str
 }
 
 func (d *DeployConfig) buildManifests(manifest string) ([]*unstructured.Unstructured, error) {
+	if d.isNative() {
+		return NewNativeBuilder(d.DeploymentConfig).Build(manifest, d.commandExecuter.RunCommand)
+	}
+
 	// Check if we should use kustomize or kubectl
 	if d.DeploymentConfig.Kubectl.Kustomize != nil && *d.DeploymentConfig.Kubectl.Kustomize && d.isKustomizeInstalled("kustomize") {
 		return NewKustomizeBuilder("kustomize", d.DeploymentConfig, d.Log).Build(manifest, d.commandExecuter.RunCommand)
@@ -346,3 +510,24 @@ func (d *DeployConfig) isKustomizeInstalled(path string) bool {
 	_, err := d.commandExecuter.RunCommand(path, []string{"version"})
 	return err == nil
 }
+
+func (d *DeployConfig) isNative() bool {
+	return d.DeploymentConfig.Kubectl.Native != nil && *d.DeploymentConfig.Kubectl.Native
+}
+
+// getNativeApplier returns the applier of the native engine, which is created on first use
+func (d *DeployConfig) getNativeApplier() (*nativeApplier, error) {
+	if d.nativeApplier != nil {
+		return d.nativeApplier, nil
+	} else if d.KubeClient == nil {
+		return nil, errors.New("kubectl.native requires a connection to a kubernetes cluster")
+	}
+
+	applier, err := newNativeApplier(d.KubeClient.RestConfig(), d.Namespace, d.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	d.nativeApplier = applier
+	return applier, nil
+}
@@ -20,6 +20,11 @@ import (
 	"github.com/pkg/errors"
 	yaml "gopkg.in/yaml.v2"
 	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 type newTestCase struct {
@@ -60,6 +65,28 @@ func TestNew(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Native without kubectl",
+			deployConfig: &latest.DeploymentConfig{
+				Name: "someDeploy",
+				Kubectl: &latest.KubectlConfig{
+					Manifests: []string{"someManifest"},
+					Native:    ptr.Bool(true),
+				},
+			},
+			expectedDeployer: &DeployConfig{
+				Name:      "someDeploy",
+				Manifests: []string{"someManifest"},
+
+				DeploymentConfig: &latest.DeploymentConfig{
+					Name: "someDeploy",
+					Kubectl: &latest.KubectlConfig{
+						Manifests: []string{"someManifest"},
+						Native:    ptr.Bool(true),
+					},
+				},
+			},
+		},
 		{
 			name: "Everything given",
 			deployConfig: &latest.DeploymentConfig{
@@ -153,6 +180,26 @@ func (e *fakeExecuter) checkParams(path string, args []string) {
 	}
 }
 
+// fakeCluster returns the live objects in owners, which maps kind/namespace/name to the deployment that applied the object
+type fakeCluster struct {
+	owners map[string]string
+}
+
+func (f *fakeCluster) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	return gvk.Kind != "Namespace" && gvk.Kind != "ClusterRole", nil
+}
+
+func (f *fakeCluster) Get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	owner, ok := f.owners[gvk.Kind+"/"+namespace+"/"+name]
+	if !ok {
+		return nil, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(map[string]string{DeploymentAnnotation: owner})
+	return obj, nil
+}
+
 type renderTestCase struct {
 	name string
 
@@ -336,21 +383,29 @@ func TestDelete(t *testing.T) {
 type deployTestCase struct {
 	name string
 
-	output       string
-	cmdPath      string
-	context      string
-	namespace    string
-	manifests    []string
-	kustomize    bool
-	kubectlFlags []string
-	cache        *generated.CacheConfig
-	forceDeploy  bool
-	builtImages  map[string]string
+	deploymentName string
+	output         string
+	cmdPath        string
+	context        string
+	namespace      string
+	manifests      []string
+	kustomize      bool
+	kubectlFlags   []string
+	cache          *generated.CacheConfig
+	forceDeploy    bool
+	builtImages    map[string]string
+	noPrune        bool
+	atomic         bool
+	kubeClient     kubectl.Client
+	liveObjects    map[string]string
 
 	expectedDeployed bool
 	expectedErr      string
 	expectedPaths    []string
 	expectedArgs     [][]string
+	expectedObjects  []*generated.KubectlObject
+
+	expectedRollbackManifests []string
 }
 
 func TestDeploy(t *testing.T) {
@@ -369,31 +424,200 @@ func TestDeploy(t *testing.T) {
 				{"--context", "myContext", "--namespace", "myNamespace", "apply", "--force", "-f", "-", "someFlag"},
 			},
 		},
+		{
+			name:      "prune removed objects",
+			cmdPath:   "myPath",
+			namespace: "myNamespace",
+			manifests: []string{"."},
+			output:    "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: backend\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new-config\n",
+			cache: &generated.CacheConfig{
+				Deployments: map[string]*generated.DeploymentCache{
+					"": {
+						KubectlObjects: []*generated.KubectlObject{
+							{APIVersion: "extensions/v1beta1", Kind: "Deployment", Namespace: "myNamespace", Name: "backend"},
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "old-config"},
+							{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "reader"},
+						},
+					},
+				},
+			},
+			expectedDeployed: true,
+			expectedPaths:    []string{"myPath", "myPath", "myPath", "myPath"},
+			expectedArgs: [][]string{
+				{"create", "--namespace", "myNamespace", "--dry-run", "--output", "yaml", "--validate=false", "--filename", "."},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+				{"delete", "--ignore-not-found=true", "-f", "-"},
+				{"--namespace", "myNamespace", "delete", "--ignore-not-found=true", "-f", "-"},
+			},
+			liveObjects: map[string]string{
+				"ConfigMap/myNamespace/old-config": "",
+				"ClusterRole//reader":              "",
+			},
+			expectedObjects: []*generated.KubectlObject{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "myNamespace", Name: "backend"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "new-config"},
+			},
+		},
+		{
+			name:           "keep removed objects of other deployments",
+			deploymentName: "backend",
+			cmdPath:        "myPath",
+			namespace:      "myNamespace",
+			manifests:      []string{"."},
+			output:         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new-config\n",
+			cache: &generated.CacheConfig{
+				Deployments: map[string]*generated.DeploymentCache{
+					"backend": {
+						KubectlObjects: []*generated.KubectlObject{
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "moved-config"},
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "taken-config"},
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "deleted-config"},
+						},
+					},
+					"frontend": {
+						KubectlObjects: []*generated.KubectlObject{
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "moved-config"},
+						},
+					},
+				},
+			},
+			liveObjects: map[string]string{
+				"ConfigMap/myNamespace/moved-config": "backend",
+				"ConfigMap/myNamespace/taken-config": "frontend",
+			},
+			expectedDeployed: true,
+			expectedPaths:    []string{"myPath", "myPath"},
+			expectedArgs: [][]string{
+				{"create", "--namespace", "myNamespace", "--dry-run", "--output", "yaml", "--validate=false", "--filename", "."},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+			},
+			expectedObjects: []*generated.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "new-config"},
+			},
+		},
+		{
+			name:      "keep removed objects with no prune",
+			cmdPath:   "myPath",
+			namespace: "myNamespace",
+			manifests: []string{"."},
+			noPrune:   true,
+			output:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new-config\n",
+			cache: &generated.CacheConfig{
+				Deployments: map[string]*generated.DeploymentCache{
+					"": {
+						KubectlObjects: []*generated.KubectlObject{
+							{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "old-config"},
+						},
+					},
+				},
+			},
+			expectedDeployed: true,
+			expectedPaths:    []string{"myPath", "myPath"},
+			expectedArgs: [][]string{
+				{"create", "--namespace", "myNamespace", "--dry-run", "--output", "yaml", "--validate=false", "--filename", "."},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+			},
+			expectedObjects: []*generated.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "new-config"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "myNamespace", Name: "old-config"},
+			},
+		},
+		{
+			name:           "store rollback manifests without secrets",
+			deploymentName: "backend",
+			cmdPath:        "myPath",
+			namespace:      "myNamespace",
+			manifests:      []string{"."},
+			atomic:         true,
+			output:         "apiVersion: v1\nkind: Secret\nmetadata:\n  name: credentials\n  namespace: myNamespace\ndata:\n  password: c2VjcmV0\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: myNamespace\n",
+			cache: &generated.CacheConfig{
+				Deployments: map[string]*generated.DeploymentCache{},
+			},
+			kubeClient: &fakekube.Client{
+				Client: fake.NewSimpleClientset(),
+			},
+			expectedDeployed: true,
+			expectedPaths:    []string{"myPath", "myPath"},
+			expectedArgs: [][]string{
+				{"create", "--namespace", "myNamespace", "--dry-run", "--output", "yaml", "--validate=false", "--filename", "."},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+			},
+			expectedRollbackManifests: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  annotations:\n    devspace.sh/deployment: backend\n  name: config\n  namespace: myNamespace\n"},
+		},
+		{
+			name:           "roll back failed rollout",
+			deploymentName: "backend",
+			cmdPath:        "myPath",
+			namespace:      "myNamespace",
+			manifests:      []string{"."},
+			atomic:         true,
+			output:         "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: backend\n  namespace: myNamespace\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new-config\n  namespace: myNamespace\n",
+			kubeClient: &fakekube.Client{
+				Client: fake.NewSimpleClientset(&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "myNamespace"},
+					Status: appsv1.DeploymentStatus{
+						Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}},
+					},
+				}),
+			},
+			cache: &generated.CacheConfig{
+				Deployments: map[string]*generated.DeploymentCache{
+					"backend": {
+						KubectlObjects: []*generated.KubectlObject{
+							{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "myNamespace", Name: "backend"},
+						},
+						KubectlRollbackManifests: []string{"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: backend\n  namespace: myNamespace\n"},
+					},
+				},
+			},
+			expectedErr:   "rollout failed:\nDeployment.apps/myNamespace/backend: deployment exceeded its progress deadline\nRolled back backend to the last successful deployment",
+			expectedPaths: []string{"myPath", "myPath", "myPath", "myPath"},
+			expectedArgs: [][]string{
+				{"create", "--namespace", "myNamespace", "--dry-run", "--output", "yaml", "--validate=false", "--filename", "."},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+				{"--namespace", "myNamespace", "apply", "--force", "-f", "-"},
+				{"--namespace", "myNamespace", "delete", "--ignore-not-found=true", "-f", "-"},
+			},
+			liveObjects: map[string]string{
+				"ConfigMap/myNamespace/new-config": "backend",
+			},
+			expectedObjects: []*generated.KubectlObject{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "myNamespace", Name: "backend"},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
 		cache := generated.New()
 		cache.Profiles[""] = testCase.cache
+		executer := &fakeExecuter{
+			output:       testCase.output,
+			t:            t,
+			testCase:     testCase.name,
+			expectedPath: testCase.expectedPaths,
+			expectedArgs: testCase.expectedArgs,
+		}
 		deployer := &DeployConfig{
-			config:    config.NewConfig(nil, latest.NewRaw(), cache, nil, constants.DefaultConfigPath),
-			CmdPath:   testCase.cmdPath,
-			Context:   testCase.context,
-			Namespace: testCase.namespace,
-			Manifests: testCase.manifests,
+			config:     config.NewConfig(nil, latest.NewRaw(), cache, nil, constants.DefaultConfigPath),
+			KubeClient: testCase.kubeClient,
+			CmdPath:    testCase.cmdPath,
+			Context:    testCase.context,
+			Namespace:  testCase.namespace,
+			Manifests:  testCase.manifests,
+			NoPrune:    testCase.noPrune,
 			DeploymentConfig: &latest.DeploymentConfig{
+				Name: testCase.deploymentName,
 				Kubectl: &latest.KubectlConfig{
 					Kustomize: &testCase.kustomize,
 					ApplyArgs: testCase.kubectlFlags,
+					Atomic:    testCase.atomic,
 				},
 			},
-			commandExecuter: &fakeExecuter{
-				output:       testCase.output,
-				t:            t,
-				testCase:     testCase.name,
-				expectedPath: testCase.expectedPaths,
-				expectedArgs: testCase.expectedArgs,
-			},
-			Log: &log.FakeLogger{},
+			commandExecuter: executer,
+			Log:             &log.FakeLogger{},
+		}
+		if testCase.liveObjects != nil {
+			deployer.cluster = &fakeCluster{owners: testCase.liveObjects}
 		}
 
 		if testCase.cache == nil {
@@ -411,6 +635,13 @@ func TestDeploy(t *testing.T) {
 		}
 
 		assert.Equal(t, deployed, testCase.expectedDeployed, "Unexpected deployed-bool in testCase %s", testCase.name)
+		assert.Equal(t, len(executer.expectedArgs), 0, "Not all expected commands were run in testCase %s", testCase.name)
+		if testCase.expectedObjects != nil {
+			assert.DeepEqual(t, cache.Profiles[""].Deployments[testCase.deploymentName].KubectlObjects, testCase.expectedObjects)
+		}
+		if testCase.expectedRollbackManifests != nil {
+			assert.DeepEqual(t, cache.Profiles[""].Deployments[testCase.deploymentName].KubectlRollbackManifests, testCase.expectedRollbackManifests)
+		}
 	}
 }
 
@@ -459,7 +690,7 @@ func TestGetReplacedManifest(t *testing.T) {
 				"myimage": "",
 			},
 			expectedRedeploy: true,
-			expectedManifest: "apiVersion: v1\nimage: myimage:mytag\nkind: Pod\n",
+			expectedManifest: "apiVersion: v1\nimage: myimage:mytag\nkind: Pod\nmetadata:\n  annotations:\n    devspace.sh/deployment: \"\"\n",
 		},
 	}
 
@@ -0,0 +1,148 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// fieldManager is the field manager of the fields that are applied by devspace
+const fieldManager = "devspace"
+
+// nativeApplier applies and deletes manifests with the dynamic client instead of the kubectl binary
+type nativeApplier struct {
+	client    dynamic.Interface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	namespace string
+	log       log.Logger
+}
+
+// newNativeApplier creates a new applier for the cluster of the rest config. Objects without a namespace
+// are applied to the given namespace
+func newNativeApplier(restConfig *rest.Config, namespace string, log log.Logger) (*nativeApplier, error) {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "create dynamic client")
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "create discovery client")
+	}
+
+	return &nativeApplier{
+		client:    client,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		namespace: namespace,
+		log:       log,
+	}, nil
+}
+
+// Apply applies the objects of the manifest with server side apply. Conflicting fields of other field managers
+// are overwritten, like kubectl apply --force does
+func (n *nativeApplier) Apply(manifest string) error {
+	objs, err := diff.ParseObjects(manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		resource, name, err := n.resourceFor(obj)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return errors.Wrap(err, "marshal object")
+		}
+
+		_, err = resource.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+			FieldManager: fieldManager,
+			Force:        ptr.Bool(true),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "apply %s", name)
+		}
+
+		n.log.Infof("%s serverside-applied", name)
+	}
+
+	return nil
+}
+
+// Delete deletes the objects of the manifest. Objects that do not exist are ignored
+func (n *nativeApplier) Delete(manifest string) error {
+	objs, err := diff.ParseObjects(manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		resource, name, err := n.resourceFor(obj)
+		if err != nil {
+			if meta.IsNoMatchError(errors.Cause(err)) {
+				continue
+			}
+
+			return err
+		}
+
+		propagationPolicy := metav1.DeletePropagationBackground
+		err = resource.Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{
+			PropagationPolicy: &propagationPolicy,
+		})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+
+			return errors.Wrapf(err, "delete %s", name)
+		}
+
+		n.log.Infof("%s deleted", name)
+	}
+
+	return nil
+}
+
+// resourceFor returns the resource client of the object and its name in the format of kubectl. The namespace
+// of namespaced objects is set if it is missing
+func (n *nativeApplier) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, string, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := n.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// the kind might have been created by a previous object, like a custom resource definition
+		n.mapper.Reset()
+		mapping, err = n.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "find resource of %s", gvk.String())
+	}
+
+	name := mapping.Resource.GroupResource().String() + "/" + obj.GetName()
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		return n.client.Resource(mapping.Resource), name, nil
+	}
+
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(n.namespace)
+	}
+
+	return n.client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), name, nil
+}
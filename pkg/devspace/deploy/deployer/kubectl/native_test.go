@@ -0,0 +1,160 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	log "github.com/loft-sh/devspace/pkg/util/log/testing"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// testAPIServer is a local api server that serves the discovery of configmaps, namespaces and deployments
+// and records the requests to them
+type testAPIServer struct {
+	m        sync.Mutex
+	requests []string
+}
+
+func (s *testAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	discovery := map[string]interface{}{
+		"/api": &metav1.APIVersions{Versions: []string{"v1"}},
+		"/apis": &metav1.APIGroupList{Groups: []metav1.APIGroup{{
+			Name:             "apps",
+			Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "apps/v1", Version: "v1"}},
+			PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"},
+		}}},
+		"/api/v1": &metav1.APIResourceList{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "patch", "delete"}},
+			{Name: "namespaces", Kind: "Namespace", Verbs: []string{"get", "patch", "delete"}},
+		}},
+		"/apis/apps/v1": &metav1.APIResourceList{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "patch", "delete"}},
+		}},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodGet {
+		if response, ok := discovery[r.URL.Path]; ok {
+			_ = json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	switch r.Method {
+	case http.MethodPatch:
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+" "+r.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(&metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(&metav1.Status{Status: metav1.StatusSuccess})
+	}
+}
+
+func TestNativeApplier(t *testing.T) {
+	apiServer := &testAPIServer{}
+	server := httptest.NewServer(apiServer)
+	defer server.Close()
+
+	applier, err := newNativeApplier(&rest.Config{Host: server.URL}, "test", &log.FakeLogger{})
+	assert.NilError(t, err)
+
+	err = applier.Apply(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: other
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: other
+`)
+	assert.NilError(t, err)
+
+	err = applier.Delete(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: missing
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unknown
+`)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, apiServer.requests, []string{
+		"PATCH /api/v1/namespaces/test/configmaps/config?fieldManager=devspace&force=true application/apply-patch+yaml",
+		"PATCH /api/v1/namespaces/other?fieldManager=devspace&force=true application/apply-patch+yaml",
+		"PATCH /apis/apps/v1/namespaces/other/deployments/backend?fieldManager=devspace&force=true application/apply-patch+yaml",
+		"DELETE /api/v1/namespaces/test/configmaps/config",
+		"DELETE /api/v1/namespaces/test/configmaps/missing",
+	})
+}
+
+func TestNativeBuilder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "native-builder")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"manifests/service.yaml":       "apiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n",
+		"manifests/configmap.json":     `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}}`,
+		"manifests/README.md":          "not a manifest",
+		"kustomize/kustomization.yaml": "namePrefix: dev-\nresources:\n- service.yaml\n",
+		"kustomize/service.yaml":       "apiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	objs, err := NewNativeBuilder(&latest.DeploymentConfig{Kubectl: &latest.KubectlConfig{}}).Build(filepath.Join(dir, "manifests"), nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objs), 2)
+	assert.Equal(t, objs[0].GetName(), "config")
+	assert.Equal(t, objs[1].GetName(), "backend")
+
+	objs, err = NewNativeBuilder(&latest.DeploymentConfig{Kubectl: &latest.KubectlConfig{Kustomize: ptr.Bool(true)}}).Build(filepath.Join(dir, "kustomize"), nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objs), 1)
+	assert.Equal(t, objs[0].GetKind(), "Service")
+	assert.Equal(t, objs[0].GetName(), "dev-backend")
+}
@@ -0,0 +1,317 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/analyze"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// defaultRolloutTimeout is the default amount of seconds to wait for the applied objects to become ready
+const defaultRolloutTimeout = 300
+
+// rolloutInterval is the interval in which the status of the applied objects is checked
+var rolloutInterval = 2 * time.Second
+
+// rolloutResult is the rollout status of an applied object
+type rolloutResult struct {
+	object *generated.KubectlObject
+
+	// ready is true if the rollout finished and failed is true if the rollout will not finish anymore
+	ready  bool
+	failed bool
+
+	message  string
+	selector *metav1.LabelSelector
+}
+
+// waitForRollout waits until the applied deployments, stateful sets, daemon sets and jobs are ready. If they do not
+// become ready within the timeout or their rollout failed, an error with the problems of their pods is returned
+func (d *DeployConfig) waitForRollout(objects []*generated.KubectlObject) error {
+	workloads := []*generated.KubectlObject{}
+	for _, object := range objects {
+		if isWorkload(object) {
+			workloads = append(workloads, object)
+		}
+	}
+	if len(workloads) == 0 {
+		return nil
+	} else if d.KubeClient == nil || d.KubeClient.KubeClient() == nil {
+		return errors.New("kubectl.wait requires a connection to a kubernetes cluster")
+	}
+
+	timeout := int64(defaultRolloutTimeout)
+	if d.DeploymentConfig.Kubectl.Timeout != nil {
+		timeout = *d.DeploymentConfig.Kubectl.Timeout
+	}
+
+	d.Log.StartWait(fmt.Sprintf("Waiting for the rollout of %d objects", len(workloads)))
+	defer d.Log.StopWait()
+
+	var results []*rolloutResult
+	err := wait.PollImmediate(rolloutInterval, time.Duration(timeout)*time.Second, func() (bool, error) {
+		results = []*rolloutResult{}
+		for _, object := range workloads {
+			result, err := d.rolloutStatus(object)
+			if err != nil {
+				return false, err
+			} else if result.failed {
+				results = []*rolloutResult{result}
+				return true, nil
+			} else if !result.ready {
+				results = append(results, result)
+			}
+		}
+
+		return len(results) == 0, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return err
+	} else if len(results) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, result := range results {
+		messages = append(messages, objectName(result.object)+": "+result.message)
+		messages = append(messages, d.podProblems(result)...)
+	}
+
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out after %ds waiting for the rollout:\n%s", timeout, strings.Join(messages, "\n"))
+	}
+
+	return errors.Errorf("rollout failed:\n%s", strings.Join(messages, "\n"))
+}
+
+// podProblems returns the problems of the pods that belong to the object
+func (d *DeployConfig) podProblems(result *rolloutResult) []string {
+	if result.selector == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(result.selector)
+	if err != nil {
+		return nil
+	}
+
+	pods, err := d.KubeClient.KubeClient().CoreV1().Pods(result.object.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil
+	}
+
+	return analyze.PodProblems(d.KubeClient, pods.Items, false)
+}
+
+func isWorkload(object *generated.KubectlObject) bool {
+	groupKind := groupVersionKind(object).GroupKind()
+	switch groupKind.Kind {
+	case "Deployment", "DaemonSet":
+		return groupKind.Group == "apps" || groupKind.Group == "extensions"
+	case "StatefulSet":
+		return groupKind.Group == "apps"
+	case "Job":
+		return groupKind.Group == "batch"
+	}
+
+	return false
+}
+
+// rolloutStatus returns the rollout status of the object. The checks are the same as kubectl rollout status does
+func (d *DeployConfig) rolloutStatus(object *generated.KubectlObject) (*rolloutResult, error) {
+	var (
+		client = d.KubeClient.KubeClient()
+		result = &rolloutResult{object: object}
+		err    error
+	)
+
+	switch object.Kind {
+	case "Deployment":
+		var deployment *appsv1.Deployment
+		deployment, err = client.AppsV1().Deployments(object.Namespace).Get(context.TODO(), object.Name, metav1.GetOptions{})
+		if err == nil {
+			deploymentStatus(deployment, result)
+		}
+	case "StatefulSet":
+		var statefulSet *appsv1.StatefulSet
+		statefulSet, err = client.AppsV1().StatefulSets(object.Namespace).Get(context.TODO(), object.Name, metav1.GetOptions{})
+		if err == nil {
+			statefulSetStatus(statefulSet, result)
+		}
+	case "DaemonSet":
+		var daemonSet *appsv1.DaemonSet
+		daemonSet, err = client.AppsV1().DaemonSets(object.Namespace).Get(context.TODO(), object.Name, metav1.GetOptions{})
+		if err == nil {
+			daemonSetStatus(daemonSet, result)
+		}
+	case "Job":
+		var job *batchv1.Job
+		job, err = client.BatchV1().Jobs(object.Namespace).Get(context.TODO(), object.Name, metav1.GetOptions{})
+		if err == nil {
+			jobStatus(job, result)
+		}
+	}
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			result.message = "waiting for the object to be created"
+			return result, nil
+		}
+
+		return nil, errors.Wrapf(err, "get %s", objectName(object))
+	}
+
+	return result, nil
+}
+
+func deploymentStatus(deployment *appsv1.Deployment, result *rolloutResult) {
+	result.selector = deployment.Spec.Selector
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		result.message = "waiting for the deployment spec update to be observed"
+		return
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			result.failed = true
+			result.message = "deployment exceeded its progress deadline"
+			return
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	if deployment.Status.UpdatedReplicas < replicas {
+		result.message = fmt.Sprintf("%d out of %d new replicas have been updated", deployment.Status.UpdatedReplicas, replicas)
+	} else if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		result.message = fmt.Sprintf("%d old replicas are pending termination", deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	} else if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+		result.message = fmt.Sprintf("%d of %d updated replicas are available", deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	} else {
+		result.ready = true
+	}
+}
+
+func statefulSetStatus(statefulSet *appsv1.StatefulSet, result *rolloutResult) {
+	result.selector = statefulSet.Spec.Selector
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		result.ready = true
+		return
+	} else if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		result.message = "waiting for the statefulset spec update to be observed"
+		return
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	if statefulSet.Status.ReadyReplicas < replicas {
+		result.message = fmt.Sprintf("%d of %d replicas are ready", statefulSet.Status.ReadyReplicas, replicas)
+		return
+	}
+
+	rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		if statefulSet.Status.UpdatedReplicas < replicas-*rollingUpdate.Partition {
+			result.message = fmt.Sprintf("%d of %d new pods have been updated", statefulSet.Status.UpdatedReplicas, replicas-*rollingUpdate.Partition)
+		} else {
+			result.ready = true
+		}
+		return
+	}
+
+	if statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		result.message = fmt.Sprintf("%d of %d pods have been updated", statefulSet.Status.UpdatedReplicas, replicas)
+	} else {
+		result.ready = true
+	}
+}
+
+func daemonSetStatus(daemonSet *appsv1.DaemonSet, result *rolloutResult) {
+	result.selector = daemonSet.Spec.Selector
+	if daemonSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		result.ready = true
+		return
+	} else if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		result.message = "waiting for the daemonset spec update to be observed"
+		return
+	}
+
+	if daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled {
+		result.message = fmt.Sprintf("%d out of %d new pods have been updated", daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled)
+	} else if daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled {
+		result.message = fmt.Sprintf("%d of %d updated pods are available", daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled)
+	} else {
+		result.ready = true
+	}
+}
+
+func jobStatus(job *batchv1.Job, result *rolloutResult) {
+	result.selector = job.Spec.Selector
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		if condition.Type == batchv1.JobComplete {
+			result.ready = true
+			return
+		} else if condition.Type == batchv1.JobFailed {
+			result.failed = true
+			result.message = fmt.Sprintf("job failed: %s", condition.Message)
+			return
+		}
+	}
+
+	result.message = "waiting for the job to complete"
+}
+
+// rollbackManifests returns the manifests that are stored in the generated config to roll back to. Secrets are
+// left out, because the generated config is a plain file and must not contain their data
+func rollbackManifests(manifests []string) ([]string, error) {
+	rollback := []string{}
+	for _, manifest := range manifests {
+		objects, err := diff.ParseObjects(manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := []string{}
+		for _, object := range objects {
+			if object.GetKind() == "Secret" && object.GroupVersionKind().Group == "" {
+				continue
+			}
+
+			resource, err := yaml.Marshal(object)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshal yaml")
+			}
+
+			resources = append(resources, string(resource))
+		}
+		if len(resources) > 0 {
+			rollback = append(rollback, strings.Join(resources, "\n---\n"))
+		}
+	}
+
+	return rollback, nil
+}
@@ -0,0 +1,135 @@
+package kubectl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	log "github.com/loft-sh/devspace/pkg/util/log/testing"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type waitForRolloutTestCase struct {
+	name string
+
+	objects        []*generated.KubectlObject
+	clusterObjects []runtime.Object
+
+	expectedErr []string
+}
+
+var backendSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backend"}}
+
+func TestWaitForRollout(t *testing.T) {
+	rolloutInterval = 10 * time.Millisecond
+	defer func() { rolloutInterval = 2 * time.Second }()
+
+	testCases := []waitForRolloutTestCase{
+		{
+			name: "Ready objects",
+			objects: []*generated.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "test", Name: "config"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "test", Name: "backend"},
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "test", Name: "database"},
+				{APIVersion: "batch/v1", Kind: "Job", Namespace: "test", Name: "migrate"},
+			},
+			clusterObjects: []runtime.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "test", Generation: 2},
+					Spec:       appsv1.DeploymentSpec{Replicas: ptr.Int32(2), Selector: backendSelector},
+					Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+				},
+				&appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "database", Namespace: "test"},
+					Spec:       appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}},
+					Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1, CurrentRevision: "1", UpdateRevision: "1"},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "test"},
+					Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}},
+				},
+			},
+		},
+		{
+			name: "Crash looping deployment",
+			objects: []*generated.KubectlObject{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "test", Name: "backend"},
+			},
+			clusterObjects: []runtime.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "test"},
+					Spec:       appsv1.DeploymentSpec{Selector: backendSelector},
+					Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend-abc", Namespace: "test", Labels: map[string]string{"app": "backend"}},
+					Status: corev1.PodStatus{
+						ContainerStatuses: []corev1.ContainerStatus{{
+							Name:  "backend",
+							State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						}},
+					},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+					Status: corev1.PodStatus{
+						ContainerStatuses: []corev1.ContainerStatus{{
+							Name:  "other",
+							State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+						}},
+					},
+				},
+			},
+			expectedErr: []string{"timed out after 1s waiting for the rollout", "Deployment.apps/test/backend: 0 of 1 updated replicas are available", "backend-abc", "CrashLoopBackOff"},
+		},
+		{
+			name: "Failed job",
+			objects: []*generated.KubectlObject{
+				{APIVersion: "batch/v1", Kind: "Job", Namespace: "test", Name: "migrate"},
+			},
+			clusterObjects: []runtime.Object{
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "test"},
+					Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job has reached the specified backoff limit"}}},
+				},
+			},
+			expectedErr: []string{"rollout failed", "Job.batch/test/migrate: job failed: Job has reached the specified backoff limit"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		deployer := &DeployConfig{
+			KubeClient: &fakekube.Client{
+				Client: fake.NewSimpleClientset(testCase.clusterObjects...),
+			},
+			DeploymentConfig: &latest.DeploymentConfig{
+				Kubectl: &latest.KubectlConfig{
+					Timeout: ptr.Int64(1),
+				},
+			},
+			Log: &log.FakeLogger{},
+		}
+
+		err := deployer.waitForRollout(testCase.objects)
+		if len(testCase.expectedErr) == 0 {
+			assert.NilError(t, err, "Error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.Assert(t, err != nil, "No error in testCase %s", testCase.name)
+		for _, expected := range testCase.expectedErr {
+			assert.Assert(t, strings.Contains(err.Error(), expected), "Error %q does not contain %q in testCase %s", err.Error(), expected, testCase.name)
+		}
+		assert.Assert(t, !strings.Contains(err.Error(), "ImagePullBackOff"), "Unrelated pod problem in testCase %s", testCase.name)
+	}
+}
@@ -131,6 +131,11 @@ func resolveImage(value string, config config2.Config, dependencies []types.Depe
 			return true, shouldRedeploy, tag, nil
 		}
 
+		// pin the image to the digest whose signature was verified, because the tag might be pushed again
+		if imageCache[configImageKey] != nil && imageCache[configImageKey].VerifiedDigest != "" && tag == imageCache[configImageKey].VerifiedTag {
+			return true, shouldRedeploy, image + "@" + imageCache[configImageKey].VerifiedDigest, nil
+		}
+
 		// return either with or without tag
 		if tag == "" {
 			return true, shouldRedeploy, image, nil
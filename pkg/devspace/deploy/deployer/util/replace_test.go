@@ -78,6 +78,30 @@ func TestReplaceContainerNames(t *testing.T) {
 				"": "myimage:someTag",
 			},
 		},
+		{
+			name: "Pin verified image to digest",
+			overwriteValues: map[interface{}]interface{}{
+				"": "myimage",
+			},
+			imagesConf: map[string]*latest.ImageConfig{
+				"test": {
+					Image: "myimage",
+				},
+			},
+			cache: &generated.CacheConfig{
+				Images: map[string]*generated.ImageCache{
+					"test": {
+						ImageName:      "myimage",
+						Tag:            "someTag",
+						VerifiedTag:    "someTag",
+						VerifiedDigest: "sha256:0123",
+					},
+				},
+			},
+			expectedOverwriteValues: map[interface{}]interface{}{
+				"": "myimage@sha256:0123",
+			},
+		},
 		{
 			name: "Replace image & tag helpers",
 			overwriteValues: map[interface{}]interface{}{
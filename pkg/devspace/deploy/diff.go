@@ -0,0 +1,80 @@
+package deploy
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/diff"
+	helmtypes "github.com/loft-sh/devspace/pkg/devspace/helm/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Diff renders the deployments and writes the changes they would make to the live objects in the
+// cluster to out. Nothing is deployed
+func (c *controller) Diff(options *Options, out io.Writer, log log.Logger) error {
+	config := c.config.Config()
+	if len(config.Deployments) == 0 {
+		return nil
+	}
+
+	graph, err := newDeploymentGraph(filterDeployments(config.Deployments, options.Deployments))
+	if err != nil {
+		return err
+	}
+
+	helmV2Clients := map[string]helmtypes.Client{}
+	cluster := diff.NewCluster(c.client.KubeClient())
+	for _, name := range graph.order {
+		deployConfig, err := getDeployConfig(config.Deployments, name)
+		if err != nil {
+			return err
+		}
+
+		deployClient, err := c.getDeployClient(deployConfig, helmV2Clients, log)
+		if err != nil {
+			return err
+		}
+
+		manifests := &bytes.Buffer{}
+		err = deployClient.Render(options.BuiltImages, manifests)
+		if err != nil {
+			return errors.Errorf("error rendering %s: %v", deployConfig.Name, err)
+		}
+
+		rendered, err := diff.ParseObjects(manifests.String())
+		if err != nil {
+			return errors.Wrapf(err, "parse rendered manifests of %s", deployConfig.Name)
+		}
+
+		namespace := deployConfig.Namespace
+		if namespace == "" {
+			namespace = c.client.Namespace()
+		}
+
+		// The objects of the deployed helm release or the objects applied by the last kubectl
+		// deployment are removed if they are not rendered anymore
+		var previous []*unstructured.Unstructured
+		if deployConfig.Kubectl != nil {
+			if deployCache, ok := c.config.Generated().GetActive().Deployments[deployConfig.Name]; ok {
+				previous = kubectl.InventoryObjects(deployCache)
+			}
+		} else if deployConfig.Helm != nil && !deployConfig.Helm.V2 {
+			previous, err = diff.HelmReleaseObjects(c.client.KubeClient(), deployConfig.Name, namespace)
+			if err != nil {
+				return err
+			}
+		}
+
+		summary, err := diff.Diff(rendered, previous, namespace, cluster, out)
+		if err != nil {
+			return errors.Wrapf(err, "diff %s", deployConfig.Name)
+		}
+
+		log.Infof("Deployment %s: %d added, %d changed, %d removed, %d unchanged", deployConfig.Name, len(summary.Added), len(summary.Changed), len(summary.Removed), len(summary.Unchanged))
+	}
+
+	return nil
+}
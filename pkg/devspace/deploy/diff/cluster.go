@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Cluster retrieves the live objects from the cluster
+type Cluster interface {
+	// IsNamespaced returns true if objects of the given kind are namespaced. Kinds that are unknown
+	// to the cluster are treated as namespaced
+	IsNamespaced(gvk schema.GroupVersionKind) (bool, error)
+
+	// Get returns the live object or nil if it does not exist
+	Get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error)
+}
+
+type cluster struct {
+	client kubernetes.Interface
+
+	// resources caches the resources of the api group versions
+	resources map[string][]metav1.APIResource
+}
+
+// NewCluster creates a cluster that retrieves the live objects with the given client
+func NewCluster(client kubernetes.Interface) Cluster {
+	return &cluster{
+		client:    client,
+		resources: map[string][]metav1.APIResource{},
+	}
+}
+
+// IsNamespaced implements interface
+func (c *cluster) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	resource, err := c.getResource(gvk)
+	if err != nil {
+		return false, err
+	} else if resource == nil {
+		return true, nil
+	}
+
+	return resource.Namespaced, nil
+}
+
+// Get implements interface
+func (c *cluster) Get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	resource, err := c.getResource(gvk)
+	if err != nil {
+		return nil, err
+	} else if resource == nil {
+		// the kind does not exist in the cluster, so there cannot be an object of it
+		return nil, nil
+	}
+
+	path := apiPath(gvk)
+	if resource.Namespaced {
+		path += "/namespaces/" + namespace
+	}
+	path += "/" + resource.Name + "/" + name
+
+	out, err := c.client.Discovery().RESTClient().Get().AbsPath(path).DoRaw(context.TODO())
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "get %s %s", resource.Name, name)
+	}
+
+	obj := &unstructured.Unstructured{}
+	err = json.Unmarshal(out, &obj.Object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode %s %s", resource.Name, name)
+	}
+
+	return obj, nil
+}
+
+func (c *cluster) getResource(gvk schema.GroupVersionKind) (*metav1.APIResource, error) {
+	groupVersion := gvk.GroupVersion().String()
+	resources, ok := c.resources[groupVersion]
+	if !ok {
+		list, err := c.client.Discovery().ServerResourcesForGroupVersion(groupVersion)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "get resources of %s", groupVersion)
+		} else if list != nil {
+			resources = list.APIResources
+		}
+
+		c.resources[groupVersion] = resources
+	}
+
+	for i, resource := range resources {
+		// skip subresources
+		if resource.Kind == gvk.Kind && !strings.Contains(resource.Name, "/") {
+			return &resources[i], nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,285 @@
+package diff
+
+import (
+	"encoding/base64"
+	"io"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The values of secrets are replaced with these masks. If a value changed, the masks before and after are used
+const (
+	secretMask       = "***"
+	secretMaskBefore = "*** (before)"
+	secretMaskAfter  = "*** (after)"
+)
+
+// metadataFields are fields of the metadata of live objects that are managed by the cluster
+var metadataFields = []string{"managedFields", "uid", "resourceVersion", "generation", "creationTimestamp", "selfLink"}
+
+// annotations are annotations of live objects that are managed by kubectl or the cluster
+var annotations = []string{"kubectl.kubernetes.io/last-applied-configuration", "deployment.kubernetes.io/revision"}
+
+// Summary contains the keys of the compared objects
+type Summary struct {
+	Added     []string
+	Changed   []string
+	Removed   []string
+	Unchanged []string
+}
+
+// Diff compares the rendered objects with the live objects in the cluster and writes a unified diff of every
+// object that would be added, changed or removed to out. Previous are the objects of the last deployment, which
+// are reported as removed if they are not rendered anymore. Only the fields that are set in the rendered objects are
+// compared, so fields that are defaulted by the cluster are not reported. The values of secrets are masked
+func Diff(rendered, previous []*unstructured.Unstructured, namespace string, cluster Cluster, out io.Writer) (*Summary, error) {
+	summary := &Summary{}
+	seen := map[objectKey]bool{}
+	for _, obj := range rendered {
+		obj = obj.DeepCopy()
+		err := setNamespace(obj, namespace, cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		key := keyOf(obj)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		live, err := cluster.Get(obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		if err != nil {
+			return nil, err
+		}
+
+		renderedObj := normalizeRendered(obj.Object)
+		if live == nil {
+			_, err = writeDiff(out, key, nil, renderedObj)
+			if err != nil {
+				return nil, err
+			}
+
+			summary.Added = append(summary.Added, key.String())
+			continue
+		}
+
+		liveObj, _ := project(cleanLive(live.Object), renderedObj).(map[string]interface{})
+		changed, err := writeDiff(out, key, liveObj, renderedObj)
+		if err != nil {
+			return nil, err
+		} else if changed {
+			summary.Changed = append(summary.Changed, key.String())
+		} else {
+			summary.Unchanged = append(summary.Unchanged, key.String())
+		}
+	}
+
+	for _, obj := range previous {
+		obj = obj.DeepCopy()
+		err := setNamespace(obj, namespace, cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		key := keyOf(obj)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// objects that do not exist anymore do not have to be removed
+		live, err := cluster.Get(obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		if err != nil {
+			return nil, err
+		} else if live == nil {
+			continue
+		}
+
+		_, err = writeDiff(out, key, cleanLive(live.Object), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Removed = append(summary.Removed, key.String())
+	}
+
+	return summary, nil
+}
+
+// writeDiff writes the unified diff of the object to out and returns true if the object changed. A nil object
+// means that the object does not exist
+func writeDiff(out io.Writer, key objectKey, live, rendered map[string]interface{}) (bool, error) {
+	maskSecret(live, rendered)
+
+	from, err := toYaml(live)
+	if err != nil {
+		return false, err
+	}
+	to, err := toYaml(rendered)
+	if err != nil {
+		return false, err
+	}
+
+	diff := unifiedDiff("live/"+key.String(), "rendered/"+key.String(), from, to)
+	if diff == "" {
+		return false, nil
+	}
+
+	_, err = out.Write([]byte(diff))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func toYaml(obj map[string]interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal object")
+	}
+
+	return string(out), nil
+}
+
+// cleanLive removes the fields of a live object that are managed by the cluster
+func cleanLive(obj map[string]interface{}) map[string]interface{} {
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, field := range metadataFields {
+			delete(metadata, field)
+		}
+
+		if objAnnotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			for _, annotation := range annotations {
+				delete(objAnnotations, annotation)
+			}
+			if len(objAnnotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+
+	return obj
+}
+
+// normalizeRendered removes empty values and the status of a rendered object, which are not stored by the
+// cluster. The string data of secrets is moved into the data, as the cluster does it
+func normalizeRendered(obj map[string]interface{}) map[string]interface{} {
+	obj, _ = removeNulls(obj).(map[string]interface{})
+	delete(obj, "status")
+
+	if isSecret(obj) {
+		if stringData, ok := obj["stringData"].(map[string]interface{}); ok {
+			data, ok := obj["data"].(map[string]interface{})
+			if !ok {
+				data = map[string]interface{}{}
+			}
+			for key, value := range stringData {
+				if str, ok := value.(string); ok {
+					data[key] = base64.StdEncoding.EncodeToString([]byte(str))
+				}
+			}
+
+			obj["data"] = data
+			delete(obj, "stringData")
+		}
+	}
+
+	return obj
+}
+
+func removeNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if child == nil {
+				delete(v, key)
+			} else {
+				v[key] = removeNulls(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = removeNulls(child)
+		}
+	}
+
+	return value
+}
+
+// project returns the parts of the live value that are set in the rendered value
+func project(live, rendered interface{}) interface{} {
+	switch r := rendered.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+
+		projected := map[string]interface{}{}
+		for key, renderedValue := range r {
+			if liveValue, ok := l[key]; ok {
+				projected[key] = project(liveValue, renderedValue)
+			}
+		}
+
+		return projected
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+
+		projected := make([]interface{}, 0, len(l))
+		for i, liveValue := range l {
+			if i < len(r) {
+				projected = append(projected, project(liveValue, r[i]))
+			} else {
+				projected = append(projected, liveValue)
+			}
+		}
+
+		return projected
+	}
+
+	return live
+}
+
+// maskSecret replaces the values of secrets, so they are not printed
+func maskSecret(live, rendered map[string]interface{}) {
+	if !isSecret(live) && !isSecret(rendered) {
+		return
+	}
+
+	liveData, _ := live["data"].(map[string]interface{})
+	renderedData, _ := rendered["data"].(map[string]interface{})
+	for key, liveValue := range liveData {
+		if renderedValue, ok := renderedData[key]; ok && !reflect.DeepEqual(liveValue, renderedValue) {
+			liveData[key] = secretMaskBefore
+			renderedData[key] = secretMaskAfter
+		} else {
+			liveData[key] = secretMask
+			if ok {
+				renderedData[key] = secretMask
+			}
+		}
+	}
+	for key := range renderedData {
+		if _, ok := liveData[key]; !ok {
+			renderedData[key] = secretMask
+		}
+	}
+}
+
+func isSecret(obj map[string]interface{}) bool {
+	return obj != nil && obj["apiVersion"] == "v1" && obj["kind"] == "Secret"
+}
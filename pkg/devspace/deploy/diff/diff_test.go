@@ -0,0 +1,273 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"gotest.tools/assert"
+)
+
+type fakeCluster struct {
+	objects []*unstructured.Unstructured
+}
+
+func (f *fakeCluster) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	return gvk.Kind != "Namespace" && gvk.Kind != "ClusterRole", nil
+}
+
+func (f *fakeCluster) Get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	for _, obj := range f.objects {
+		if obj.GroupVersionKind() == gvk && obj.GetNamespace() == namespace && obj.GetName() == name {
+			return obj.DeepCopy(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func mustParse(t *testing.T, manifests string) []*unstructured.Unstructured {
+	objs, err := ParseObjects(manifests)
+	assert.NilError(t, err)
+	return objs
+}
+
+const liveObjects = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: default
+  uid: 5d1e2d6c
+  resourceVersion: "1234"
+  generation: 3
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+spec:
+  replicas: 1
+  progressDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - name: backend
+        image: john/backend:abc
+        imagePullPolicy: IfNotPresent
+      dnsPolicy: ClusterFirst
+status:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  key: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: default
+data:
+  password: b2xkLXBhc3N3b3Jk
+  user: YWRtaW4=
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: old-service
+  namespace: default
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+rules: []
+`
+
+const renderedObjects = `# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  creationTimestamp: null
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: backend
+        image: john/backend:abc
+status: {}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+stringData:
+  password: new-password
+  user: admin
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new-config
+  namespace: other
+data:
+  key: value
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+  namespace: default
+rules: []
+`
+
+func TestDiff(t *testing.T) {
+	cluster := &fakeCluster{objects: mustParse(t, liveObjects)}
+	previous := mustParse(t, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: old-service
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: already-deleted
+`)
+
+	out := &bytes.Buffer{}
+	summary, err := Diff(mustParse(t, renderedObjects), previous, "default", cluster, out)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, summary, &Summary{
+		Added:     []string{"ConfigMap/other/new-config"},
+		Changed:   []string{"Deployment.apps/default/backend", "Secret/default/credentials"},
+		Removed:   []string{"Service/default/old-service"},
+		Unchanged: []string{"ConfigMap/default/config", "ClusterRole.rbac.authorization.k8s.io/reader"},
+	})
+
+	expected := `--- live/Deployment.apps/default/backend
++++ rendered/Deployment.apps/default/backend
+@@ -4,7 +4,7 @@
+   name: backend
+   namespace: default
+ spec:
+-  replicas: 1
++  replicas: 2
+   template:
+     spec:
+       containers:
+--- live/Secret/default/credentials
++++ rendered/Secret/default/credentials
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  password: '*** (before)'
++  password: '*** (after)'
+   user: '***'
+ kind: Secret
+ metadata:
+--- live/ConfigMap/other/new-config
++++ rendered/ConfigMap/other/new-config
+@@ -0,0 +1,7 @@
++apiVersion: v1
++data:
++  key: value
++kind: ConfigMap
++metadata:
++  name: new-config
++  namespace: other
+--- live/Service/default/old-service
++++ rendered/Service/default/old-service
+@@ -1,8 +0,0 @@
+-apiVersion: v1
+-kind: Service
+-metadata:
+-  name: old-service
+-  namespace: default
+-spec:
+-  ports:
+-  - port: 80
+`
+	assert.Equal(t, out.String(), expected)
+
+	// Secret values are never printed
+	for _, value := range []string{"new-password", "bmV3LXBhc3N3b3Jk", "b2xkLXBhc3N3b3Jk", "YWRtaW4="} {
+		assert.Assert(t, !strings.Contains(out.String(), value), "Secret value %s was printed", value)
+	}
+}
+
+func TestParseObjects(t *testing.T) {
+	objs, err := ParseObjects(`---
+# only a comment
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: third
+`)
+	assert.NilError(t, err)
+
+	names := []string{}
+	for _, obj := range objs {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	assert.DeepEqual(t, names, []string{"ConfigMap/first", "ConfigMap/second", "Service/third"})
+
+	_, err = ParseObjects("apiVersion: v1\nkind: ConfigMap\n")
+	assert.ErrorContains(t, err, "missing kind or metadata.name")
+
+	_, err = ParseObjects("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n---\napiVersion: v1\nkind: Secret\nstringData:\n  password: topsecret\n")
+	assert.ErrorContains(t, err, "manifest document 2 is missing kind or metadata.name")
+	assert.Assert(t, !strings.Contains(err.Error(), "topsecret"), "error contains the manifest: %v", err)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+
+	assert.Equal(t, unifiedDiff("from", "to", from, from), "")
+	assert.Equal(t, unifiedDiff("from", "to", from, to), `--- from
++++ to
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+`)
+}
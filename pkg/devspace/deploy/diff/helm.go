@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// gzipMagic are the first bytes of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// helmRelease is the part of a helm 3 release that contains the deployed manifests
+type helmRelease struct {
+	Manifest string `json:"manifest"`
+}
+
+// HelmReleaseObjects returns the objects of the deployed revision of a helm 3 release. The release is read
+// from the secret helm stores it in. If the release was not deployed yet, no objects are returned
+func HelmReleaseObjects(client kubernetes.Interface, releaseName, namespace string) ([]*unstructured.Unstructured, error) {
+	secrets, err := client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "owner=helm,status=deployed,name=" + releaseName,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "list secrets of release %s", releaseName)
+	} else if len(secrets.Items) == 0 {
+		return nil, nil
+	}
+
+	// helm stores the release as base64 encoded and gzip compressed json
+	data, err := base64.StdEncoding.DecodeString(string(secrets.Items[0].Data["release"]))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode release %s", releaseName)
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompress release %s", releaseName)
+		}
+		defer reader.Close()
+
+		data, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompress release %s", releaseName)
+		}
+	}
+
+	release := &helmRelease{}
+	err = json.Unmarshal(data, release)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode release %s", releaseName)
+	}
+
+	return ParseObjects(release.Manifest)
+}
@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHelmReleaseObjects(t *testing.T) {
+	release, err := json.Marshal(&helmRelease{
+		Manifest: "---\n# Source: chart/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n",
+	})
+	assert.NilError(t, err)
+
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	_, err = writer.Write(release)
+	assert.NilError(t, err)
+	assert.NilError(t, writer.Close())
+
+	client := fake.NewSimpleClientset(&k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.backend.v2",
+			Namespace: "default",
+			Labels: map[string]string{
+				"owner":  "helm",
+				"name":   "backend",
+				"status": "deployed",
+			},
+		},
+		Data: map[string][]byte{
+			"release": []byte(base64.StdEncoding.EncodeToString(compressed.Bytes())),
+		},
+	})
+
+	objs, err := HelmReleaseObjects(client, "backend", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, len(objs), 1)
+	assert.Equal(t, objs[0].GetKind(), "Service")
+	assert.Equal(t, objs[0].GetName(), "backend")
+
+	// Releases that were not deployed yet have no objects
+	objs, err = HelmReleaseObjects(client, "frontend", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, len(objs), 0)
+}
@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+
+// ParseObjects splits the rendered manifests into objects. Lists are expanded into their items.
+// Errors only reference the document by its index, because manifests may contain secrets
+func ParseObjects(manifests string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+	for i, document := range documentSeparator.Split(manifests, -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		objMap := map[string]interface{}{}
+		err := yaml.Unmarshal([]byte(document), &objMap)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal manifest document %d", i+1)
+		} else if len(objMap) == 0 {
+			// the document only contains comments
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: objMap}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, errors.Wrapf(err, "convert list in manifest document %d", i+1)
+			}
+
+			for j := range list.Items {
+				objs = append(objs, &list.Items[j])
+			}
+			continue
+		}
+
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, errors.Errorf("manifest document %d is missing kind or metadata.name (kind: %q, name: %q)", i+1, obj.GetKind(), obj.GetName())
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// objectKey identifies an object independent of the version of its api group
+type objectKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+func keyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{
+		group:     obj.GroupVersionKind().Group,
+		kind:      obj.GetKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// String returns the key as kind/namespace/name or kind/name for cluster scoped objects
+func (k objectKey) String() string {
+	kind := k.kind
+	if k.group != "" {
+		kind += "." + k.group
+	}
+	if k.namespace == "" {
+		return kind + "/" + k.name
+	}
+
+	return kind + "/" + k.namespace + "/" + k.name
+}
+
+// setNamespace sets the namespace of namespaced objects without a namespace to the default namespace and
+// removes the namespace of cluster scoped objects
+func setNamespace(obj *unstructured.Unstructured, defaultNamespace string, cluster Cluster) error {
+	namespaced, err := cluster.IsNamespaced(obj.GroupVersionKind())
+	if err != nil {
+		return errors.Wrapf(err, "get resource of %s", obj.GroupVersionKind().String())
+	}
+
+	if !namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+
+	return nil
+}
+
+// apiPath returns the path of the api group version of the kind
+func apiPath(gvk schema.GroupVersionKind) string {
+	if gvk.Group == "" {
+		return "/api/" + gvk.Version
+	}
+
+	return "/apis/" + gvk.Group + "/" + gvk.Version
+}
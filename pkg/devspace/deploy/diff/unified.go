@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the amount of unchanged lines that are printed around a change
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// unifiedDiff returns the unified diff between the two texts or an empty string if they are equal
+func unifiedDiff(fromName, toName, from, to string) string {
+	lines := diffLines(from, to)
+	changes := []int{}
+	for i, line := range lines {
+		if line.op != diffmatchpatch.DiffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// fromLine and toLine are the amount of lines of the texts before a diff line
+	fromLine := make([]int, len(lines)+1)
+	toLine := make([]int, len(lines)+1)
+	for i, line := range lines {
+		fromLine[i+1] = fromLine[i]
+		toLine[i+1] = toLine[i]
+		if line.op != diffmatchpatch.DiffInsert {
+			fromLine[i+1]++
+		}
+		if line.op != diffmatchpatch.DiffDelete {
+			toLine[i+1]++
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(changes); {
+		// changes that are close to each other are printed in the same hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j]-1 <= 2*diffContext {
+			j++
+		}
+
+		start := changes[i] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(fromLine[start], fromLine[end]-fromLine[start]), hunkRange(toLine[start], toLine[end]-toLine[start]))
+		for _, line := range lines[start:end] {
+			switch line.op {
+			case diffmatchpatch.DiffDelete:
+				buf.WriteString("-" + line.text + "\n")
+			case diffmatchpatch.DiffInsert:
+				buf.WriteString("+" + line.text + "\n")
+			default:
+				buf.WriteString(" " + line.text + "\n")
+			}
+		}
+
+		i = j + 1
+	}
+
+	return buf.String()
+}
+
+// hunkRange formats the start and the length of a hunk like diff -u
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// diffLines compares the texts line by line
+func diffLines(from, to string) []diffLine {
+	dmp := diffmatchpatch.New()
+	fromChars, toChars, lineArray := dmp.DiffLinesToChars(from, to)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(fromChars, toChars, false), lineArray)
+
+	lines := []diffLine{}
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text == "" {
+				continue
+			}
+
+			lines = append(lines, diffLine{
+				op:   d.Type,
+				text: strings.TrimSuffix(text, "\n"),
+			})
+		}
+	}
+
+	return lines
+}
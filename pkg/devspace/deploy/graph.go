@@ -0,0 +1,132 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/pkg/errors"
+)
+
+// deploymentGraph holds the dependencies between the deployments that should be deployed
+type deploymentGraph struct {
+	// order are the deployment names in an order where every deployment comes after the deployments
+	// it depends on. Deployments without dependencies keep the order of the config
+	order []string
+
+	// dependencies maps a deployment name to the deployment names it depends on
+	dependencies map[string][]string
+}
+
+// cyclicError is returned if the deployments depend on each other
+type cyclicError struct {
+	path []string
+}
+
+// Error implements error interface
+func (c *cyclicError) Error() string {
+	return fmt.Sprintf("Cyclic dependency found: \n%s", strings.Join(c.path, "\n"))
+}
+
+// newDeploymentGraph resolves the dependsOn of the given deployments. Dependencies on deployments that
+// are not part of the given deployments are ignored, so a subset of the deployments can be deployed
+func newDeploymentGraph(deployments []*latest.DeploymentConfig) (*deploymentGraph, error) {
+	graph := &deploymentGraph{
+		dependencies: map[string][]string{},
+	}
+
+	names := []string{}
+	for _, deployConfig := range deployments {
+		names = append(names, deployConfig.Name)
+		graph.dependencies[deployConfig.Name] = []string{}
+	}
+
+	for _, deployConfig := range deployments {
+		for _, dependency := range deployConfig.DependsOn {
+			if _, ok := graph.dependencies[dependency]; ok {
+				graph.dependencies[deployConfig.Name] = append(graph.dependencies[deployConfig.Name], dependency)
+			}
+		}
+	}
+
+	var (
+		visited = map[string]bool{}
+		visit   func(name string, path []string) error
+	)
+	visit = func(name string, path []string) error {
+		if visited[name] {
+			return nil
+		}
+		for i, parent := range path {
+			if parent == name {
+				return &cyclicError{
+					path: append(append([]string{}, path[i:]...), name),
+				}
+			}
+		}
+
+		for _, dependency := range graph.dependencies[name] {
+			err := visit(dependency, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+
+		visited[name] = true
+		graph.order = append(graph.order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return graph, nil
+}
+
+// declaresDependsOn returns true if any of the deployments declares the deployments it depends on
+func declaresDependsOn(deployments []*latest.DeploymentConfig) bool {
+	for _, deployConfig := range deployments {
+		if len(deployConfig.DependsOn) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ready returns true if all dependencies of the deployment are finished
+func (g *deploymentGraph) ready(name string, finished map[string]bool) bool {
+	for _, dependency := range g.dependencies[name] {
+		if !finished[dependency] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reverseOrder returns the deployments in an order where every deployment comes before the
+// deployments it depends on
+func (g *deploymentGraph) reverseOrder() []string {
+	order := make([]string, 0, len(g.order))
+	for i := len(g.order) - 1; i >= 0; i-- {
+		order = append(order, g.order[i])
+	}
+
+	return order
+}
+
+// getDeployConfig returns the deployment with the given name
+func getDeployConfig(deployments []*latest.DeploymentConfig, name string) (*latest.DeploymentConfig, error) {
+	for _, deployConfig := range deployments {
+		if deployConfig.Name == name {
+			return deployConfig, nil
+		}
+	}
+
+	return nil, errors.Errorf("couldn't find deployment %s", name)
+}
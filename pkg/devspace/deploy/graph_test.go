@@ -0,0 +1,98 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+
+	"gotest.tools/assert"
+)
+
+type deploymentGraphTestCase struct {
+	name string
+
+	deployments []*latest.DeploymentConfig
+
+	expectedOrder        []string
+	expectedReverseOrder []string
+	expectedErr          string
+}
+
+func TestDeploymentGraph(t *testing.T) {
+	testCases := []deploymentGraphTestCase{
+		{
+			name: "Keep config order",
+			deployments: []*latest.DeploymentConfig{
+				{Name: "b"},
+				{Name: "a"},
+				{Name: "c"},
+			},
+			expectedOrder:        []string{"b", "a", "c"},
+			expectedReverseOrder: []string{"c", "a", "b"},
+		},
+		{
+			name: "Deploy dependencies first",
+			deployments: []*latest.DeploymentConfig{
+				{Name: "backend", DependsOn: []string{"database", "cache"}},
+				{Name: "frontend", DependsOn: []string{"backend"}},
+				{Name: "database"},
+				{Name: "cache"},
+			},
+			expectedOrder:        []string{"database", "cache", "backend", "frontend"},
+			expectedReverseOrder: []string{"frontend", "backend", "cache", "database"},
+		},
+		{
+			name: "Ignore dependencies that are not deployed",
+			deployments: []*latest.DeploymentConfig{
+				{Name: "backend", DependsOn: []string{"database"}},
+			},
+			expectedOrder:        []string{"backend"},
+			expectedReverseOrder: []string{"backend"},
+		},
+		{
+			name: "Cyclic dependency",
+			deployments: []*latest.DeploymentConfig{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+			expectedErr: "Cyclic dependency found: \na\nb\nc\na",
+		},
+	}
+
+	for _, testCase := range testCases {
+		graph, err := newDeploymentGraph(testCase.deployments)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, "Wrong or no error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.DeepEqual(t, graph.order, testCase.expectedOrder)
+		assert.DeepEqual(t, graph.reverseOrder(), testCase.expectedReverseOrder)
+	}
+}
+
+func TestDeploymentGraphReady(t *testing.T) {
+	graph, err := newDeploymentGraph([]*latest.DeploymentConfig{
+		{Name: "backend", DependsOn: []string{"database", "cache"}},
+		{Name: "database"},
+		{Name: "cache"},
+	})
+	assert.NilError(t, err)
+
+	finished := map[string]bool{}
+	assert.Equal(t, graph.ready("database", finished), true)
+	assert.Equal(t, graph.ready("backend", finished), false)
+
+	finished["database"] = true
+	assert.Equal(t, graph.ready("backend", finished), false)
+
+	finished["cache"] = true
+	assert.Equal(t, graph.ready("backend", finished), true)
+}
+
+func TestDeclaresDependsOn(t *testing.T) {
+	assert.Equal(t, declaresDependsOn([]*latest.DeploymentConfig{{Name: "crds"}, {Name: "backend"}}), false)
+	assert.Equal(t, declaresDependsOn([]*latest.DeploymentConfig{{Name: "crds"}, {Name: "backend", DependsOn: []string{"crds"}}}), true)
+}
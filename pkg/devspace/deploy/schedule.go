@@ -0,0 +1,195 @@
+package deploy
+
+import (
+	"io"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	helmtypes "github.com/loft-sh/devspace/pkg/devspace/helm/types"
+	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/scanner"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// deployState tracks the progress of the deployments of a single Deploy call
+type deployState struct {
+	graph *deploymentGraph
+
+	// finished are the deployments that were deployed or skipped
+	finished map[string]bool
+
+	// running is the amount of deployments that are currently deployed in parallel
+	running int
+	started int
+
+	errChan  chan error
+	doneChan chan string
+}
+
+// filterDeployments returns the deployments with the given names or all deployments if no names are given
+func filterDeployments(deployments []*latest.DeploymentConfig, names []string) []*latest.DeploymentConfig {
+	if len(names) == 0 {
+		return deployments
+	}
+
+	filtered := []*latest.DeploymentConfig{}
+	for _, deployConfig := range deployments {
+		for _, name := range names {
+			if name == strings.TrimSpace(deployConfig.Name) {
+				filtered = append(filtered, deployConfig)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// startDeployment deploys a single deployment. If the deployments are not deployed sequentially, the
+// deployment is deployed in the background and the result is sent to the channels of the deploy state
+func (c *controller) startDeployment(deployConfig *latest.DeploymentConfig, state *deployState, helmV2Clients map[string]helmtypes.Client, sequential bool, options *Options, log logpkg.Logger) error {
+	if sequential {
+		deployClient, method, err := c.newDeployer(deployConfig, helmV2Clients, options, log)
+		if err != nil {
+			return err
+		}
+
+		err = c.deployOne(deployConfig, deployClient, method, options, log)
+		if err != nil {
+			return err
+		}
+
+		state.finished[deployConfig.Name] = true
+		return nil
+	}
+
+	// Create a string log
+	reader, writer := io.Pipe()
+	streamLog := logpkg.NewStreamLogger(writer, logrus.InfoLevel)
+	color := logpkg.Colors[(len(logpkg.Colors)-1)-(state.started%len(logpkg.Colors))]
+	logsLog := logpkg.NewPrefixLogger("["+deployConfig.Name+"] ", color, log)
+
+	// read from the reader
+	go func() {
+		scanner := scanner.NewScanner(reader)
+		for scanner.Scan() {
+			logsLog.Info(scanner.Text())
+		}
+	}()
+
+	// The deployer is created here, because the helm clients are shared between the deployments.
+	// The deployment cache is created here as well, so the parallel deployments only read the cache map
+	deployClient, method, err := c.newDeployer(deployConfig, helmV2Clients, options, streamLog)
+	if err != nil {
+		_ = writer.Close()
+		return err
+	}
+	c.config.Generated().GetActive().GetDeploymentCache(deployConfig.Name)
+
+	state.running++
+	state.started++
+	go func() {
+		err := c.deployOne(deployConfig, deployClient, method, options, streamLog)
+		_ = writer.Close()
+		if err != nil {
+			state.errChan <- err
+			return
+		}
+
+		state.doneChan <- deployConfig.Name
+	}()
+
+	return nil
+}
+
+// waitForDeployment waits until one of the parallel deployments is done
+func (c *controller) waitForDeployment(state *deployState) error {
+	select {
+	case err := <-state.errChan:
+		state.running--
+		return err
+	case name := <-state.doneChan:
+		state.running--
+		state.finished[name] = true
+	}
+
+	return nil
+}
+
+// newDeployer creates the deployer of the deployment and returns it together with the name of the deployment method
+func (c *controller) newDeployer(deployConfig *latest.DeploymentConfig, helmV2Clients map[string]helmtypes.Client, options *Options, log logpkg.Logger) (deployer.Interface, string, error) {
+	if deployConfig.Kubectl != nil {
+		deployClient, err := kubectl.New(c.config, c.dependencies, c.client, deployConfig, log)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		deployClient.(*kubectl.DeployConfig).NoPrune = options.NoPrune
+
+		return deployClient, "kubectl", nil
+	} else if deployConfig.Helm != nil {
+		// Get helm client
+		helmClient, err := GetCachedHelmClient(c.config.Config(), deployConfig, c.client, helmV2Clients, false, log)
+		if err != nil {
+			return nil, "", err
+		}
+
+		deployClient, err := helm.New(c.config, c.dependencies, helmClient, c.client, deployConfig, log)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		return deployClient, "helm", nil
+	}
+
+	return nil, "", errors.Errorf("error deploying: deployment %s has no deployment method", deployConfig.Name)
+}
+
+// deployOne deploys a single deployment and executes its hooks
+func (c *controller) deployOne(deployConfig *latest.DeploymentConfig, deployClient deployer.Interface, method string, options *Options, log logpkg.Logger) error {
+	// Execute before deployment deploy hook
+	err := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+		"DEPLOY_NAME":   deployConfig.Name,
+		"DEPLOY_CONFIG": deployConfig,
+	}, log, hook.EventsForSingle("before:deploy", deployConfig.Name).With("deploy.beforeDeploy")...)
+	if err != nil {
+		return err
+	}
+
+	wasDeployed, err := deployClient.Deploy(options.ForceDeploy, options.BuiltImages)
+	if err != nil {
+		hookErr := hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+			"DEPLOY_NAME":   deployConfig.Name,
+			"DEPLOY_CONFIG": deployConfig,
+			"ERROR":         err,
+		}, log, hook.EventsForSingle("error:deploy", deployConfig.Name).With("deploy.errorDeploy")...)
+		if hookErr != nil {
+			return hookErr
+		}
+
+		return errors.Errorf("error deploying %s: %v", deployConfig.Name, err)
+	}
+
+	if wasDeployed {
+		log.Donef("Successfully deployed %s with %s", deployConfig.Name, method)
+
+		// Execute after deployment deploy hook
+		return hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+			"DEPLOY_NAME":   deployConfig.Name,
+			"DEPLOY_CONFIG": deployConfig,
+		}, log, hook.EventsForSingle("after:deploy", deployConfig.Name).With("deploy.afterDeploy")...)
+	}
+
+	log.Infof("Skipping deployment %s", deployConfig.Name)
+
+	// Execute after deployment deploy hook
+	return hook.ExecuteHooks(c.client, c.config, c.dependencies, map[string]interface{}{
+		"DEPLOY_NAME":   deployConfig.Name,
+		"DEPLOY_CONFIG": deployConfig,
+	}, log, hook.EventsForSingle("skip:deploy", deployConfig.Name)...)
+}
@@ -9,7 +9,10 @@ import (
 )
 
 // FakeController is the fake build controller
-type FakeController struct{}
+type FakeController struct {
+	// Err is returned by Deploy
+	Err error
+}
 
 // NewFakeController creates a new fake build controller
 func NewFakeController(config *latest.Config) deploy.Controller {
@@ -18,7 +21,7 @@ func NewFakeController(config *latest.Config) deploy.Controller {
 
 // Deploy deploys the deployments
 func (f *FakeController) Deploy(options *deploy.Options, log log.Logger) error {
-	return nil
+	return f.Err
 }
 
 // Render implements interface
@@ -26,6 +29,11 @@ func (f *FakeController) Render(options *deploy.Options, out io.Writer, log log.
 	return nil
 }
 
+// Diff implements interface
+func (f *FakeController) Diff(options *deploy.Options, out io.Writer, log log.Logger) error {
+	return nil
+}
+
 // Purge purges the deployments
 func (f *FakeController) Purge(deployments []string, log log.Logger) error {
 	return nil
@@ -0,0 +1,123 @@
+package deploy
+
+import (
+	"context"
+	"sort"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
+	"github.com/loft-sh/devspace/pkg/devspace/signature"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// verifyImages verifies the signatures of all images with a public key that are replaced into the helm values
+// and kubectl manifests of the deployments, including the images of the dependencies. The images are resolved
+// the same way the deployers replace them and are pinned to the verified digest afterwards
+func (c *controller) verifyImages(log log.Logger) error {
+	err := verifyConfigImages(c.config, c.dependencies, log)
+	if err != nil {
+		return err
+	}
+
+	verified := map[string]bool{}
+	dependencies := c.dependencies
+	for len(dependencies) > 0 {
+		children := []types.Dependency{}
+		for _, dependency := range dependencies {
+			if verified[dependency.ID()] {
+				continue
+			}
+
+			verified[dependency.ID()] = true
+			err = verifyConfigImages(dependency.Config(), dependency.Children(), log)
+			if err != nil {
+				return errors.Wrapf(err, "dependency %s", dependency.DependencyConfig().Name)
+			}
+
+			children = append(children, dependency.Children()...)
+		}
+
+		dependencies = children
+	}
+
+	return nil
+}
+
+// verifyConfigImages verifies the signed images of the given config and stores the verified digests in its image cache
+func verifyConfigImages(config config.Config, dependencies []types.Dependency, log log.Logger) error {
+	images := config.Config().Images
+	imageConfigNames := []string{}
+	for imageConfigName, imageConf := range images {
+		if imageConf.Signature != nil && imageConf.Signature.PublicKey != "" {
+			imageConfigNames = append(imageConfigNames, imageConfigName)
+		}
+	}
+	sort.Strings(imageConfigNames)
+
+	for _, imageConfigName := range imageConfigNames {
+		imageConf := images[imageConfigName]
+
+		// resolve the tag and not the digest of a previous verification
+		imageCache := config.Generated().GetActive().GetImageCache(imageConfigName)
+		imageCache.VerifiedTag = ""
+		imageCache.VerifiedDigest = ""
+
+		resolvedImage, err := util.ResolveImage(imageConf.Image, config, dependencies)
+		if err != nil {
+			return errors.Wrapf(err, "resolve image %s", imageConf.Image)
+		}
+
+		image, originalTag, err := imageselector.GetStrippedDockerImageName(resolvedImage)
+		if err != nil {
+			return err
+		}
+
+		tag := originalTag
+		if tag == "" {
+			tag = "latest"
+		}
+
+		publicKey, err := signature.LoadPublicKey(imageConf.Signature.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "load public key of image %s", imageConfigName)
+		}
+
+		registryClient, err := newRegistryClient(image)
+		if err != nil {
+			return errors.Wrapf(err, "create registry client for image %s", image)
+		}
+
+		log.StartWait("Verifying signature of image " + image + ":" + tag)
+		digest, err := signature.Verify(context.Background(), registryClient, image, tag, publicKey)
+		log.StopWait()
+		if err != nil {
+			return errors.Wrapf(err, "verify signature of image %s", imageConfigName)
+		}
+
+		imageCache.VerifiedTag = originalTag
+		imageCache.VerifiedDigest = digest
+		log.Donef("Verified signature of image %s:%s (%s)", image, tag, digest)
+	}
+
+	return nil
+}
+
+// newRegistryClient creates a client for the repository of the image with the credentials of the docker config
+func newRegistryClient(image string) (*docker.RegistryClient, error) {
+	registryURL, err := pullsecrets.GetRegistryFromImageName(image)
+	if err != nil {
+		return nil, err
+	}
+
+	authConfig, err := docker.GetDefaultAuthConfig(registryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return docker.NewRegistryClient(image, authConfig, "pull")
+}
@@ -31,6 +31,16 @@ func (c *client) GetAuthConfig(registryURL string, checkCredentialsStore bool) (
 	return getDefaultAuthConfig(checkCredentialsStore, serverAddress, isDefaultRegistry)
 }
 
+// GetDefaultAuthConfig returns the AuthConfig for a Docker registry from the docker config and the credential helpers
+// without asking the docker daemon for the default registry
+func GetDefaultAuthConfig(registryURL string) (*types.AuthConfig, error) {
+	if registryURL == "" || registryURL == "hub.docker.com" {
+		registryURL = registry.IndexServer
+	}
+
+	return getDefaultAuthConfig(true, registryURL, registryURL == registry.IndexServer)
+}
+
 // Login logs the user into docker
 func (c *client) Login(registryURL, user, password string, checkCredentialsStore, saveAuthConfig, relogin bool) (*types.AuthConfig, error) {
 	ctx := context.Background()
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"io"
 	"os"
 	"os/exec"
@@ -12,7 +13,7 @@ import (
 )
 
 // ImageBuildCLI builds an image with the docker cli
-func (c *client) ImageBuildCLI(useBuildKit bool, context io.Reader, writer io.Writer, additionalArgs []string, options dockertypes.ImageBuildOptions, log log.Logger) error {
+func (c *client) ImageBuildCLI(ctx context.Context, useBuildKit bool, buildContext io.Reader, writer io.Writer, additionalArgs []string, options dockertypes.ImageBuildOptions, log log.Logger) error {
 	args := []string{"build"}
 	if options.BuildArgs != nil {
 		for k, v := range options.BuildArgs {
@@ -26,6 +27,9 @@ func (c *client) ImageBuildCLI(useBuildKit bool, context io.Reader, writer io.Wr
 	if options.NetworkMode != "" {
 		args = append(args, "--network", options.NetworkMode)
 	}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
 	for _, tag := range options.Tags {
 		args = append(args, "--tag", tag)
 	}
@@ -42,7 +46,7 @@ func (c *client) ImageBuildCLI(useBuildKit bool, context io.Reader, writer io.Wr
 	args = append(args, "-")
 
 	log.Infof("Execute docker cli command with: docker %s", strings.Join(args, " "))
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Env = os.Environ()
 	if useBuildKit {
 		cmd.Env = append(cmd.Env, "DOCKER_BUILDKIT=1")
@@ -53,7 +57,7 @@ func (c *client) ImageBuildCLI(useBuildKit bool, context io.Reader, writer io.Wr
 		}
 	}
 
-	cmd.Stdin = context
+	cmd.Stdin = buildContext
 	cmd.Stdout = writer
 	cmd.Stderr = writer
 
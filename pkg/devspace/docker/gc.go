@@ -0,0 +1,168 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// getTagsConcurrency is the amount of tags that are inspected in parallel
+const getTagsConcurrency = 8
+
+// RegistryTag is a tag of an image repository in the registry
+type RegistryTag struct {
+	Tag     string
+	Digest  string
+	Created time.Time
+
+	// Manifests are the digests of the manifests the tag references if it points to a manifest list
+	Manifests []string
+}
+
+// GetRegistryTags returns all tags of the repository together with the digests they point to and
+// the creation times of the images
+func (r *RegistryClient) GetRegistryTags(ctx context.Context) ([]*RegistryTag, error) {
+	tags, err := r.GetTags(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list tags")
+	}
+
+	var (
+		registryTags = make([]*RegistryTag, len(tags))
+		errs         = make([]error, len(tags))
+		work         = make(chan int)
+		waitGroup    sync.WaitGroup
+	)
+
+	for i := 0; i < getTagsConcurrency; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for idx := range work {
+				registryTags[idx], errs[idx] = r.getRegistryTag(ctx, tags[idx])
+			}
+		}()
+	}
+	for idx := range tags {
+		work <- idx
+	}
+	close(work)
+	waitGroup.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "inspect tag %s", tags[idx])
+		}
+	}
+
+	return registryTags, nil
+}
+
+func (r *RegistryClient) getRegistryTag(ctx context.Context, tag string) (*RegistryTag, error) {
+	manifest, err := r.GetManifest(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	registryTag := &RegistryTag{
+		Tag:    tag,
+		Digest: manifest.Digest,
+	}
+
+	// Use the first image of a manifest list for the creation time
+	config := manifest.Config
+	if len(manifest.Manifests) > 0 {
+		for _, child := range manifest.Manifests {
+			registryTag.Manifests = append(registryTag.Manifests, child.Digest)
+		}
+
+		childManifest, err := r.GetManifest(ctx, manifest.Manifests[0].Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		config = childManifest.Config
+	}
+
+	// Old schema1 manifests have no config and are treated as the oldest images
+	if config != "" {
+		registryTag.Created, err = r.GetImageCreated(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return registryTag, nil
+}
+
+// SelectStaleTags returns the tags that can be deleted. The keep most recent tags and the protected tags are kept.
+// Because deleting a tag deletes the manifest it points to, all tags that point to the manifest of a kept tag or to a
+// manifest referenced by a kept manifest list are kept as well. If signatureTag is set, it returns the tag the signatures
+// of a manifest are stored under. Signature tags are not counted as recent tags and are kept together with the manifest
+// they sign. The returned tags are sorted from newest to oldest
+func SelectStaleTags(tags []*RegistryTag, keep int, protectedTags map[string]bool, protectedDigests map[string]bool, signatureTag func(digest string) string) []*RegistryTag {
+	// find the signature tags and the manifests they sign
+	signedDigests := map[string]string{}
+	if signatureTag != nil {
+		for _, tag := range tags {
+			for _, digest := range append([]string{tag.Digest}, tag.Manifests...) {
+				signedDigests[signatureTag(digest)] = digest
+			}
+		}
+	}
+
+	sorted := make([]*RegistryTag, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := signedDigests[tag.Tag]; !ok {
+			sorted = append(sorted, tag)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Created.Equal(sorted[j].Created) {
+			return sorted[i].Tag < sorted[j].Tag
+		}
+
+		return sorted[i].Created.After(sorted[j].Created)
+	})
+
+	keptDigests := map[string]bool{}
+	for digest := range protectedDigests {
+		keptDigests[digest] = true
+	}
+	for idx, tag := range sorted {
+		if idx < keep || protectedTags[tag.Tag] {
+			keptDigests[tag.Digest] = true
+		}
+	}
+	for _, tag := range sorted {
+		if keptDigests[tag.Digest] {
+			for _, manifest := range tag.Manifests {
+				keptDigests[manifest] = true
+			}
+		}
+	}
+
+	// signatures are deleted together with the manifest they sign
+	signatures := []*RegistryTag{}
+	for _, tag := range tags {
+		if signed, ok := signedDigests[tag.Tag]; ok {
+			if keptDigests[signed] {
+				keptDigests[tag.Digest] = true
+			}
+
+			signatures = append(signatures, tag)
+		}
+	}
+
+	stale := []*RegistryTag{}
+	for _, tag := range append(sorted, signatures...) {
+		if !keptDigests[tag.Digest] {
+			stale = append(stale, tag)
+		}
+	}
+
+	return stale
+}
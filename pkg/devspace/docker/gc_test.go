@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestSelectStaleTags(t *testing.T) {
+	now := time.Now()
+	tags := []*RegistryTag{
+		{Tag: "a", Digest: "sha256:a", Created: now.Add(-1 * time.Hour)},
+		{Tag: "b", Digest: "sha256:b", Created: now.Add(-2 * time.Hour)},
+		{Tag: "b-alias", Digest: "sha256:b", Created: now.Add(-2 * time.Hour)},
+		{Tag: "c", Digest: "sha256:c", Created: now.Add(-3 * time.Hour)},
+		{Tag: "d", Digest: "sha256:d", Created: now.Add(-4 * time.Hour)},
+		{Tag: "e", Digest: "sha256:e", Created: now.Add(-5 * time.Hour)},
+		{Tag: "list", Digest: "sha256:list", Created: now.Add(-6 * time.Hour), Manifests: []string{"sha256:list-amd64", "sha256:list-arm64"}},
+		{Tag: "list-linux-amd64", Digest: "sha256:list-amd64", Created: now.Add(-6 * time.Hour)},
+		{Tag: "list-linux-arm64", Digest: "sha256:list-arm64", Created: now.Add(-6 * time.Hour)},
+		{Tag: "old", Digest: "sha256:old"},
+	}
+
+	stale := SelectStaleTags(tags, 2, map[string]bool{"c": true, "list": true}, map[string]bool{"sha256:e": true}, nil)
+	staleTags := []string{}
+	for _, tag := range stale {
+		staleTags = append(staleTags, tag.Tag)
+	}
+
+	// a and b are the most recent, b-alias points to the same manifest as b, c is protected by
+	// tag, e by digest and the platform images are referenced by the protected manifest list
+	assert.DeepEqual(t, staleTags, []string{"d", "old"})
+
+	assert.Equal(t, len(SelectStaleTags(tags, 0, nil, nil, nil)), len(tags))
+	assert.Equal(t, len(SelectStaleTags(tags, 100, nil, nil, nil)), 0)
+}
+
+func TestSelectStaleTagsSignatures(t *testing.T) {
+	signatureTag := func(digest string) string {
+		return strings.Replace(digest, ":", "-", 1) + ".sig"
+	}
+
+	now := time.Now()
+	tags := []*RegistryTag{
+		{Tag: "a", Digest: "sha256:a", Created: now.Add(-1 * time.Hour)},
+		{Tag: "b", Digest: "sha256:b", Created: now.Add(-2 * time.Hour)},
+		{Tag: "c", Digest: "sha256:c", Created: now.Add(-3 * time.Hour)},
+		{Tag: "sha256-a.sig", Digest: "sha256:a-sig"},
+		{Tag: "sha256-b.sig", Digest: "sha256:b-sig"},
+		{Tag: "sha256-c.sig", Digest: "sha256:c-sig"},
+		{Tag: "sha256-gone.sig", Digest: "sha256:gone-sig"},
+	}
+
+	stale := SelectStaleTags(tags, 2, nil, map[string]bool{"sha256:c": true}, signatureTag)
+	staleTags := []string{}
+	for _, tag := range stale {
+		staleTags = append(staleTags, tag.Tag)
+	}
+
+	// the signatures are not counted as recent tags and are kept with the images they sign, the
+	// signature of an image that does not exist anymore is deleted
+	assert.DeepEqual(t, staleTags, []string{"sha256-gone.sig"})
+
+	stale = SelectStaleTags(tags, 1, nil, nil, signatureTag)
+	staleTags = []string{}
+	for _, tag := range stale {
+		staleTags = append(staleTags, tag.Tag)
+	}
+	assert.DeepEqual(t, staleTags, []string{"b", "c", "sha256-gone.sig", "sha256-b.sig", "sha256-c.sig"})
+}
@@ -0,0 +1,656 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	v2 "github.com/docker/distribution/registry/api/v2"
+	registryclient "github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// MediaTypeManifestList is the media type of a docker manifest list
+const MediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+// manifestMediaTypes are the manifest types that are accepted from the registry
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	MediaTypeManifestList,
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v1+prettyjws",
+}
+
+// ManifestDescriptor describes a manifest in the registry
+type ManifestDescriptor struct {
+	MediaType string    `json:"mediaType"`
+	Size      int64     `json:"size"`
+	Digest    string    `json:"digest"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Platform is the platform of a manifest within a manifest list
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// ParsePlatform parses a platform in the form os/arch[/variant]
+func ParsePlatform(platform string) (*Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" || (len(parts) == 3 && parts[2] == "") {
+		return nil, errors.Errorf("invalid platform %s, expected os/arch[/variant]", platform)
+	}
+
+	parsed := &Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+	if len(parts) == 3 {
+		parsed.Variant = parts[2]
+	}
+
+	return parsed, nil
+}
+
+// Manifest is an image manifest or a manifest list in the registry
+type Manifest struct {
+	ManifestDescriptor
+
+	// Config is the digest of the image config. It is empty for manifest lists
+	Config string
+
+	// Manifests are the manifests a manifest list references
+	Manifests []*ManifestDescriptor
+}
+
+type manifestList struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	MediaType     string                `json:"mediaType"`
+	Manifests     []*ManifestDescriptor `json:"manifests"`
+}
+
+// RegistryClient talks to the registry api of a single image repository
+type RegistryClient struct {
+	name       reference.Named
+	urlBuilder *v2.URLBuilder
+	client     *http.Client
+}
+
+// NewRegistryClient creates a client for the registry api of the repository of the given image that
+// authenticates with the given auth config
+func NewRegistryClient(image string, authConfig *types.AuthConfig, actions ...string) (*RegistryClient, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse image %s", image)
+	}
+
+	service, err := registry.NewService(registry.ServiceOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	repoInfo, err := service.ResolveRepository(named)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := service.LookupPullEndpoints(reference.Domain(repoInfo.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// Try the endpoints in order, e.g. insecure registries fall back to http
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if endpoint.Mirror || endpoint.Version != registry.APIVersion2 {
+			continue
+		}
+
+		client, err := newRegistryClient(repoInfo, endpoint, authConfig, actions)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return client, nil
+	}
+
+	if lastErr == nil {
+		lastErr = errors.Errorf("no registry endpoint found for image %s", image)
+	}
+
+	return nil, lastErr
+}
+
+func newRegistryClient(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint, authConfig *types.AuthConfig, actions []string) (*RegistryClient, error) {
+	var (
+		base      = registry.NewTransport(endpoint.TLSConfig)
+		modifiers = registry.Headers("devspace", nil)
+	)
+
+	challengeManager, _, err := registry.PingV2Registry(endpoint.URL, transport.NewTransport(base, modifiers...))
+	if err != nil {
+		return nil, errors.Wrapf(err, "ping registry %s", endpoint.URL.String())
+	}
+
+	var (
+		path         = reference.Path(repoInfo.Name)
+		credentials  = registry.NewStaticCredentialStore(authConfig)
+		tokenHandler = auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+			Transport:   transport.NewTransport(base, modifiers...),
+			Credentials: credentials,
+			Scopes: []auth.Scope{
+				auth.RepositoryScope{
+					Repository: path,
+					Actions:    actions,
+				},
+			},
+			ClientID: "devspace",
+		})
+	)
+
+	modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, auth.NewBasicHandler(credentials)))
+	urlBuilder, err := v2.NewURLBuilderFromString(endpoint.URL.String(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := reference.WithName(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegistryClient{
+		name:       name,
+		urlBuilder: urlBuilder,
+		client:     registry.HTTPClient(transport.NewTransport(base, modifiers...)),
+	}, nil
+}
+
+// TagExists checks if the given tag exists in the repository
+func (r *RegistryClient) TagExists(ctx context.Context, tag string) (bool, error) {
+	resp, err := r.headManifest(ctx, tag)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	} else if registryclient.SuccessStatus(resp.StatusCode) {
+		return true, nil
+	}
+
+	return false, registryclient.HandleErrorResponse(resp)
+}
+
+// GetManifestDescriptor returns the media type, size and digest of the manifest the tag points to
+func (r *RegistryClient) GetManifestDescriptor(ctx context.Context, tag string) (*ManifestDescriptor, error) {
+	resp, err := r.headManifest(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return nil, registryclient.HandleErrorResponse(resp)
+	}
+
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("registry returned an invalid content length for %s:%s", r.name.Name(), tag)
+	}
+
+	contentDigest := resp.Header.Get("Docker-Content-Digest")
+	if contentDigest == "" {
+		return nil, errors.Errorf("registry returned no digest for %s:%s", r.name.Name(), tag)
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if idx := strings.Index(mediaType, ";"); idx != -1 {
+		mediaType = mediaType[:idx]
+	}
+
+	return &ManifestDescriptor{
+		MediaType: mediaType,
+		Size:      size,
+		Digest:    contentDigest,
+	}, nil
+}
+
+// PutManifestList pushes a manifest list that references the given manifests under the given tag. The
+// manifests have to exist in the same repository
+func (r *RegistryClient) PutManifestList(ctx context.Context, tag string, manifests []*ManifestDescriptor) error {
+	out, err := json.Marshal(&manifestList{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifestList,
+		Manifests:     manifests,
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.PutManifest(ctx, tag, MediaTypeManifestList, out)
+}
+
+// PutManifest pushes the given manifest under the given tag. The blobs the manifest references have to
+// exist in the repository
+func (r *RegistryClient) PutManifest(ctx context.Context, tag string, mediaType string, manifest []byte) error {
+	manifestURL, err := r.manifestURL(tag)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, manifestURL, bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return registryclient.HandleErrorResponse(resp)
+	}
+
+	return nil
+}
+
+func (r *RegistryClient) manifestURL(tagOrDigest string) (string, error) {
+	var (
+		ref reference.Named
+		err error
+	)
+	if strings.Contains(tagOrDigest, ":") {
+		ref, err = reference.WithDigest(r.name, digest.Digest(tagOrDigest))
+	} else {
+		ref, err = reference.WithTag(r.name, tagOrDigest)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return r.urlBuilder.BuildManifestURL(ref)
+}
+
+func (r *RegistryClient) headManifest(ctx context.Context, tagOrDigest string) (*http.Response, error) {
+	manifestURL, err := r.manifestURL(tagOrDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	return r.client.Do(req.WithContext(ctx))
+}
+
+// GetTags returns all tags of the repository
+func (r *RegistryClient) GetTags(ctx context.Context) ([]string, error) {
+	tagsURL, err := r.urlBuilder.BuildTagsURL(r.name)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for tagsURL != "" {
+		req, err := http.NewRequest(http.MethodGet, tagsURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := r.client.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		response := struct {
+			Tags []string `json:"tags"`
+		}{}
+		if !registryclient.SuccessStatus(resp.StatusCode) {
+			err = registryclient.HandleErrorResponse(resp)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&response)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, response.Tags...)
+		tagsURL, err = nextLink(tagsURL, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+// nextLink returns the url of the next page from the link header of a paginated response
+func nextLink(current string, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start == -1 || end <= start {
+		return "", errors.Errorf("invalid link header %s", link)
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
+
+// GetManifest returns the manifest the tag or digest points to
+func (r *RegistryClient) GetManifest(ctx context.Context, tagOrDigest string) (*Manifest, error) {
+	manifestURL, err := r.manifestURL(tagOrDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return nil, registryclient.HandleErrorResponse(resp)
+	}
+
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		MediaType string `json:"mediaType"`
+		Config    struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Manifests []*ManifestDescriptor `json:"manifests"`
+	}{}
+	err = json.Unmarshal(out, &body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode manifest %s", tagOrDigest)
+	}
+
+	manifest := &Manifest{
+		ManifestDescriptor: ManifestDescriptor{
+			MediaType: body.MediaType,
+			Size:      int64(len(out)),
+			Digest:    resp.Header.Get("Docker-Content-Digest"),
+		},
+		Config:    body.Config.Digest,
+		Manifests: body.Manifests,
+	}
+	if mediaType := resp.Header.Get("Content-Type"); mediaType != "" {
+		if idx := strings.Index(mediaType, ";"); idx != -1 {
+			mediaType = mediaType[:idx]
+		}
+		manifest.MediaType = mediaType
+	}
+	if manifest.Digest == "" {
+		manifest.Digest = digest.FromBytes(out).String()
+	}
+
+	return manifest, nil
+}
+
+// GetImageCreated returns the creation time that is stored in the image config with the given digest
+func (r *RegistryClient) GetImageCreated(ctx context.Context, configDigest string) (time.Time, error) {
+	blobURL, err := r.blobURL(configDigest)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, blobURL, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return time.Time{}, registryclient.HandleErrorResponse(resp)
+	}
+
+	config := struct {
+		Created time.Time `json:"created"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&config)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "decode image config %s", configDigest)
+	}
+
+	return config.Created, nil
+}
+
+// DeleteManifest deletes the manifest with the given digest and therefore all tags that point to it
+func (r *RegistryClient) DeleteManifest(ctx context.Context, manifestDigest string) error {
+	manifestURL, err := r.manifestURL(manifestDigest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, manifestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return registryclient.HandleErrorResponse(resp)
+	}
+
+	return nil
+}
+
+// GetRawManifest returns the content and the media type of the manifest the tag or digest points to
+func (r *RegistryClient) GetRawManifest(ctx context.Context, tagOrDigest string) ([]byte, string, error) {
+	manifestURL, err := r.manifestURL(tagOrDigest)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return nil, "", registryclient.HandleErrorResponse(resp)
+	}
+
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if idx := strings.Index(mediaType, ";"); idx != -1 {
+		mediaType = mediaType[:idx]
+	}
+	if mediaType == "" {
+		body := struct {
+			MediaType string `json:"mediaType"`
+		}{}
+		_ = json.Unmarshal(out, &body)
+		mediaType = body.MediaType
+	}
+
+	return out, mediaType, nil
+}
+
+// BlobExists checks if the blob with the given digest exists in the repository
+func (r *RegistryClient) BlobExists(ctx context.Context, blobDigest string) (bool, error) {
+	blobURL, err := r.blobURL(blobDigest)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodHead, blobURL, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	} else if registryclient.SuccessStatus(resp.StatusCode) {
+		return true, nil
+	}
+
+	return false, registryclient.HandleErrorResponse(resp)
+}
+
+// GetBlob returns the content of the blob with the given digest. The caller has to close the returned reader
+func (r *RegistryClient) GetBlob(ctx context.Context, blobDigest string) (io.ReadCloser, error) {
+	blobURL, err := r.blobURL(blobDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, blobURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		defer resp.Body.Close()
+		return nil, registryclient.HandleErrorResponse(resp)
+	}
+
+	return resp.Body, nil
+}
+
+// PutBlob uploads the blob with the given digest and size in a single request
+func (r *RegistryClient) PutBlob(ctx context.Context, blobDigest string, size int64, content io.Reader) error {
+	uploadURL, err := r.urlBuilder.BuildBlobUploadURL(r.name)
+	if err != nil {
+		return err
+	}
+
+	// Start the upload
+	req, err := http.NewRequest(http.MethodPost, uploadURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return registryclient.HandleErrorResponse(resp)
+	}
+
+	// Finish the upload with the content at the returned location
+	base, err := url.Parse(uploadURL)
+	if err != nil {
+		return err
+	}
+	location, err := base.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return errors.Wrap(err, "parse upload location")
+	}
+
+	query := location.Query()
+	query.Set("digest", blobDigest)
+	location.RawQuery = query.Encode()
+
+	req, err = http.NewRequest(http.MethodPut, location.String(), content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err = r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !registryclient.SuccessStatus(resp.StatusCode) {
+		return registryclient.HandleErrorResponse(resp)
+	}
+
+	return nil
+}
+
+func (r *RegistryClient) blobURL(blobDigest string) (string, error) {
+	ref, err := reference.WithDigest(r.name, digest.Digest(blobDigest))
+	if err != nil {
+		return "", err
+	}
+
+	return r.urlBuilder.BuildBlobURL(ref)
+}
@@ -0,0 +1,185 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"gotest.tools/assert"
+)
+
+func TestRegistryClientTagExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/image/manifests/exists" && r.Method == http.MethodHead:
+			w.Header().Set("Docker-Content-Digest", "sha256:0000000000000000000000000000000000000000000000000000000000000000")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/image"
+	client, err := NewRegistryClient(image, &types.AuthConfig{}, "pull")
+	assert.NilError(t, err)
+
+	exists, err := client.TagExists(context.Background(), "exists")
+	assert.NilError(t, err)
+	assert.Equal(t, exists, true, "Existing tag was not found")
+
+	exists, err = client.TagExists(context.Background(), "missing")
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false, "Missing tag was found")
+}
+
+func TestRegistryClientPutManifestList(t *testing.T) {
+	var pushed *manifestList
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/image/manifests/tag-linux-arm64" && r.Method == http.MethodHead:
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "528")
+			w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/image/manifests/tag" && r.Method == http.MethodPut:
+			assert.Equal(t, r.Header.Get("Content-Type"), MediaTypeManifestList)
+			out, err := ioutil.ReadAll(r.Body)
+			assert.NilError(t, err)
+			pushed = &manifestList{}
+			assert.NilError(t, json.Unmarshal(out, pushed))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/image"
+	client, err := NewRegistryClient(image, &types.AuthConfig{}, "pull", "push")
+	assert.NilError(t, err)
+
+	manifest, err := client.GetManifestDescriptor(context.Background(), "tag-linux-arm64")
+	assert.NilError(t, err)
+	assert.Equal(t, manifest.MediaType, "application/vnd.docker.distribution.manifest.v2+json")
+	assert.Equal(t, manifest.Size, int64(528))
+
+	manifest.Platform, err = ParsePlatform("linux/arm64/v8")
+	assert.NilError(t, err)
+	err = client.PutManifestList(context.Background(), "tag", []*ManifestDescriptor{manifest})
+	assert.NilError(t, err)
+	assert.Equal(t, len(pushed.Manifests), 1)
+	assert.Equal(t, pushed.Manifests[0].Digest, "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+	assert.Equal(t, *pushed.Manifests[0].Platform, Platform{OS: "linux", Architecture: "arm64", Variant: "v8"})
+
+	_, err = ParsePlatform("linux")
+	assert.ErrorContains(t, err, "invalid platform")
+}
+
+func TestRegistryClientGetRegistryTags(t *testing.T) {
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/image/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/test/image/tags/list?last=new&n=2>; rel="next"`)
+			_, _ = w.Write([]byte(`{"name":"test/image","tags":["new","old"]}`))
+		case r.URL.Path == "/v2/test/image/tags/list" && r.URL.Query().Get("last") == "new":
+			_, _ = w.Write([]byte(`{"name":"test/image","tags":["old-alias"]}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/test/image/manifests/") && r.Method == http.MethodGet:
+			digest := "sha256:" + strings.Repeat("a", 64)
+			if tag := strings.TrimPrefix(r.URL.Path, "/v2/test/image/manifests/"); tag == "old" || tag == "old-alias" {
+				digest = "sha256:" + strings.Repeat("b", 64)
+			}
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Docker-Content-Digest", digest)
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"digest":"` + digest + `"}}`))
+		case r.URL.Path == "/v2/test/image/blobs/sha256:"+strings.Repeat("a", 64):
+			_, _ = w.Write([]byte(`{"created":"2021-02-01T00:00:00Z"}`))
+		case r.URL.Path == "/v2/test/image/blobs/sha256:"+strings.Repeat("b", 64):
+			_, _ = w.Write([]byte(`{"created":"2021-01-01T00:00:00Z"}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/test/image/manifests/") && r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v2/test/image/manifests/"))
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/image"
+	client, err := NewRegistryClient(image, &types.AuthConfig{}, "pull", "push", "delete")
+	assert.NilError(t, err)
+
+	tags, err := client.GetRegistryTags(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 3)
+
+	stale := SelectStaleTags(tags, 1, nil, nil, nil)
+	assert.Equal(t, len(stale), 2)
+	assert.Equal(t, stale[0].Digest, "sha256:"+strings.Repeat("b", 64))
+	assert.Equal(t, stale[0].Created.Year(), 2021)
+
+	assert.NilError(t, client.DeleteManifest(context.Background(), stale[0].Digest))
+	assert.DeepEqual(t, deleted, []string{stale[0].Digest})
+}
+
+func TestRegistryClientPutBlob(t *testing.T) {
+	var (
+		content = []byte("layer")
+		blob    = "sha256:0f2ea75cbf0e1c4e8c4e4f1d4d6a18fd7a7f5ab2d0e1ff7b5bd1d5c0a8a7f6e1"
+		pushed  []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/image/blobs/"+blob && r.Method == http.MethodHead:
+			if pushed == nil {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+		case r.URL.Path == "/v2/test/image/blobs/uploads/" && r.Method == http.MethodPost:
+			w.Header().Set("Location", "/v2/test/image/blobs/uploads/upload-id?state=abc")
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/v2/test/image/blobs/uploads/upload-id" && r.Method == http.MethodPut:
+			assert.Equal(t, r.URL.Query().Get("state"), "abc")
+			assert.Equal(t, r.URL.Query().Get("digest"), blob)
+			out, err := ioutil.ReadAll(r.Body)
+			assert.NilError(t, err)
+			pushed = out
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/image"
+	client, err := NewRegistryClient(image, &types.AuthConfig{}, "pull", "push")
+	assert.NilError(t, err)
+
+	exists, err := client.BlobExists(context.Background(), blob)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false, "Missing blob was found")
+
+	err = client.PutBlob(context.Background(), blob, int64(len(content)), bytes.NewReader(content))
+	assert.NilError(t, err)
+	assert.Equal(t, string(pushed), string(content))
+
+	exists, err = client.BlobExists(context.Background(), blob)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, true, "Uploaded blob was not found")
+}
@@ -27,7 +27,7 @@ func (client *FakeClient) Ping(ctx context.Context) (dockertypes.Ping, error) {
 func (client *FakeClient) NegotiateAPIVersion(ctx context.Context) {}
 
 // ImageBuildCLI builds an image with the docker cli
-func (client *FakeClient) ImageBuildCLI(useBuildkit bool, context io.Reader, writer io.Writer, additionalArgs []string, options dockertypes.ImageBuildOptions, log log.Logger) error {
+func (client *FakeClient) ImageBuildCLI(ctx context.Context, useBuildkit bool, buildContext io.Reader, writer io.Writer, additionalArgs []string, options dockertypes.ImageBuildOptions, log log.Logger) error {
 	return nil
 }
 
@@ -181,6 +181,7 @@ func newHandler(config config.Config, dependencies []types.Dependency, defaultCo
 	handler.mux.HandleFunc("/api/resize", handler.resize)
 	handler.mux.HandleFunc("/api/logs", handler.logs)
 	handler.mux.HandleFunc("/api/logs-multiple", handler.logsMultiple)
+	handler.mux.HandleFunc("/api/sync", handler.syncStatus)
 	return handler, nil
 }
 
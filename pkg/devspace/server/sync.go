@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/loft-sh/devspace/pkg/devspace/services/synccontroller"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+)
+
+// SyncStatus is the status of a single sync config that is returned by the /api/sync request
+type SyncStatus struct {
+	Name          string `json:"name,omitempty"`
+	LocalPath     string `json:"localPath"`
+	ContainerPath string `json:"containerPath"`
+
+	// Status is nil if the sync was not started yet
+	Status *sync.Status `json:"status"`
+}
+
+func (h *handler) syncStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := []*SyncStatus{}
+	if h.config != nil && h.config.Config() != nil {
+		for _, syncConfig := range h.config.Config().Dev.Sync {
+			syncStatus := &SyncStatus{
+				Name:          syncConfig.Name,
+				LocalPath:     syncConfig.LocalSubPath,
+				ContainerPath: syncConfig.ContainerPath,
+			}
+
+			status, err := sync.ReadStatus(synccontroller.StatusPath(syncConfig))
+			if err != nil && !os.IsNotExist(err) {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			syncStatus.Status = status
+			statuses = append(statuses, syncStatus)
+		}
+	}
+
+	b, err := json.Marshal(statuses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/log"
 )
 
@@ -25,6 +26,7 @@ type Client interface {
 	StartSync(interrupt chan error, printSyncLog bool, verboseSync bool, prefixFn func(idx int, syncConfig *latest.SyncConfig) string) error
 
 	StartSyncFromCmd(options targetselector.Options, syncConfig *latest.SyncConfig, interrupt chan error, noWatch, verbose bool) error
+	SyncDryRun(options targetselector.Options, syncConfig *latest.SyncConfig) (*sync.DryRunResult, error)
 	StartTerminal(options targetselector.Options, args []string, workDir string, interrupt chan error, wait, restart bool, stdout io.Writer, stderr io.Writer, stdin io.Reader) (int, error)
 
 	ReplacePods() error
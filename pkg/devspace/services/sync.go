@@ -10,6 +10,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	devspacesync "github.com/loft-sh/devspace/pkg/devspace/sync"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 
 	"github.com/pkg/errors"
@@ -60,6 +61,17 @@ func (serviceClient *client) StartSyncFromCmd(targetOptions targetselector.Optio
 	return nil
 }
 
+// SyncDryRun returns the changes the initial sync of the given sync config would apply
+func (serviceClient *client) SyncDryRun(targetOptions targetselector.Options, syncConfig *latest.SyncConfig) (*devspacesync.DryRunResult, error) {
+	options := &synccontroller.Options{
+		SyncConfig:    syncConfig,
+		TargetOptions: targetOptions,
+		SyncLog:       logpkg.GetFileLogger("sync"),
+	}
+
+	return synccontroller.NewController(serviceClient.config, serviceClient.dependencies, serviceClient.client, serviceClient.log).DryRun(options, serviceClient.log)
+}
+
 func DefaultPrefixFn(idx int, syncConfig *latest.SyncConfig) string {
 	prefix := fmt.Sprintf("[%d:sync] ", idx)
 	if syncConfig.Name != "" {
@@ -75,6 +87,9 @@ func (serviceClient *client) StartSync(interrupt chan error, printSyncLog, verbo
 		return fmt.Errorf("DevSpace config is nil")
 	}
 
+	// syncs to the same container share a single helper connection of the controller
+	controller := synccontroller.NewController(serviceClient.config, serviceClient.dependencies, serviceClient.client, serviceClient.log)
+
 	// Start sync client
 	waitGroup := sync.WaitGroup{}
 	errs := []error{}
@@ -113,7 +128,7 @@ func (serviceClient *client) StartSync(interrupt chan error, printSyncLog, verbo
 		go func(options *synccontroller.Options) {
 			defer waitGroup.Done()
 
-			err := synccontroller.NewController(serviceClient.config, serviceClient.dependencies, serviceClient.client, serviceClient.log).Start(options, log)
+			err := controller.Start(options, log)
 			if err != nil {
 				errsMutex.Lock()
 				errs = append(errs, errors.Errorf("unable to start sync: %v", err))
@@ -0,0 +1,246 @@
+package synccontroller
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/services/inject"
+	devspacesync "github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+)
+
+// helperConnection is a single connection to the sync helper in a container. All syncs
+// that target the same container share the connection and use their own session on it
+type helperConnection struct {
+	key string
+
+	conn *grpc.ClientConn
+
+	// close closes the connection and its transport
+	close func()
+
+	// sessions is the amount of syncs that use the connection
+	sessions int
+
+	// closed is closed as soon as the connection to the helper is lost
+	closed chan struct{}
+	err    error
+}
+
+const (
+	// defaultHelperPort is the container port the helper of the first container listens on if the port forward
+	// transport is used. The helpers of the other containers of the pod listen on the following ports
+	defaultHelperPort = 9410
+
+	// helperIdleTimeout is the time a helper that listens on a port keeps running without a connection
+	helperIdleTimeout = 5 * time.Minute
+)
+
+// connectionCache holds the open helper connections of a controller
+type connectionCache struct {
+	connectionsMutex sync.Mutex
+	connections      map[string]*helperConnection
+}
+
+// getConnection returns the helper connection for the given container or opens a new one if there is none yet
+func (c *connectionCache) getConnection(client kubectl.Client, pod *v1.Pod, container string, syncConfig *latest.SyncConfig, log logpkg.Logger) (*helperConnection, error) {
+	key := pod.Namespace + "/" + pod.Name + "/" + container
+	if syncConfig.Transport == latest.SyncTransportPortForward {
+		key += "/" + string(latest.SyncTransportPortForward)
+	}
+
+	// syncs with bandwidth limits need their own connection, because the limits are applied to the connection
+	var upstreamLimit, downstreamLimit int64
+	if syncConfig.BandwidthLimits != nil {
+		if syncConfig.BandwidthLimits.Download != nil {
+			downstreamLimit = *syncConfig.BandwidthLimits.Download * 1024
+		}
+		if syncConfig.BandwidthLimits.Upload != nil {
+			upstreamLimit = *syncConfig.BandwidthLimits.Upload * 1024
+		}
+		if upstreamLimit > 0 || downstreamLimit > 0 {
+			key += "/" + getSyncConfigID(syncConfig)
+		}
+	}
+
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+
+	if c.connections == nil {
+		c.connections = map[string]*helperConnection{}
+	}
+	if existing := c.connections[key]; existing != nil {
+		existing.sessions++
+		return existing, nil
+	}
+
+	err := inject.InjectDevSpaceHelper(client, pod, container, string(syncConfig.Arch), log)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &helperConnection{
+		key:      key,
+		sessions: 1,
+		closed:   make(chan struct{}),
+	}
+
+	// wait blocks until the transport of the connection is closed
+	var wait func() error
+	if syncConfig.Transport == latest.SyncTransportPortForward {
+		wait, err = connectPortForward(connection, client, pod, container, getHelperPort(pod, container, syncConfig), upstreamLimit, downstreamLimit)
+	} else {
+		wait, err = connectExec(connection, client, pod, container, upstreamLimit, downstreamLimit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := wait()
+		if err == nil {
+			err = errors.New("helper exited")
+		}
+
+		c.connectionsMutex.Lock()
+		if c.connections[key] == connection {
+			delete(c.connections, key)
+		}
+		connection.err = err
+		c.connectionsMutex.Unlock()
+		close(connection.closed)
+	}()
+
+	c.connections[key] = connection
+	return connection, nil
+}
+
+// getHelperPort returns the port the helper of the given container listens on. All containers of a pod share
+// the network namespace, so every container gets its own port by adding the index of the container to the base port
+func getHelperPort(pod *v1.Pod, container string, syncConfig *latest.SyncConfig) int {
+	port := defaultHelperPort
+	if syncConfig.TransportPort != nil {
+		port = *syncConfig.TransportPort
+	}
+
+	for index, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return port + index
+		}
+	}
+
+	return port
+}
+
+// connectExec connects to the helper over the streams of a kubectl exec that runs the helper
+func connectExec(connection *helperConnection, client kubectl.Client, pod *v1.Pod, container string, upstreamLimit, downstreamLimit int64) (func() error, error) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	conn, err := devspacesync.NewClientConnection(stdoutReader, stdinWriter, upstreamLimit, downstreamLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "new client connection")
+	}
+
+	connection.conn = conn
+	connection.close = func() {
+		conn.Close()
+		stdinWriter.Close()
+		stdoutReader.Close()
+	}
+	return func() error {
+		return StartStream(client, pod, container, []string{inject.DevSpaceHelperContainerPath, "sync", "serve"}, stdinReader, stdoutWriter, true, logpkg.GetFileLogger("sync"))
+	}, nil
+}
+
+// connectPortForward starts the helper in the background listening on the given loopback port of the container,
+// if it isn't running already, and connects to it through a port forwarding
+func connectPortForward(connection *helperConnection, client kubectl.Client, pod *v1.Pod, container string, port int, upstreamLimit, downstreamLimit int64) (func() error, error) {
+	_, stderr, err := client.ExecBuffered(pod, container, []string{inject.DevSpaceHelperContainerPath, "sync", "serve", "--listen", "127.0.0.1:" + strconv.Itoa(port), "--detach", "--idle-timeout", helperIdleTimeout.String()}, nil)
+	if err != nil {
+		return nil, errors.Errorf("start helper on port %d: %s %v", port, string(stderr), err)
+	}
+
+	var (
+		stopChan  = make(chan struct{})
+		readyChan = make(chan struct{})
+		errorChan = make(chan error, 10)
+		done      = make(chan error, 1)
+	)
+	pf, err := client.NewPortForwarder(pod, []string{"0:" + strconv.Itoa(port)}, []string{"localhost"}, stopChan, readyChan, errorChan)
+	if err != nil {
+		return nil, errors.Wrap(err, "new port forwarder")
+	}
+
+	go func() {
+		done <- pf.ForwardPorts()
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-errorChan:
+		close(stopChan)
+		return nil, errors.Wrap(err, "forward helper port")
+	case err := <-done:
+		if err == nil {
+			err = errors.New("port forwarding stopped")
+		}
+		return nil, errors.Wrap(err, "forward helper port")
+	case <-time.After(20 * time.Second):
+		close(stopChan)
+		return nil, errors.New("timeout waiting for helper port forwarding to start")
+	}
+
+	ports, err := pf.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopChan)
+		return nil, errors.Errorf("retrieve forwarded helper port: %v", err)
+	}
+
+	conn, err := devspacesync.NewTCPClientConnection("localhost:"+strconv.Itoa(int(ports[0].Local)), upstreamLimit, downstreamLimit)
+	if err != nil {
+		close(stopChan)
+		return nil, errors.Wrap(err, "new client connection")
+	}
+
+	var closeOnce sync.Once
+	connection.conn = conn
+	connection.close = func() {
+		conn.Close()
+		closeOnce.Do(func() {
+			close(stopChan)
+		})
+	}
+	return func() error {
+		select {
+		case err := <-errorChan:
+			return errors.Wrap(err, "port forwarding")
+		case err := <-done:
+			return err
+		}
+	}, nil
+}
+
+// releaseConnection is called if a sync stops using the connection. If no sync uses the
+// connection anymore, the connection is closed
+func (c *connectionCache) releaseConnection(connection *helperConnection) {
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+
+	connection.sessions--
+	if connection.sessions > 0 {
+		return
+	}
+
+	if c.connections[connection.key] == connection {
+		delete(c.connections, connection.key)
+	}
+
+	connection.close()
+}
@@ -8,18 +8,23 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/modemask"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
-	"github.com/loft-sh/devspace/pkg/devspace/services/inject"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl/selector"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/loft-sh/devspace/pkg/util/hash"
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/scanner"
@@ -30,6 +35,9 @@ import (
 
 type Controller interface {
 	Start(options *Options, log logpkg.Logger) error
+
+	// DryRun returns the changes the initial sync would apply without changing anything
+	DryRun(options *Options, log logpkg.Logger) (*sync.DryRunResult, error)
 }
 
 func NewController(config config.Config, dependencies []types.Dependency, client kubectl.Client, log logpkg.Logger) Controller {
@@ -46,8 +54,17 @@ type controller struct {
 	dependencies []types.Dependency
 	client       kubectl.Client
 	log          logpkg.Logger
+
+	// connections are the helper connections that are shared by all syncs to the same container
+	connections connectionCache
 }
 
+// defaultThrottleChangeDetection is the default throttle of the helper change detection in milliseconds
+const defaultThrottleChangeDetection = 5
+
+// sessionCounter makes sure that session ids are unique
+var sessionCounter int64
+
 type Options struct {
 	SyncConfig    *latest.SyncConfig
 	TargetOptions targetselector.Options
@@ -90,7 +107,7 @@ func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
 	var (
 		onInitUploadDone   chan struct{}
 		onInitDownloadDone chan struct{}
-		onError            = make(chan error)
+		onError            = make(chan error, 1)
 		onDone             = make(chan struct{})
 	)
 
@@ -245,24 +262,13 @@ func (c *controller) startSync(options *Options, onInitUploadDone chan struct{},
 		}
 	}
 
-	options.TargetOptions.ImageSelector = []imageselector.ImageSelector{}
-	if syncConfig.ImageSelector != "" {
-		imageSelector, err := util.ResolveImageAsImageSelector(syncConfig.ImageSelector, c.config, c.dependencies)
-		if err != nil {
-			return nil, err
-		}
-
-		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
-	}
-
-	log.Info("Waiting for pods...")
-	container, err := targetselector.NewTargetSelector(c.client).SelectSingleContainer(context.TODO(), options.TargetOptions, c.log)
+	container, err := c.selectContainer(options, log)
 	if err != nil {
-		return nil, errors.Errorf("Error selecting pod: %v", err)
+		return nil, err
 	}
 
 	log.Info("Starting sync...")
-	syncClient, err := c.initClient(container.Pod, container.Container.Name, syncConfig, options.Verbose, options.SyncLog)
+	syncClient, err := c.initClient(container.Pod, container.Container.Name, syncConfig, options.Verbose, options.SyncLog, false)
 	if err != nil {
 		return nil, errors.Wrap(err, "start sync")
 	}
@@ -281,12 +287,60 @@ func (c *controller) startSync(options *Options, onInitUploadDone chan struct{},
 	return syncClient, nil
 }
 
-func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *latest.SyncConfig, verbose bool, customLog logpkg.Logger) (*sync.Sync, error) {
-	err := inject.InjectDevSpaceHelper(c.client, pod, container, string(syncConfig.Arch), customLog)
+// DryRun connects to the target container and compares the local and remote state like the initial
+// sync, but doesn't apply any changes
+func (c *controller) DryRun(options *Options, log logpkg.Logger) (*sync.DryRunResult, error) {
+	options.TargetOptions.SkipInitContainers = true
+	syncConfig := options.SyncConfig
+
+	localPath := "."
+	if syncConfig.LocalSubPath != "" {
+		localPath = syncConfig.LocalSubPath
+	}
+
+	_, err := os.Stat(localPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "stat local path")
+	}
+
+	container, err := c.selectContainer(options, log)
 	if err != nil {
 		return nil, err
 	}
 
+	// the session is read only, so that the container path is neither created nor changed
+	syncClient, err := c.initClient(container.Pod, container.Container.Name, syncConfig, options.Verbose, options.SyncLog, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "start sync")
+	}
+	defer syncClient.Stop(nil)
+
+	log.Info("Comparing local and remote files...")
+	return syncClient.DryRun()
+}
+
+// selectContainer waits for the container that should be synced
+func (c *controller) selectContainer(options *Options, log logpkg.Logger) (*selector.SelectedPodContainer, error) {
+	options.TargetOptions.ImageSelector = []imageselector.ImageSelector{}
+	if options.SyncConfig.ImageSelector != "" {
+		imageSelector, err := util.ResolveImageAsImageSelector(options.SyncConfig.ImageSelector, c.config, c.dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
+	}
+
+	log.Info("Waiting for pods...")
+	container, err := targetselector.NewTargetSelector(c.client).SelectSingleContainer(context.TODO(), options.TargetOptions, c.log)
+	if err != nil {
+		return nil, errors.Errorf("Error selecting pod: %v", err)
+	}
+
+	return container, nil
+}
+
+func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *latest.SyncConfig, verbose bool, customLog logpkg.Logger, readOnly bool) (*sync.Sync, error) {
 	localPath := "."
 	if syncConfig.LocalSubPath != "" {
 		localPath = syncConfig.LocalSubPath
@@ -320,6 +374,11 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 		DownstreamDisabled:   downstreamDisabled,
 		Log:                  customLog,
 		Polling:              syncConfig.Polling,
+		DeltaTransfer:        syncConfig.DeltaTransfer,
+		ConflictStrategy:     syncConfig.ConflictStrategy,
+		ConflictLogPath:      ConflictLogPath(syncConfig),
+		StatusPath:           StatusPath(syncConfig),
+		Questioner:           c.log,
 	}
 
 	// Initialize log
@@ -327,6 +386,16 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 		options.Log = logpkg.GetFileLogger("sync")
 	}
 
+	// Persist the sync state per sync config and container. The state is keyed by the container id,
+	// because a restarted container starts with a fresh filesystem and needs a full initial sync
+	if syncConfig.PersistState {
+		containerID := getContainerID(pod, container)
+		if containerID != "" {
+			options.StatePath = getStatePath(syncConfig)
+			options.StateKey = containerID + ":" + containerPath
+		}
+	}
+
 	// Add onDownload hooks
 	if syncConfig.OnDownload != nil && syncConfig.OnDownload.ExecLocal != nil {
 		fileCmd, fileArgs, dirCmd, dirArgs := getSyncCommands(syncConfig.OnDownload.ExecLocal)
@@ -391,88 +460,167 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 		options.UploadBatchArgs = syncConfig.OnUpload.ExecRemote.OnBatch.Args
 	}
 
+	// Apply the permission options
+	var modeMasks []modemask.Mask
+	if syncConfig.Permissions != nil {
+		var err error
+		modeMasks, err = getModeMasks(syncConfig.Permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		options.PreserveModes = syncConfig.Permissions.PreserveModes
+		options.ModeMasks, err = modemask.Compile(modeMasks)
+		if err != nil {
+			return nil, errors.Wrap(err, "compile mode masks")
+		}
+	}
+
 	syncClient, err := sync.NewSync(localPath, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "create sync")
 	}
 
-	// Start upstream
-	upstreamArgs := []string{inject.DevSpaceHelperContainerPath, "sync", "upstream"}
-	if runtime.GOOS == "darwin" || runtime.GOOS == "linux" {
-		upstreamArgs = append(upstreamArgs, "--override-permissions")
-	}
-	for _, exclude := range options.ExcludePaths {
-		upstreamArgs = append(upstreamArgs, "--exclude", exclude)
-	}
-	for _, exclude := range options.DownloadExcludePaths {
-		upstreamArgs = append(upstreamArgs, "--exclude", exclude)
-	}
-	if syncConfig.OnUpload != nil && syncConfig.OnUpload.ExecRemote != nil {
-		onUpload := syncConfig.OnUpload.ExecRemote
-		fileCmd, fileArgs, dirCmd, dirArgs := getSyncCommands(onUpload)
-		if fileCmd != "" {
-			upstreamArgs = append(upstreamArgs, "--filechangecmd", fileCmd)
-			for _, arg := range fileArgs {
-				upstreamArgs = append(upstreamArgs, "--filechangeargs", arg)
-			}
-		}
-		if dirCmd != "" {
-			upstreamArgs = append(upstreamArgs, "--dircreatecmd", dirCmd)
-			for _, arg := range dirArgs {
-				upstreamArgs = append(upstreamArgs, "--dircreateargs", arg)
-			}
-		}
+	// Start the session on the helper connection to the container
+	connection, err := c.connections.getConnection(c.client, pod, container, syncConfig, customLog)
+	if err != nil {
+		return nil, err
 	}
 
-	upstreamArgs = append(upstreamArgs, containerPath)
+	sessionID := fmt.Sprintf("%s-%d", getSyncConfigID(syncConfig), atomic.AddInt64(&sessionCounter, 1))
+	err = startSession(connection, sessionID, syncConfig, containerPath, modeMasks, &options, readOnly)
+	if err != nil {
+		c.connections.releaseConnection(connection)
+		return nil, errors.Wrap(err, "start session")
+	}
 
-	upStdinReader, upStdinWriter := io.Pipe()
-	upStdoutReader, upStdoutWriter := io.Pipe()
+	err = syncClient.InitSession(connection.conn, sessionID)
+	if err != nil {
+		c.connections.releaseConnection(connection)
+		return nil, errors.Wrap(err, "init session")
+	}
 
 	go func() {
-		err := StartStream(c.client, pod, container, upstreamArgs, upStdinReader, upStdoutWriter, true, options.Log)
-		if err != nil {
-			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
+		select {
+		case <-connection.closed:
+			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, connection.err))
+			<-syncClient.Stopped()
+		case <-syncClient.Stopped():
 		}
+
+		c.connections.releaseConnection(connection)
 	}()
 
-	err = syncClient.InitUpstream(upStdoutReader, upStdinWriter)
-	if err != nil {
-		return nil, errors.Wrap(err, "init upstream")
-	}
+	return syncClient, nil
+}
 
-	// Start downstream
-	downstreamArgs := []string{inject.DevSpaceHelperContainerPath, "sync", "downstream"}
-	if syncConfig.ThrottleChangeDetection != nil {
-		downstreamArgs = append(downstreamArgs, "--throttle", strconv.FormatInt(*syncConfig.ThrottleChangeDetection, 10))
+// startSession starts a new sync session with the given id on the helper connection. A read only
+// session only lists the files in the container
+func startSession(connection *helperConnection, sessionID string, syncConfig *latest.SyncConfig, containerPath string, modeMasks []modemask.Mask, options *sync.Options, readOnly bool) error {
+	upstreamOptions := &remote.UpstreamSessionOptions{
+		Path:                containerPath,
+		OverridePermissions: runtime.GOOS == "darwin" || runtime.GOOS == "linux" || options.PreserveModes,
+		PreserveModes:       options.PreserveModes,
+	}
+	for _, mask := range modeMasks {
+		upstreamOptions.ModeMasks = append(upstreamOptions.ModeMasks, &remote.ModeMask{
+			Path:  mask.Path,
+			Set:   uint32(mask.Set),
+			Clear: uint32(mask.Clear),
+		})
+	}
+	if syncConfig.Permissions != nil && (syncConfig.Permissions.UID != nil || syncConfig.Permissions.GID != nil) {
+		upstreamOptions.Owner = &remote.Owner{UID: -1, GID: -1}
+		if syncConfig.Permissions.UID != nil {
+			upstreamOptions.Owner.UID = *syncConfig.Permissions.UID
+		}
+		if syncConfig.Permissions.GID != nil {
+			upstreamOptions.Owner.GID = *syncConfig.Permissions.GID
+		}
 	}
-	if syncConfig.Polling {
-		downstreamArgs = append(downstreamArgs, "--polling")
+	downstreamOptions := &remote.DownstreamSessionOptions{
+		Path:     containerPath,
+		Throttle: defaultThrottleChangeDetection,
+		Polling:  syncConfig.Polling,
 	}
-	for _, exclude := range options.ExcludePaths {
-		downstreamArgs = append(downstreamArgs, "--exclude", exclude)
+	if syncConfig.ThrottleChangeDetection != nil {
+		downstreamOptions.Throttle = *syncConfig.ThrottleChangeDetection
 	}
-	for _, exclude := range options.DownloadExcludePaths {
-		downstreamArgs = append(downstreamArgs, "--exclude", exclude)
+
+	excludePaths := append([]string{}, options.ExcludePaths...)
+	excludePaths = append(excludePaths, options.DownloadExcludePaths...)
+	upstreamOptions.Exclude = excludePaths
+	downstreamOptions.Exclude = excludePaths
+
+	if syncConfig.OnUpload != nil && syncConfig.OnUpload.ExecRemote != nil {
+		upstreamOptions.FileChangeCmd, upstreamOptions.FileChangeArgs, upstreamOptions.DirCreateCmd, upstreamOptions.DirCreateArgs = getSyncCommands(syncConfig.OnUpload.ExecRemote)
 	}
-	downstreamArgs = append(downstreamArgs, containerPath)
 
-	downStdinReader, downStdinWriter := io.Pipe()
-	downStdoutReader, downStdoutWriter := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
-	go func() {
-		err := StartStream(c.client, pod, container, downstreamArgs, downStdinReader, downStdoutWriter, true, options.Log)
+	_, err := remote.NewSessionClient(connection.conn).Start(ctx, &remote.SessionOptions{
+		ID:         sessionID,
+		Upstream:   upstreamOptions,
+		Downstream: downstreamOptions,
+		ReadOnly:   readOnly,
+	})
+	return err
+}
+
+// getModeMasks parses the mode masks of the given permissions
+func getModeMasks(permissions *latest.SyncPermissions) ([]modemask.Mask, error) {
+	modeMasks := make([]modemask.Mask, 0, len(permissions.ModeMasks))
+	for _, mask := range permissions.ModeMasks {
+		set, err := modemask.ParseMode(mask.Set)
 		if err != nil {
-			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
+			return nil, errors.Wrapf(err, "parse mode mask %s set", mask.Path)
+		}
+		clearMode, err := modemask.ParseMode(mask.Clear)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse mode mask %s clear", mask.Path)
 		}
-	}()
 
-	err = syncClient.InitDownstream(downStdoutReader, downStdinWriter)
-	if err != nil {
-		return nil, errors.Wrap(err, "init downstream")
+		modeMasks = append(modeMasks, modemask.Mask{
+			Path:  mask.Path,
+			Set:   set,
+			Clear: clearMode,
+		})
 	}
 
-	return syncClient, nil
+	return modeMasks, nil
+}
+
+// ConflictLogPath returns the path of the conflict log of the given sync config
+func ConflictLogPath(syncConfig *latest.SyncConfig) string {
+	return filepath.Join(constants.DefaultCacheFolder, "sync", getSyncConfigID(syncConfig)+"-conflicts.log")
+}
+
+// StatusPath returns the path where the metrics of the given sync config are written to
+func StatusPath(syncConfig *latest.SyncConfig) string {
+	return filepath.Join(constants.DefaultCacheFolder, "sync", getSyncConfigID(syncConfig)+"-status.json")
+}
+
+// getStatePath returns the path where the state of the given sync config is persisted
+func getStatePath(syncConfig *latest.SyncConfig) string {
+	return filepath.Join(constants.DefaultCacheFolder, "sync", getSyncConfigID(syncConfig)+".json")
+}
+
+// getContainerID returns the id of the running container with the given name or an empty string if it is not known
+func getContainerID(pod *v1.Pod, container string) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.ContainerID
+		}
+	}
+
+	return ""
+}
+
+// getSyncConfigID returns a short id that identifies the sync config
+func getSyncConfigID(syncConfig *latest.SyncConfig) string {
+	key := strings.Join([]string{syncConfig.Name, syncConfig.ImageSelector, fmt.Sprintf("%v", syncConfig.LabelSelector), syncConfig.ContainerName, syncConfig.LocalSubPath, syncConfig.ContainerPath}, ";")
+	return hash.String(key)[:16]
 }
 
 func getSyncCommands(cmd *latest.SyncExecCommand) (string, []string, string, []string) {
@@ -0,0 +1,341 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// The signatures are stored in the same format as cosign stores them, so they can be verified with cosign as well
+const (
+	// MediaTypeSimpleSigning is the media type of the layers that contain the signed payload
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// AnnotationSignature is the annotation of a layer that contains the base64 encoded signature of the payload
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	signatureType      = "cosign container image signature"
+	signatureTagSuffix = ".sig"
+)
+
+// emptyConfig is the config blob of a signature manifest
+var emptyConfig = []byte("{}")
+
+// Registry is the registry api that is needed to store and retrieve signatures
+type Registry interface {
+	GetManifestDescriptor(ctx context.Context, tag string) (*docker.ManifestDescriptor, error)
+	GetRawManifest(ctx context.Context, tagOrDigest string) ([]byte, string, error)
+	BlobExists(ctx context.Context, blobDigest string) (bool, error)
+	GetBlob(ctx context.Context, blobDigest string) (io.ReadCloser, error)
+	PutBlob(ctx context.Context, blobDigest string, size int64, content io.Reader) error
+	PutManifest(ctx context.Context, tag string, mediaType string, manifest []byte) error
+}
+
+// payload is the simple signing payload that is signed
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// Tag returns the tag the signatures of the manifest with the given digest are stored under
+func Tag(manifestDigest string) string {
+	return strings.Replace(manifestDigest, ":", "-", 1) + signatureTagSuffix
+}
+
+// Sign signs the manifest the tag of the image references and stores the signature next to the image in the registry.
+// Existing signatures of the manifest are kept. The digest of the signed manifest is returned
+func Sign(ctx context.Context, registry Registry, image, tag string, key crypto.Signer) (string, error) {
+	repository, err := normalizeRepository(image)
+	if err != nil {
+		return "", err
+	}
+
+	descriptor, err := registry.GetManifestDescriptor(ctx, tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "get digest of %s:%s", image, tag)
+	}
+
+	p := &payload{}
+	p.Critical.Identity.DockerReference = repository
+	p.Critical.Image.DockerManifestDigest = descriptor.Digest
+	p.Critical.Type = signatureType
+	content, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the existing signatures, unless the manifest was already signed with the same key
+	m := &ocispec.Manifest{}
+	existing, _, err := registry.GetRawManifest(ctx, Tag(descriptor.Digest))
+	if err == nil {
+		err = json.Unmarshal(existing, m)
+		if err != nil {
+			return "", errors.Wrap(err, "decode existing signatures")
+		}
+
+		for _, layer := range m.Layers {
+			if layer.Digest == digest.FromBytes(content) && verifySignature(key.Public(), content, layer.Annotations[AnnotationSignature]) == nil {
+				return descriptor.Digest, nil
+			}
+		}
+	}
+
+	signature, err := sign(key, content)
+	if err != nil {
+		return "", errors.Wrap(err, "sign payload")
+	}
+
+	m.Versioned = specs.Versioned{SchemaVersion: 2}
+	m.Config = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(emptyConfig),
+		Size:      int64(len(emptyConfig)),
+	}
+	m.Layers = append(m.Layers, ocispec.Descriptor{
+		MediaType: MediaTypeSimpleSigning,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			AnnotationSignature: base64.StdEncoding.EncodeToString(signature),
+		},
+	})
+
+	for _, blob := range [][]byte{emptyConfig, content} {
+		err = putBlob(ctx, registry, blob)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	err = registry.PutManifest(ctx, Tag(descriptor.Digest), ocispec.MediaTypeImageManifest, out)
+	if err != nil {
+		return "", errors.Wrap(err, "push signature")
+	}
+
+	return descriptor.Digest, nil
+}
+
+// Verify checks that the manifest the tag of the image references was signed with the private key of the
+// given public key. The digest of the verified manifest is returned
+func Verify(ctx context.Context, registry Registry, image, tag string, publicKey crypto.PublicKey) (string, error) {
+	repository, err := normalizeRepository(image)
+	if err != nil {
+		return "", err
+	}
+
+	descriptor, err := registry.GetManifestDescriptor(ctx, tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "get digest of %s:%s", image, tag)
+	}
+
+	out, _, err := registry.GetRawManifest(ctx, Tag(descriptor.Digest))
+	if err != nil {
+		return "", errors.Errorf("no signature found for %s:%s (%s): %v", image, tag, descriptor.Digest, err)
+	}
+
+	m := &ocispec.Manifest{}
+	err = json.Unmarshal(out, m)
+	if err != nil {
+		return "", errors.Wrap(err, "decode signatures")
+	}
+
+	for _, layer := range m.Layers {
+		if layer.MediaType != MediaTypeSimpleSigning || layer.Annotations[AnnotationSignature] == "" {
+			continue
+		}
+
+		content, err := getBlob(ctx, registry, layer.Digest)
+		if err != nil {
+			return "", err
+		}
+
+		err = verifySignature(publicKey, content, layer.Annotations[AnnotationSignature])
+		if err != nil {
+			continue
+		}
+
+		// The payload has to reference exactly this image
+		p := &payload{}
+		err = json.Unmarshal(content, p)
+		if err != nil || p.Critical.Image.DockerManifestDigest != descriptor.Digest || p.Critical.Identity.DockerReference != repository {
+			continue
+		}
+
+		return descriptor.Digest, nil
+	}
+
+	return "", errors.Errorf("no valid signature found for %s:%s (%s)", image, tag, descriptor.Digest)
+}
+
+// LoadPrivateKey reads a pem encoded ecdsa, ed25519 or rsa private key from the given file
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported private key type %s in %s. Please use an unencrypted pem encoded private key", block.Type, path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse private key %s", path)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	}
+
+	return nil, errors.Errorf("unsupported private key in %s, expected an ecdsa, ed25519 or rsa key", path)
+}
+
+// LoadPublicKey reads a pem encoded public key from the given file
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	} else if block.Type != "PUBLIC KEY" {
+		return nil, errors.Errorf("unsupported public key type %s in %s", block.Type, path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse public key %s", path)
+	}
+
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(out)
+	if block == nil {
+		return nil, errors.Errorf("no pem encoded key found in %s", path)
+	}
+
+	return block, nil
+}
+
+func sign(key crypto.Signer, content []byte) ([]byte, error) {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return key.Sign(rand.Reader, content, crypto.Hash(0))
+	}
+
+	hash := sha256.Sum256(content)
+	return key.Sign(rand.Reader, hash[:], crypto.SHA256)
+}
+
+func verifySignature(publicKey crypto.PublicKey, content []byte, encodedSignature string) error {
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return errors.Wrap(err, "decode signature")
+	}
+
+	hash := sha256.Sum256(content)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, content, signature) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return nil
+}
+
+func putBlob(ctx context.Context, registry Registry, content []byte) error {
+	blobDigest := digest.FromBytes(content)
+	exists, err := registry.BlobExists(ctx, blobDigest.String())
+	if err != nil {
+		return errors.Wrapf(err, "check blob %s", blobDigest)
+	} else if exists {
+		return nil
+	}
+
+	err = registry.PutBlob(ctx, blobDigest.String(), int64(len(content)), bytes.NewReader(content))
+	if err != nil {
+		return errors.Wrapf(err, "upload blob %s", blobDigest)
+	}
+
+	return nil
+}
+
+func getBlob(ctx context.Context, registry Registry, blobDigest digest.Digest) ([]byte, error) {
+	reader, err := registry.GetBlob(ctx, blobDigest.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "get blob %s", blobDigest)
+	}
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	} else if digest.FromBytes(content) != blobDigest {
+		return nil, errors.Errorf("content of blob %s does not match its digest", blobDigest)
+	}
+
+	return content, nil
+}
+
+func normalizeRepository(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse image %s", image)
+	}
+
+	return named.Name(), nil
+}
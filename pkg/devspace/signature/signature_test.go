@@ -0,0 +1,189 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+type fakeRegistry struct {
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		manifests: map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+}
+
+func (f *fakeRegistry) GetManifestDescriptor(ctx context.Context, tag string) (*docker.ManifestDescriptor, error) {
+	m, ok := f.manifests[tag]
+	if !ok {
+		return nil, errors.Errorf("manifest %s not found", tag)
+	}
+
+	return &docker.ManifestDescriptor{
+		Size:   int64(len(m)),
+		Digest: digest.FromBytes(m).String(),
+	}, nil
+}
+
+func (f *fakeRegistry) GetRawManifest(ctx context.Context, tagOrDigest string) ([]byte, string, error) {
+	m, ok := f.manifests[tagOrDigest]
+	if !ok {
+		return nil, "", errors.Errorf("manifest %s not found", tagOrDigest)
+	}
+
+	return m, ocispec.MediaTypeImageManifest, nil
+}
+
+func (f *fakeRegistry) BlobExists(ctx context.Context, blobDigest string) (bool, error) {
+	_, ok := f.blobs[blobDigest]
+	return ok, nil
+}
+
+func (f *fakeRegistry) GetBlob(ctx context.Context, blobDigest string) (io.ReadCloser, error) {
+	blob, ok := f.blobs[blobDigest]
+	if !ok {
+		return nil, errors.Errorf("blob %s not found", blobDigest)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (f *fakeRegistry) PutBlob(ctx context.Context, blobDigest string, size int64, content io.Reader) error {
+	out, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	} else if int64(len(out)) != size || digest.FromBytes(out).String() != blobDigest {
+		return errors.Errorf("invalid blob %s", blobDigest)
+	}
+
+	f.blobs[blobDigest] = out
+	return nil
+}
+
+func (f *fakeRegistry) PutManifest(ctx context.Context, tag string, mediaType string, manifest []byte) error {
+	f.manifests[tag] = manifest
+	return nil
+}
+
+func (f *fakeRegistry) signatures(t *testing.T, tag string) []ocispec.Descriptor {
+	m := &ocispec.Manifest{}
+	assert.NilError(t, json.Unmarshal(f.manifests[Tag(digest.FromBytes(f.manifests[tag]).String())], m))
+	return m.Layers
+}
+
+// writeKeys writes the private and the public key as pem files to the directory
+func writeKeys(t *testing.T, dir, name string, privateKey crypto.Signer) (string, string) {
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NilError(t, err)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	assert.NilError(t, err)
+
+	privateKeyPath := filepath.Join(dir, name+".key")
+	publicKeyPath := filepath.Join(dir, name+".pub")
+	assert.NilError(t, ioutil.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes}), 0600))
+	assert.NilError(t, ioutil.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}), 0644))
+	return privateKeyPath, publicKeyPath
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+
+	for _, testCase := range []struct {
+		name string
+		key  crypto.Signer
+	}{
+		{name: "ecdsa", key: ecdsaKey},
+		{name: "rsa", key: rsaKey},
+		{name: "ed25519", key: ed25519Key},
+	} {
+		privateKeyPath, publicKeyPath := writeKeys(t, dir, testCase.name, testCase.key)
+		privateKey, err := LoadPrivateKey(privateKeyPath)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		publicKey, err := LoadPublicKey(publicKeyPath)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+
+		registry := newFakeRegistry()
+		registry.manifests["v1"] = []byte(`{"schemaVersion":2}`)
+		registry.manifests["v2"] = []byte(`{"schemaVersion":2,"layers":[]}`)
+
+		_, err = Verify(context.Background(), registry, "localhost:5000/john/app", "v1", publicKey)
+		assert.ErrorContains(t, err, "no signature found", "Unsigned image was verified in testCase %s", testCase.name)
+
+		signedDigest, err := Sign(context.Background(), registry, "localhost:5000/john/app", "v1", privateKey)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.Equal(t, signedDigest, digest.FromBytes(registry.manifests["v1"]).String(), "Wrong digest in testCase %s", testCase.name)
+
+		verifiedDigest, err := Verify(context.Background(), registry, "localhost:5000/john/app", "v1", publicKey)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.Equal(t, verifiedDigest, signedDigest, "Wrong digest in testCase %s", testCase.name)
+
+		// Signing again with the same key does not add another signature
+		_, err = Sign(context.Background(), registry, "localhost:5000/john/app", "v1", privateKey)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.Equal(t, len(registry.signatures(t, "v1")), 1, "Duplicate signature in testCase %s", testCase.name)
+
+		// The signature is only valid for the signed manifest and repository
+		_, err = Verify(context.Background(), registry, "localhost:5000/john/app", "v2", publicKey)
+		assert.ErrorContains(t, err, "no signature found", "Unsigned image was verified in testCase %s", testCase.name)
+		_, err = Verify(context.Background(), registry, "localhost:5000/john/other", "v1", publicKey)
+		assert.ErrorContains(t, err, "no valid signature found", "Signature of another repository was accepted in testCase %s", testCase.name)
+	}
+}
+
+func TestVerifyWithOtherKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	registry := newFakeRegistry()
+	registry.manifests["latest"] = []byte(`{"schemaVersion":2}`)
+
+	_, err = Sign(context.Background(), registry, "john/app", "latest", otherKey)
+	assert.NilError(t, err)
+	_, err = Verify(context.Background(), registry, "john/app", "latest", key.Public())
+	assert.ErrorContains(t, err, "no valid signature found")
+
+	// Signatures of other keys are kept
+	_, err = Sign(context.Background(), registry, "john/app", "latest", key)
+	assert.NilError(t, err)
+	assert.Equal(t, len(registry.signatures(t, "latest")), 2)
+
+	_, err = Verify(context.Background(), registry, "john/app", "latest", key.Public())
+	assert.NilError(t, err)
+	_, err = Verify(context.Background(), registry, "john/app", "latest", otherKey.Public())
+	assert.NilError(t, err)
+}
@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/pkg/errors"
+)
+
+// checksumBatchSize is the amount of paths that are hashed remotely with a single request
+const checksumBatchSize = 100
+
+// checksumParallelRequests is the amount of checksum requests that are sent in parallel
+const checksumParallelRequests = 4
+
+// sameContent hashes the given files locally and remotely in parallel and returns the files
+// that have the same content on both sides
+func (s *Sync) sameContent(files []string) (map[string]bool, error) {
+	if len(files) == 0 {
+		return map[string]bool{}, nil
+	} else if s.upstream == nil {
+		return nil, errors.New("upstream is not initialized")
+	}
+
+	// cancel after 10 minutes
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	// start remote hashing
+	var (
+		remoteChecksums = make([]uint32, len(files))
+		remoteHashed    = make([]bool, len(files))
+		remoteErr       error
+		remoteErrOnce   sync.Once
+		waitGroup       sync.WaitGroup
+		requests        = make(chan struct{}, checksumParallelRequests)
+	)
+	for i := 0; i < len(files); i += checksumBatchSize {
+		end := i + checksumBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+
+		requests <- struct{}{}
+		waitGroup.Add(1)
+		go func(start, end int) {
+			defer waitGroup.Done()
+			defer func() { <-requests }()
+
+			checksums, err := s.upstream.client.Checksums(ctx, &remote.Paths{Paths: files[start:end]})
+			if err == nil && checksums == nil {
+				err = fmt.Errorf("unexpected checksum response")
+			} else if err == nil && (len(checksums.Checksums) != end-start || len(checksums.Hashed) != end-start) {
+				err = fmt.Errorf("unexpected checksum size %d != %d", len(checksums.Checksums), end-start)
+			}
+			if err != nil {
+				remoteErrOnce.Do(func() {
+					remoteErr = err
+					cancel()
+				})
+				return
+			}
+
+			copy(remoteChecksums[start:end], checksums.Checksums)
+			copy(remoteHashed[start:end], checksums.Hashed)
+		}(i, end)
+	}
+
+	// start local hashing
+	absolutePaths := make([]string, 0, len(files))
+	for _, file := range files {
+		absolutePaths = append(absolutePaths, filepath.Join(s.LocalPath, file))
+	}
+	localChecksums, errs := crc32.Checksums(absolutePaths)
+	for i, err := range errs {
+		if err != nil && !os.IsNotExist(err) {
+			s.log.Infof("Error hashing file %s: %v", files[i], err)
+		}
+	}
+
+	// wait for remote
+	waitGroup.Wait()
+	if remoteErr != nil {
+		return nil, errors.Wrap(remoteErr, "hashing remote files")
+	}
+
+	// compare checksums of the files that could be hashed on both sides
+	same := make(map[string]bool, len(files))
+	s.sameContentMutex.Lock()
+	defer s.sameContentMutex.Unlock()
+	if s.sameContentChecksums == nil {
+		s.sameContentChecksums = map[string]uint32{}
+	}
+	for i := range files {
+		if remoteHashed[i] && errs[i] == nil && remoteChecksums[i] == localChecksums[i] {
+			same[files[i]] = true
+			s.sameContentChecksums[files[i]] = localChecksums[i]
+		}
+	}
+
+	return same, nil
+}
@@ -0,0 +1,346 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/survey"
+	"github.com/pkg/errors"
+)
+
+// ConflictSuffix is appended to the local copy of a file if the keepBoth conflict strategy is used
+const ConflictSuffix = ".conflict"
+
+const (
+	conflictAnswerLocal  = "Keep the local version"
+	conflictAnswerRemote = "Keep the container version"
+	conflictAnswerBoth   = "Keep both versions"
+)
+
+// Conflict describes a file that was changed locally and in the container since it was last in sync
+type Conflict struct {
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+
+	LocalSize   int64 `json:"localSize"`
+	LocalMtime  int64 `json:"localMtime"`
+	RemoteSize  int64 `json:"remoteSize"`
+	RemoteMtime int64 `json:"remoteMtime"`
+
+	// Resolution is the strategy that was used to resolve the conflict
+	Resolution latest.ConflictStrategy `json:"resolution"`
+}
+
+// ReadConflictLog reads all conflicts from the given conflict log. If the log does not exist, nil is returned
+func ReadConflictLog(path string) ([]*Conflict, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	conflicts := []*Conflict{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		conflict := &Conflict{}
+		err = json.Unmarshal(scanner.Bytes(), conflict)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse conflict log")
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts, scanner.Err()
+}
+
+// conflictDetectionEnabled returns true if changes are synced in both directions
+func (s *Sync) conflictDetectionEnabled() bool {
+	return !s.Options.UpstreamDisabled && !s.Options.DownstreamDisabled
+}
+
+// localConflict checks if the local version of the remotely changed file was changed since it was last
+// in sync and returns the local file information if so
+func (s *Sync) localConflict(change *remote.Change) *FileInformation {
+	if change.IsDir || (s.uploadIgnoreMatcher != nil && s.uploadIgnoreMatcher.Matches(change.Path, false)) {
+		return nil
+	}
+
+	stat, err := os.Stat(filepath.Join(s.LocalPath, change.Path))
+	if err != nil || stat.IsDir() {
+		return nil
+	} else if stat.ModTime().Unix() == change.MtimeUnix && stat.Size() == change.Size {
+		return nil
+	}
+
+	s.fileIndex.fileMapMutex.Lock()
+	lastSynced := s.fileIndex.fileMap[change.Path]
+	s.fileIndex.fileMapMutex.Unlock()
+	if lastSynced != nil {
+		// if the initial sync compared by size or checksum, the mtimes of files that are in sync might differ
+		if lastSynced.IsSymbolicLink || lastSynced.Size == stat.Size() && (lastSynced.Mtime == stat.ModTime().Unix() || s.Options.InitialSyncCompareBy == latest.InitialSyncCompareBySize || s.unchangedContent(change.Path)) {
+			return nil
+		}
+	}
+
+	return &FileInformation{
+		Name:      change.Path,
+		Mtime:     stat.ModTime().Unix(),
+		MtimeNano: stat.ModTime().UnixNano(),
+		Size:      stat.Size(),
+		Mode:      stat.Mode(),
+	}
+}
+
+// unchangedContent checks if the local file still has the content that was found to be the same locally and
+// remotely, if the initial sync compared by checksum
+func (s *Sync) unchangedContent(relativePath string) bool {
+	if s.Options.InitialSyncCompareBy != latest.InitialSyncCompareByChecksum {
+		return false
+	}
+
+	s.sameContentMutex.Lock()
+	checksum, ok := s.sameContentChecksums[relativePath]
+	s.sameContentMutex.Unlock()
+	if !ok {
+		return false
+	}
+
+	localChecksum, err := crc32.Checksum(filepath.Join(s.LocalPath, relativePath))
+	return err == nil && localChecksum == checksum
+}
+
+// resolveConflict determines how the conflict between the given local and remote file should be resolved
+// and writes the conflict to the sync log and the conflict log
+func (s *Sync) resolveConflict(localFile *FileInformation, remoteFile *remote.Change) latest.ConflictStrategy {
+	resolution := s.Options.ConflictStrategy
+	if resolution == "" {
+		resolution = latest.ConflictStrategyPreferLocal
+	} else if resolution == latest.ConflictStrategyAsk {
+		resolution = s.askConflict(localFile.Name)
+	}
+
+	conflict := &Conflict{
+		Path:        localFile.Name,
+		Time:        time.Now(),
+		LocalSize:   localFile.Size,
+		LocalMtime:  localFile.Mtime,
+		RemoteSize:  remoteFile.Size,
+		RemoteMtime: remoteFile.MtimeUnix,
+		Resolution:  resolution,
+	}
+
+	s.log.Infof("Conflict - '.%s' was changed locally and in the container, resolve with strategy %s", conflict.Path, conflict.Resolution)
+	err := s.writeConflict(conflict)
+	if err != nil {
+		s.log.Infof("Error writing conflict log: %v", err)
+	}
+
+	return resolution
+}
+
+// askConflict asks the user how the conflict should be resolved. If the user cannot be asked,
+// both versions are kept
+func (s *Sync) askConflict(relativePath string) latest.ConflictStrategy {
+	if s.Options.Questioner == nil {
+		return latest.ConflictStrategyKeepBoth
+	}
+
+	s.conflictMutex.Lock()
+	defer s.conflictMutex.Unlock()
+
+	answer, err := s.Options.Questioner.Question(&survey.QuestionOptions{
+		Question:     fmt.Sprintf("Sync conflict: '.%s' was changed locally and in the container. Which version do you want to keep?", relativePath),
+		DefaultValue: conflictAnswerBoth,
+		Options:      []string{conflictAnswerLocal, conflictAnswerRemote, conflictAnswerBoth},
+	})
+	if err != nil {
+		s.log.Infof("Error asking for conflict resolution of '.%s': %v", relativePath, err)
+		return latest.ConflictStrategyKeepBoth
+	}
+
+	switch answer {
+	case conflictAnswerLocal:
+		return latest.ConflictStrategyPreferLocal
+	case conflictAnswerRemote:
+		return latest.ConflictStrategyPreferRemote
+	}
+
+	return latest.ConflictStrategyKeepBoth
+}
+
+// writeConflict appends the conflict to the conflict log
+func (s *Sync) writeConflict(conflict *Conflict) error {
+	if s.Options.ConflictLogPath == "" {
+		return nil
+	}
+
+	out, err := json.Marshal(conflict)
+	if err != nil {
+		return err
+	}
+
+	s.conflictMutex.Lock()
+	defer s.conflictMutex.Unlock()
+
+	err = os.MkdirAll(filepath.Dir(s.Options.ConflictLogPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.Options.ConflictLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(out, '\n'))
+	return err
+}
+
+// keepLocalCopy copies the local version of the file to a file with the conflict suffix, which
+// is then uploaded as a new file
+func (s *Sync) keepLocalCopy(relativePath string) error {
+	absolutePath := filepath.Join(s.LocalPath, relativePath)
+	in, err := os.Open(absolutePath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	stat, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(absolutePath+ConflictSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, stat.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return err
+	}
+
+	s.log.Infof("Conflict - Keep local version of '.%s' as '.%s%s'", relativePath, relativePath, ConflictSuffix)
+	return out.Close()
+}
+
+// resolveConflicts checks the files that should be uploaded for files that were also changed remotely since
+// they were last in sync and resolves these conflicts. Returns the files that should still be uploaded
+func (u *upstream) resolveConflicts(files []*FileInformation) ([]*FileInformation, error) {
+	lastSynced := map[string]*FileInformation{}
+	u.sync.fileIndex.fileMapMutex.Lock()
+	for _, file := range files {
+		element := u.sync.fileIndex.fileMap[file.Name]
+		if file.IsDirectory || element == nil || element.IsDirectory || element.IsSymbolicLink {
+			continue
+		} else if u.sync.downloadIgnoreMatcher != nil && u.sync.downloadIgnoreMatcher.Matches(file.Name, false) {
+			continue
+		}
+
+		lastSynced[file.Name] = element
+	}
+	u.sync.fileIndex.fileMapMutex.Unlock()
+	if len(lastSynced) == 0 {
+		return files, nil
+	}
+
+	remoteFiles, err := u.stat(lastSynced)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		upload        = make([]*FileInformation, 0, len(files))
+		forceDownload = []*remote.Change{}
+	)
+	for _, file := range files {
+		remoteFile := remoteFiles[file.Name]
+		if remoteFile == nil || remoteFile.ChangeType == remote.ChangeType_DELETE || remoteFile.IsDir {
+			upload = append(upload, file)
+			continue
+		}
+
+		// check if the remote file was changed since it was last in sync
+		element := lastSynced[file.Name]
+		if (remoteFile.MtimeUnix == element.Mtime && remoteFile.Size == element.Size) || (remoteFile.MtimeUnix == file.Mtime && remoteFile.Size == file.Size) {
+			upload = append(upload, file)
+			continue
+		}
+
+		switch u.sync.resolveConflict(file, remoteFile) {
+		case latest.ConflictStrategyPreferRemote:
+			forceDownload = append(forceDownload, remoteFile)
+		case latest.ConflictStrategyKeepBoth:
+			err := u.sync.keepLocalCopy(file.Name)
+			if err != nil {
+				u.sync.log.Infof("Upstream - Upload '.%s', because local copy failed: %v", file.Name, err)
+				upload = append(upload, file)
+				continue
+			}
+
+			forceDownload = append(forceDownload, remoteFile)
+		default:
+			upload = append(upload, file)
+		}
+	}
+
+	if len(forceDownload) > 0 {
+		err = u.sync.downstream.download(forceDownload, true)
+		if err != nil {
+			return nil, errors.Wrap(err, "download conflicting files")
+		}
+	}
+
+	return upload, nil
+}
+
+// stat retrieves the current state of the given files in the container
+func (u *upstream) stat(files map[string]*FileInformation) (map[string]*remote.Change, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+
+	remoteFiles := make(map[string]*remote.Change, len(files))
+	for i := 0; i < len(paths); i += 100 {
+		end := i + 100
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		stats, err := u.client.Stat(ctx, &remote.Paths{Paths: paths[i:end]})
+		if err != nil {
+			return nil, errors.Wrap(err, "stat remote files")
+		}
+
+		for _, change := range stats.Changes {
+			remoteFiles[change.Path] = change
+		}
+	}
+
+	return remoteFiles, nil
+}
@@ -0,0 +1,216 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/notify"
+)
+
+func TestLocalConflict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-conflict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mtime := time.Unix(1000, 0)
+	for name, content := range map[string]string{"unchanged": "abc", "changed": "local", "new": "local"} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(filepath.Join(dir, name), mtime, mtime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Sync{
+		LocalPath: dir,
+		fileIndex: newFileIndex(),
+		log:       log.Discard,
+	}
+	s.fileIndex.fileMap["/unchanged"] = &FileInformation{Name: "/unchanged", Size: 3, Mtime: 1000}
+	s.fileIndex.fileMap["/changed"] = &FileInformation{Name: "/changed", Size: 3, Mtime: 900}
+
+	remoteChange := func(path string) *remote.Change {
+		return &remote.Change{ChangeType: remote.ChangeType_CHANGE, Path: path, Size: 6, MtimeUnix: 1100}
+	}
+	if s.localConflict(remoteChange("/unchanged")) != nil {
+		t.Fatalf("Unexpected conflict for locally unchanged file")
+	}
+	if s.localConflict(remoteChange("/missing")) != nil {
+		t.Fatalf("Unexpected conflict for missing local file")
+	}
+	if localFile := s.localConflict(remoteChange("/changed")); localFile == nil || localFile.Size != 5 {
+		t.Fatalf("Expected conflict for locally changed file")
+	}
+	if s.localConflict(remoteChange("/new")) == nil {
+		t.Fatalf("Expected conflict for file created on both sides")
+	}
+	if s.localConflict(&remote.Change{ChangeType: remote.ChangeType_CHANGE, Path: "/new", Size: 5, MtimeUnix: 1000}) != nil {
+		t.Fatalf("Unexpected conflict for identical files")
+	}
+}
+
+func TestLocalConflictCompareByChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-conflict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the local file has the same content as the container file, but a different mtime
+	mtime := time.Unix(1000, 0)
+	err = ioutil.WriteFile(filepath.Join(dir, "same"), []byte("abc"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filepath.Join(dir, "same"), mtime, mtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := crc32.Checksum(filepath.Join(dir, "same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Sync{
+		LocalPath:            dir,
+		Options:              Options{InitialSyncCompareBy: latest.InitialSyncCompareByChecksum},
+		fileIndex:            newFileIndex(),
+		sameContentChecksums: map[string]uint32{"/same": checksum},
+		log:                  log.Discard,
+	}
+	s.fileIndex.fileMap["/same"] = &FileInformation{Name: "/same", Size: 3, Mtime: 900}
+
+	change := &remote.Change{ChangeType: remote.ChangeType_CHANGE, Path: "/same", Size: 6, MtimeUnix: 1100}
+	if s.localConflict(change) != nil {
+		t.Fatalf("Unexpected conflict for file with the same content on both sides")
+	}
+
+	// change the content locally without changing the size
+	err = ioutil.WriteFile(filepath.Join(dir, "same"), []byte("xyz"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filepath.Join(dir, "same"), mtime, mtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.localConflict(change) == nil {
+		t.Fatalf("Expected conflict for locally changed file")
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-conflict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &Sync{
+		LocalPath: dir,
+		Options: Options{
+			ConflictLogPath: filepath.Join(dir, "log", "conflicts.log"),
+		},
+		fileIndex: newFileIndex(),
+		log:       log.Discard,
+	}
+
+	localFile := &FileInformation{Name: "/file", Size: 5, Mtime: 1000}
+	remoteFile := &remote.Change{Path: "/file", Size: 6, MtimeUnix: 1100}
+	if resolution := s.resolveConflict(localFile, remoteFile); resolution != latest.ConflictStrategyPreferLocal {
+		t.Fatalf("Expected default resolution %s, got %s", latest.ConflictStrategyPreferLocal, resolution)
+	}
+
+	s.Options.ConflictStrategy = latest.ConflictStrategyAsk
+	if resolution := s.resolveConflict(localFile, remoteFile); resolution != latest.ConflictStrategyKeepBoth {
+		t.Fatalf("Expected resolution %s without questioner, got %s", latest.ConflictStrategyKeepBoth, resolution)
+	}
+
+	conflicts, err := ReadConflictLog(s.Options.ConflictLogPath)
+	if err != nil {
+		t.Fatalf("Error reading conflict log: %v", err)
+	} else if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts in log, got %d", len(conflicts))
+	} else if conflicts[0].Path != "/file" || conflicts[0].LocalSize != 5 || conflicts[0].RemoteMtime != 1100 || conflicts[1].Resolution != latest.ConflictStrategyKeepBoth {
+		t.Fatalf("Unexpected conflicts in log: %#+v %#+v", conflicts[0], conflicts[1])
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "file"), []byte("local"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.keepLocalCopy("/file")
+	if err != nil {
+		t.Fatalf("Error keeping local copy: %v", err)
+	}
+	out, err := ioutil.ReadFile(filepath.Join(dir, "file"+ConflictSuffix))
+	if err != nil || string(out) != "local" {
+		t.Fatalf("Unexpected local copy %s: %v", string(out), err)
+	}
+}
+
+func TestDownstreamResolveConflicts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-conflict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "file"), []byte("local"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Sync{
+		LocalPath: dir,
+		fileIndex: newFileIndex(),
+		log:       log.Discard,
+	}
+	s.upstream = &upstream{
+		events: make(chan notify.EventInfo),
+		sync:   s,
+	}
+	d := &downstream{sync: s}
+
+	// upstream checks if it is busy while the conflicting file is sent
+	received := make(chan notify.EventInfo)
+	go func() {
+		s.upstream.IsBusy()
+		received <- <-s.upstream.events
+	}()
+
+	done := make(chan struct{})
+	var download, forceDownload []*remote.Change
+	go func() {
+		download, forceDownload = d.resolveConflicts([]*remote.Change{{ChangeType: remote.ChangeType_CHANGE, Path: "/file", Size: 6, MtimeUnix: 1100}})
+		close(done)
+	}()
+
+	select {
+	case event := <-received:
+		if event.Path() != "/file" {
+			t.Fatalf("Unexpected upstream event for %s", event.Path())
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("Timeout waiting for the local file to be sent to upstream")
+	}
+	<-done
+
+	if len(download) != 0 || len(forceDownload) != 0 {
+		t.Fatalf("Expected the local version to win, got %d downloads and %d forced downloads", len(download), len(forceDownload))
+	} else if !s.upstream.IsBusy() {
+		t.Fatalf("Expected upstream to be busy")
+	}
+}
@@ -0,0 +1,245 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/delta"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// deltaChunkMaxOperations is the maximum amount of operations sent within a single chunk
+	deltaChunkMaxOperations = 1024
+
+	// deltaChunkMaxData is the maximum amount of literal bytes sent within a single chunk
+	deltaChunkMaxData = 64 * 1024
+)
+
+// applyDeltaCreates uploads all files that are big enough and already exist remotely via the
+// delta transfer and returns the files that still need to be uploaded as a complete archive.
+// Function assumes that fileMap is locked for access
+func (u *upstream) applyDeltaCreates(files []*FileInformation) ([]*FileInformation, int, error) {
+	var (
+		rest     = make([]*FileInformation, 0, len(files))
+		uploaded = 0
+
+		totalSize int64
+		sentSize  int64
+	)
+
+	for _, file := range files {
+		existing := u.sync.fileIndex.fileMap[file.Name]
+		if file.IsDirectory || file.Size < deltaTransferMinSize || existing == nil || existing.IsDirectory {
+			rest = append(rest, file)
+			continue
+		}
+
+		fileInfo, sent, err := u.uploadDelta(file.Name)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "upload delta %s", file.Name)
+		} else if fileInfo == nil {
+			rest = append(rest, file)
+			continue
+		}
+
+		if u.sync.Options.Verbose || len(files) <= 3 {
+			u.sync.log.Infof("Upstream - Upload File '%s' as delta", u.getRelativeUpstreamPath(file.Name))
+		}
+
+		u.sync.fileIndex.CreateDirInFileMap(path.Dir(fileInfo.Name))
+		u.sync.fileIndex.fileMap[fileInfo.Name] = fileInfo
+
+		totalSize += fileInfo.Size
+		sentSize += sent
+		uploaded++
+	}
+
+	if uploaded > 0 {
+		u.sync.metrics.update(func(status *Status) {
+			status.BytesUploaded += sentSize
+		})
+		u.sync.log.Infof("Upstream - Upload %d file(s) as delta (Sent ~%0.2f KB of ~%0.2f KB)", uploaded, float64(sentSize)/1024.0, float64(totalSize)/1024.0)
+	}
+
+	return rest, uploaded, nil
+}
+
+// uploadDelta retrieves the block signatures of the remote file and only sends the blocks that
+// have changed. If the remote file does not exist or was changed while the delta was sent, nil is
+// returned and the file should be uploaded completely
+func (u *upstream) uploadDelta(relativePath string) (*FileInformation, int64, error) {
+	absolutePath := filepath.Join(u.sync.LocalPath, relativePath)
+	stat, err := os.Stat(absolutePath)
+	if err != nil || !stat.Mode().IsRegular() {
+		return nil, 0, nil
+	}
+
+	signature, err := u.getSignature(relativePath)
+	if err != nil {
+		return nil, 0, err
+	} else if signature == nil {
+		return nil, 0, nil
+	}
+
+	f, err := os.Open(absolutePath)
+	if err != nil {
+		return nil, 0, nil
+	}
+	defer f.Close()
+
+	// the helper verifies the rebuilt file with the checksum of the local file
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "hash file")
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "seek file")
+	}
+
+	// cancel after 1 hour
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	uploadClient, err := u.client.UploadDelta(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "upload delta")
+	}
+
+	// the first chunk holds the file information
+	var (
+		sent      int64
+		chunkSize int64
+		dataSize  int
+		chunk     = &remote.DeltaChunk{
+			Path:      relativePath,
+			MtimeUnix: stat.ModTime().Unix(),
+			Mode:      uint32(chmodTarEntry(stat.Mode().Perm())),
+			BlockSize: signature.BlockSize,
+			Sha256:    hasher.Sum(nil),
+		}
+	)
+
+	err = delta.Diff(signature, f, func(operation *delta.Operation) error {
+		chunk.Operations = append(chunk.Operations, &remote.DeltaOperation{
+			BlockIndex: operation.BlockIndex,
+			Data:       operation.Data,
+		})
+
+		dataSize += len(operation.Data)
+		chunkSize += int64(len(operation.Data)) + 8
+		if len(chunk.Operations) < deltaChunkMaxOperations && dataSize < deltaChunkMaxData {
+			return nil
+		}
+
+		err := u.sendDeltaChunk(uploadClient, chunk, chunkSize)
+		if err != nil {
+			return errors.Wrap(err, "send delta")
+		}
+
+		sent += chunkSize
+		chunk = &remote.DeltaChunk{}
+		chunkSize = 0
+		dataSize = 0
+		return nil
+	})
+	if err != nil {
+		_, recvErr := uploadClient.CloseAndRecv()
+		if status.Code(recvErr) == codes.FailedPrecondition {
+			return u.deltaMismatch(relativePath, recvErr)
+		}
+
+		return nil, 0, err
+	}
+
+	err = u.sendDeltaChunk(uploadClient, chunk, chunkSize)
+	if err != nil {
+		_, recvErr := uploadClient.CloseAndRecv()
+		if status.Code(recvErr) == codes.FailedPrecondition {
+			return u.deltaMismatch(relativePath, recvErr)
+		} else if recvErr != nil {
+			return nil, 0, errors.Wrap(recvErr, "send delta")
+		}
+
+		return nil, 0, errors.Wrap(err, "send delta")
+	}
+
+	_, err = uploadClient.CloseAndRecv()
+	if status.Code(err) == codes.FailedPrecondition {
+		return u.deltaMismatch(relativePath, err)
+	} else if err != nil {
+		return nil, 0, errors.Wrap(err, "after upload delta")
+	}
+
+	sent += chunkSize
+	return &FileInformation{
+		Name:      relativePath,
+		Size:      stat.Size(),
+		Mtime:     stat.ModTime().Unix(),
+		MtimeNano: stat.ModTime().UnixNano(),
+		Mode:      stat.Mode(),
+	}, sent, nil
+}
+
+// deltaMismatch is called if the helper could not rebuild the file, because the remote file was
+// changed since the signature was retrieved. The file is then uploaded completely
+func (u *upstream) deltaMismatch(relativePath string, err error) (*FileInformation, int64, error) {
+	u.sync.log.Infof("Upstream - Upload file '%s' completely, because the delta could not be applied: %v", u.getRelativeUpstreamPath(relativePath), status.Convert(err).Message())
+	return nil, 0, nil
+}
+
+// sendDeltaChunk sends the given chunk as soon as the upstream limit allows to send the given amount of bytes
+func (u *upstream) sendDeltaChunk(uploadClient remote.Upstream_UploadDeltaClient, chunk *remote.DeltaChunk, size int64) error {
+	if u.deltaLimiter != nil {
+		u.deltaLimiter.Wait(size)
+	}
+
+	return uploadClient.Send(chunk)
+}
+
+// getSignature retrieves the block signatures of the given remote file
+func (u *upstream) getSignature(relativePath string) (*delta.Signature, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	signatureClient, err := u.client.Signature(ctx, &remote.SignatureRequest{Path: relativePath})
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieve signature")
+	}
+
+	var signature *delta.Signature
+	for {
+		chunk, err := signatureClient.Recv()
+		if chunk != nil && chunk.BlockSize > 0 {
+			if signature == nil {
+				signature = &delta.Signature{BlockSize: chunk.BlockSize}
+			}
+
+			for _, block := range chunk.Blocks {
+				signature.Blocks = append(signature.Blocks, delta.BlockSignature{
+					Weak:   block.Weak,
+					Strong: block.Strong,
+					Size:   int(block.Size),
+				})
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv signature")
+		}
+	}
+
+	return signature, nil
+}
@@ -10,11 +10,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/juju/ratelimit"
 	"github.com/pkg/errors"
 
 	"github.com/loft-sh/devspace/helper/remote"
-	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 )
 
 type downstream struct {
@@ -25,39 +24,29 @@ type downstream struct {
 	writer io.WriteCloser
 	client remote.DownstreamClient
 
-	unarchiver *Unarchiver
+	unarchiver      *Unarchiver
+	forceUnarchiver *Unarchiver
+
+	// detectConflicts is enabled after the initial sync is done
+	detectConflicts bool
 }
 
 const downloadFilesBufferSize = 64
 
-// newDownstream creates a new downstream handler with the given parameters
-func newDownstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*downstream, error) {
-	var (
-		clientReader io.Reader = reader
-		clientWriter io.Writer = writer
-	)
-
-	// Apply limits if specified
-	if sync.Options.DownstreamLimit > 0 {
-		clientReader = ratelimit.Reader(reader, ratelimit.NewBucketWithRate(float64(sync.Options.DownstreamLimit), sync.Options.DownstreamLimit))
-	}
-	if sync.Options.UpstreamLimit > 0 {
-		clientWriter = ratelimit.Writer(writer, ratelimit.NewBucketWithRate(float64(sync.Options.UpstreamLimit), sync.Options.UpstreamLimit))
-	}
-
-	// Create client connection
-	conn, err := util.NewClientConnection(clientReader, clientWriter)
-	if err != nil {
-		return nil, errors.Wrap(err, "new client connection")
-	}
+// watchRecheckInterval is the interval in which the change amount is rechecked while
+// waiting for more changes if the helper notifies us about changes
+const watchRecheckInterval = time.Millisecond * 100
 
+// newDownstream creates a new downstream handler with the given parameters
+func newDownstream(client remote.DownstreamClient, reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*downstream, error) {
 	return &downstream{
-		interrupt:  make(chan bool, 1),
-		sync:       sync,
-		reader:     reader,
-		writer:     writer,
-		client:     remote.NewDownstreamClient(conn),
-		unarchiver: NewUnarchiver(sync, false, sync.log),
+		interrupt:       make(chan bool, 1),
+		sync:            sync,
+		reader:          reader,
+		writer:          writer,
+		client:          client,
+		unarchiver:      NewUnarchiver(sync, false, sync.log),
+		forceUnarchiver: NewUnarchiver(sync, true, sync.log),
 	}, nil
 }
 
@@ -137,21 +126,49 @@ func (d *downstream) mainLoop() error {
 	// start pinging the underlying connection
 	d.startPing(doneChan)
 
+	// from now on the file map is the last known common state
+	d.detectConflicts = d.sync.conflictDetectionEnabled()
+
 	lastAmountChanges := int64(0)
 	recheckInterval := 1700
 	if !d.sync.Options.Polling {
 		recheckInterval = 500
 	}
 
+	// try to get notified about changes instead of polling
+	var watchChan <-chan struct{}
+	if !d.sync.Options.Polling {
+		var err error
+		watchChan, err = d.startWatch(doneChan)
+		if err != nil {
+			d.sync.log.Infof("Downstream - Watching is not available, fall back to polling: %v", err)
+			recheckInterval = 1700
+		}
+	}
+
 	var (
-		changeTimer time.Time
+		changeTimer    time.Time
+		pendingChanges int64
 	)
 	for {
+		// if we are watching and there are no pending changes, we wait until we get notified
+		var recheck <-chan time.Time
+		if watchChan == nil {
+			recheck = time.After(time.Duration(recheckInterval) * time.Millisecond)
+		} else if lastAmountChanges > 0 {
+			recheck = time.After(watchRecheckInterval)
+		}
+
 		select {
 		case <-d.interrupt:
 			return nil
-		case <-time.After(time.Duration(recheckInterval) * time.Millisecond):
-			break
+		case _, ok := <-watchChan:
+			if !ok {
+				d.sync.log.Infof("Downstream - Watching stopped, fall back to polling")
+				watchChan = nil
+				recheckInterval = 1700
+			}
+		case <-recheck:
 		}
 
 		// Check for changes remotely
@@ -186,7 +203,57 @@ func (d *downstream) mainLoop() error {
 		} else {
 			lastAmountChanges = changeAmount.Amount
 		}
+
+		if lastAmountChanges != pendingChanges {
+			pendingChanges = lastAmountChanges
+			d.sync.metrics.update(func(status *Status) {
+				status.DownstreamPending = pendingChanges
+			})
+		}
+	}
+}
+
+// startWatch starts watching for remote changes. The returned channel is notified if there are new changes
+// and is closed if watching stopped
+func (d *downstream) startWatch(doneChan chan struct{}) (<-chan struct{}, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	watchClient, err := d.client.Watch(ctx, &remote.Empty{})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the first message tells us if the helper is able to watch
+	_, err = watchClient.Recv()
+	if err != nil {
+		cancel()
+		return nil, err
 	}
+
+	watchChan := make(chan struct{}, 1)
+	go func() {
+		<-doneChan
+		cancel()
+	}()
+	go func() {
+		defer close(watchChan)
+
+		for {
+			_, err := watchClient.Recv()
+			if err != nil {
+				return
+			}
+
+			select {
+			case watchChan <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	// the initial state should be checked in any case
+	watchChan <- struct{}{}
+	return watchChan, nil
 }
 
 func (d *downstream) shouldKeep(change *remote.Change) bool {
@@ -216,6 +283,8 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 		return nil
 	}
 
+	started := time.Now()
+
 	// determine what to delete and what to download
 	for _, change := range changes {
 		if change.ChangeType == remote.ChangeType_DELETE {
@@ -228,26 +297,99 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	// Remove all files and folders that should be deleted first and we ignore errors
 	d.remove(remove, force)
 
+	// Check if there are files that were also changed locally
+	var forceDownload []*remote.Change
+	if !force && d.detectConflicts {
+		download, forceDownload = d.resolveConflicts(download)
+	}
+
 	// Extract downloaded archive
-	if len(download) > 0 {
-		for i := 0; i < syncRetries; i++ {
-			err := d.initDownload(download)
-			if err == nil {
-				break
-			} else if i+1 >= syncRetries {
-				return err
-			}
+	err := d.download(download, false)
+	if err != nil {
+		return err
+	}
+	err = d.download(forceDownload, true)
+	if err != nil {
+		return err
+	}
+
+	d.sync.metrics.update(func(status *Status) {
+		status.FilesDownloaded += int64(len(download) + len(forceDownload))
+		status.FilesRemovedLocal += int64(len(remove))
+	})
+	d.sync.metrics.addBatch(false, started)
+	d.sync.log.Infof("Downstream - Successfully processed %d change(s)", len(changes))
+	return nil
+}
 
-			d.sync.log.Infof("Downstream - Retry download because of error: %v", err)
+// resolveConflicts checks the changes for files that were also changed locally since they were last in sync and
+// resolves these conflicts. Returns the changes to download and the changes that should override local files
+func (d *downstream) resolveConflicts(changes []*remote.Change) ([]*remote.Change, []*remote.Change) {
+	var (
+		download      = make([]*remote.Change, 0, len(changes))
+		forceDownload = []*remote.Change{}
+		upload        = []*FileInformation{}
+	)
+
+	for _, change := range changes {
+		localFile := d.sync.localConflict(change)
+		if localFile == nil {
+			download = append(download, change)
+			continue
+		}
 
-			download = d.updateDownloadChanges(download)
-			if len(download) == 0 {
-				break
+		switch d.sync.resolveConflict(localFile, change) {
+		case latest.ConflictStrategyPreferRemote:
+			forceDownload = append(forceDownload, change)
+		case latest.ConflictStrategyKeepBoth:
+			err := d.sync.keepLocalCopy(change.Path)
+			if err != nil {
+				d.sync.log.Infof("Downstream - Skip download of '.%s', because local copy failed: %v", change.Path, err)
+				continue
 			}
+
+			forceDownload = append(forceDownload, change)
+		default:
+			// The container version is now the last known version, so that upstream
+			// overrides it with the local version
+			d.sync.fileIndex.fileMapMutex.Lock()
+			d.sync.fileIndex.fileMap[change.Path] = parseFileInformation(change)
+			d.sync.fileIndex.fileMapMutex.Unlock()
+
+			upload = append(upload, localFile)
 		}
 	}
 
-	d.sync.log.Infof("Downstream - Successfully processed %d change(s)", len(changes))
+	// The local versions are sent without holding the busy lock, because upstream
+	// needs that lock while it drains its events
+	for _, localFile := range upload {
+		d.sync.upstream.isBusyMutex.Lock()
+		d.sync.upstream.isBusy = true
+		d.sync.upstream.isBusyMutex.Unlock()
+
+		d.sync.upstream.events <- localFile
+	}
+
+	return download, forceDownload
+}
+
+// download downloads the given changes and retries on error. If force is true, local files are overridden
+// even if they are newer
+func (d *downstream) download(download []*remote.Change, force bool) error {
+	for i := 0; i < syncRetries && len(download) > 0; i++ {
+		err := d.initDownload(download, force)
+		if err == nil {
+			break
+		} else if i+1 >= syncRetries {
+			return err
+		}
+
+		d.sync.log.Infof("Downstream - Retry download because of error: %v", err)
+		d.sync.metrics.addRetry()
+
+		download = d.updateDownloadChanges(download)
+	}
+
 	return nil
 }
 
@@ -265,7 +407,7 @@ func (d *downstream) updateDownloadChanges(download []*remote.Change) []*remote.
 	return newChanges
 }
 
-func (d *downstream) initDownload(download []*remote.Change) error {
+func (d *downstream) initDownload(download []*remote.Change, force bool) error {
 	reader, writer := io.Pipe()
 
 	defer reader.Close()
@@ -278,7 +420,12 @@ func (d *downstream) initDownload(download []*remote.Change) error {
 
 	// Untaring all downloaded files to the right location
 	// this can be a lengthy process when we downloaded a lot of files
-	err := d.unarchiver.Untar(reader, d.sync.LocalPath)
+	unarchiver := d.unarchiver
+	if force {
+		unarchiver = d.forceUnarchiver
+	}
+
+	err := unarchiver.Untar(reader, d.sync.LocalPath)
 	if err != nil {
 		return errors.Wrap(err, "untar files")
 	}
@@ -359,6 +506,10 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 
 				return errors.Wrap(err, "write chunk")
 			}
+
+			d.sync.metrics.update(func(status *Status) {
+				status.BytesDownloaded += int64(len(chunk.Content))
+			})
 		}
 
 		if err == io.EOF {
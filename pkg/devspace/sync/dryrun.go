@@ -0,0 +1,195 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+)
+
+// Dry run resolutions of conflicts
+const (
+	DryRunResolutionUpload   = "upload"
+	DryRunResolutionDownload = "download"
+	DryRunResolutionSkip     = "skip"
+)
+
+// DryRunResult holds the changes the initial sync would apply
+type DryRunResult struct {
+	Upload       []*FileInformation
+	Download     []*FileInformation
+	DeleteRemote []*FileInformation
+	DeleteLocal  []*FileInformation
+
+	Conflicts []*DryRunConflict
+}
+
+// DryRunConflict is a file that differs locally and in the container
+type DryRunConflict struct {
+	Path string
+
+	LocalSize  int64
+	RemoteSize int64
+
+	// Resolution is either upload, download or skip
+	Resolution string
+}
+
+// DryRun compares the local and the remote state with the configured initial sync strategy and
+// returns the changes the initial sync would apply. Nothing is changed on either side.
+// The sync has to be initialized with InitSession or InitDownstream before
+func (s *Sync) DryRun() (*DryRunResult, error) {
+	if s.downstream == nil {
+		return nil, errors.New("downstream is not initialized")
+	}
+
+	err := s.downstream.populateFileMap()
+	if err != nil {
+		return nil, errors.Wrap(err, "populate file map")
+	}
+
+	var (
+		result        = &DryRunResult{}
+		resultMutex   sync.Mutex
+		conflicts     = map[string]*DryRunConflict{}
+		upstreamDone  = make(chan struct{})
+		remoteChanges = s.remoteState()
+	)
+
+	initialSync := newInitialSyncer(&initialSyncOptions{
+		LocalPath: s.LocalPath,
+		Strategy:  s.Options.InitialSync,
+		CompareBy: s.Options.InitialSyncCompareBy,
+
+		IgnoreMatcher:         s.ignoreMatcher,
+		DownloadIgnoreMatcher: s.downloadIgnoreMatcher,
+		UploadIgnoreMatcher:   s.uploadIgnoreMatcher,
+
+		UpstreamDisabled:   s.Options.UpstreamDisabled,
+		DownstreamDisabled: s.Options.DownstreamDisabled,
+		FileIndex:          s.fileIndex,
+		Snapshot:           s.snapshot,
+		SnapshotChecksums:  s.snapshotChecksums,
+		SameContent:        s.sameContent,
+
+		ApplyRemote: func(changes []*FileInformation, remove bool) {
+			s.fileIndex.fileMapMutex.Lock()
+			defer s.fileIndex.fileMapMutex.Unlock()
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+
+			if remove {
+				for _, change := range changes {
+					// use the remote file information, so that the size is known
+					if element := s.fileIndex.fileMap[change.Name]; element != nil {
+						change = element
+					}
+
+					result.DeleteRemote = append(result.DeleteRemote, change)
+				}
+				return
+			}
+
+			for _, change := range changes {
+				if s.shouldUploadInitially(change) {
+					result.Upload = append(result.Upload, change)
+				}
+			}
+		},
+		ApplyLocal: func(changes []*remote.Change, force bool) error {
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+
+			for _, change := range changes {
+				if change.ChangeType == remote.ChangeType_DELETE {
+					result.DeleteLocal = append(result.DeleteLocal, parseFileInformation(change))
+				} else {
+					result.Download = append(result.Download, parseFileInformation(change))
+				}
+			}
+
+			return nil
+		},
+		OnConflict: func(relativePath string, localFile, remoteFile *FileInformation) {
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+
+			conflict := &DryRunConflict{Path: relativePath}
+			if localFile != nil {
+				conflict.LocalSize = localFile.Size
+			}
+			if remoteFile != nil {
+				conflict.RemoteSize = remoteFile.Size
+			}
+			conflicts[relativePath] = conflict
+		},
+		AddSymlink: s.dryRunSymlink,
+		Log:        s.log,
+
+		UpstreamDone: func() {
+			close(upstreamDone)
+		},
+		DownstreamDone: func() {},
+	})
+
+	err = initialSync.Run(remoteChanges)
+	if err != nil {
+		return nil, err
+	}
+	<-upstreamDone
+
+	// the resolution of a conflict is what actually happens to the file
+	uploads := map[string]bool{}
+	for _, file := range result.Upload {
+		uploads[file.Name] = true
+	}
+	downloads := map[string]bool{}
+	for _, file := range result.Download {
+		downloads[file.Name] = true
+	}
+	for _, conflict := range conflicts {
+		conflict.Resolution = DryRunResolutionSkip
+		if uploads[conflict.Path] {
+			conflict.Resolution = DryRunResolutionUpload
+		} else if downloads[conflict.Path] {
+			conflict.Resolution = DryRunResolutionDownload
+		}
+
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+
+	result.sort()
+	return result, nil
+}
+
+// dryRunSymlink resolves the symlink like the upstream, but doesn't watch the target
+func (s *Sync) dryRunSymlink(relativePath, absPath string) (os.FileInfo, error) {
+	targetPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	stat, err := os.Stat(targetPath)
+	if err != nil {
+		return nil, nil
+	} else if s.ignoreMatcher != nil && s.ignoreMatcher.Matches(relativePath, stat.IsDir()) {
+		return nil, nil
+	}
+
+	return stat, nil
+}
+
+func (r *DryRunResult) sort() {
+	for _, files := range [][]*FileInformation{r.Upload, r.Download, r.DeleteRemote, r.DeleteLocal} {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Name < files[j].Name
+		})
+	}
+
+	sort.Slice(r.Conflicts, func(i, j int) bool {
+		return r.Conflicts[i].Path < r.Conflicts[j].Path
+	})
+}
@@ -0,0 +1,180 @@
+//go:build !windows
+// +build !windows
+
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+)
+
+func TestDryRun(t *testing.T) {
+	remote, local, outside := initTestDirs(t)
+	defer os.RemoveAll(remote)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(outside)
+
+	files := map[string]string{
+		filepath.Join(local, "local.txt"):   "local",
+		filepath.Join(local, "both.txt"):    "local version",
+		filepath.Join(remote, "both.txt"):   "remote",
+		filepath.Join(remote, "remote.txt"): "remote",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(name, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncClient, err := createTestSyncClient(local, testCaseList{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syncClient.Stop(nil)
+	syncClient.Options.InitialSync = latest.InitialSyncStrategyMirrorLocal
+
+	downClientReader, downClientWriter, _ := os.Pipe()
+	downServerReader, downServerWriter, _ := os.Pipe()
+	defer downClientReader.Close()
+	defer downClientWriter.Close()
+	defer downServerReader.Close()
+	defer downServerWriter.Close()
+
+	go func() {
+		err := server.StartDownstreamServer(downServerReader, downClientWriter, &server.DownstreamOptions{
+			RemotePath:  remote,
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = syncClient.InitDownstream(downClientReader, downServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := syncClient.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Upload) != 2 || result.Upload[0].Name != "/both.txt" || result.Upload[1].Name != "/local.txt" {
+		t.Fatalf("Unexpected uploads: %v", result.Upload)
+	} else if len(result.DeleteRemote) != 1 || result.DeleteRemote[0].Name != "/remote.txt" || result.DeleteRemote[0].Size != 6 {
+		t.Fatalf("Unexpected remote deletes: %v", result.DeleteRemote)
+	} else if len(result.Download) != 0 || len(result.DeleteLocal) != 0 {
+		t.Fatalf("Unexpected local changes: %v %v", result.Download, result.DeleteLocal)
+	} else if len(result.Conflicts) != 1 || *result.Conflicts[0] != (DryRunConflict{Path: "/both.txt", LocalSize: 13, RemoteSize: 6, Resolution: DryRunResolutionUpload}) {
+		t.Fatalf("Unexpected conflicts: %v", result.Conflicts)
+	}
+
+	// nothing should have changed
+	for name, content := range files {
+		out, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(out) != content {
+			t.Fatalf("Dry run changed %s", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(remote, "local.txt")); !os.IsNotExist(err) {
+		t.Fatal("Dry run uploaded local.txt")
+	}
+}
+
+func TestDryRunCompareByChecksum(t *testing.T) {
+	remote, local, outside := initTestDirs(t)
+	defer os.RemoveAll(remote)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(outside)
+
+	// all files have the same size, but a different mtime
+	files := map[string]string{
+		filepath.Join(local, "same.txt"):     "content",
+		filepath.Join(remote, "same.txt"):    "content",
+		filepath.Join(local, "empty.txt"):    "",
+		filepath.Join(remote, "empty.txt"):   "",
+		filepath.Join(local, "changed.txt"):  "local  ",
+		filepath.Join(remote, "changed.txt"): "remote ",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(name, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	for _, name := range []string{"same.txt", "empty.txt", "changed.txt"} {
+		err := os.Chtimes(filepath.Join(remote, name), past, past)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncClient, err := createTestSyncClient(local, testCaseList{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syncClient.Stop(nil)
+	syncClient.Options.InitialSync = latest.InitialSyncStrategyPreferLocal
+	syncClient.Options.InitialSyncCompareBy = latest.InitialSyncCompareByChecksum
+
+	upClientReader, upClientWriter, _ := os.Pipe()
+	upServerReader, upServerWriter, _ := os.Pipe()
+	downClientReader, downClientWriter, _ := os.Pipe()
+	downServerReader, downServerWriter, _ := os.Pipe()
+	for _, f := range []*os.File{upClientReader, upClientWriter, upServerReader, upServerWriter, downClientReader, downClientWriter, downServerReader, downServerWriter} {
+		defer f.Close()
+	}
+
+	go func() {
+		err := server.StartUpstreamServer(upServerReader, upClientWriter, &server.UpstreamOptions{
+			UploadPath:  remote,
+			ExludePaths: []string{},
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+	go func() {
+		err := server.StartDownstreamServer(downServerReader, downClientWriter, &server.DownstreamOptions{
+			RemotePath:  remote,
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = syncClient.InitUpstream(upClientReader, upServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syncClient.InitDownstream(downClientReader, downServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := syncClient.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Upload) != 1 || result.Upload[0].Name != "/changed.txt" {
+		t.Fatalf("Unexpected uploads: %v", result.Upload)
+	} else if len(result.Download) != 0 || len(result.DeleteRemote) != 0 || len(result.DeleteLocal) != 0 {
+		t.Fatalf("Unexpected changes: %v %v %v", result.Download, result.DeleteRemote, result.DeleteLocal)
+	} else if len(result.Conflicts) != 1 || result.Conflicts[0].Path != "/changed.txt" {
+		t.Fatalf("Unexpected conflicts: %v", result.Conflicts)
+	}
+}
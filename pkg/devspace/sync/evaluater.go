@@ -73,7 +73,7 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 		}
 
 		// File did not change or was changed by downstream
-		if fileInformation.Mtime == s.fileIndex.fileMap[fileInformation.Name].Mtime && fileInformation.Size == s.fileIndex.fileMap[fileInformation.Name].Size {
+		if fileInformation.Mtime == s.fileIndex.fileMap[fileInformation.Name].Mtime && fileInformation.Size == s.fileIndex.fileMap[fileInformation.Name].Size && !modeChanged(s, fileInformation.Mode, s.fileIndex.fileMap[fileInformation.Name]) {
 			return false
 		}
 	}
@@ -98,6 +98,11 @@ func shouldDownload(change *remote.Change, s *Sync) bool {
 			if change.MtimeUnix == s.fileIndex.fileMap[change.Path].Mtime && change.Size != s.fileIndex.fileMap[change.Path].Size {
 				return true
 			}
+
+			// Redownload file if only the permission bits changed
+			if change.MtimeUnix == s.fileIndex.fileMap[change.Path].Mtime && change.Mode != 0 && modeChanged(s, os.FileMode(change.Mode), s.fileIndex.fileMap[change.Path]) {
+				return true
+			}
 		}
 
 		return false
@@ -165,3 +170,13 @@ func shouldRemoveLocal(absFilepath string, fileInformation *FileInformation, s *
 
 	return false
 }
+
+// modeChanged checks if the permission bits of a file differ from the tracked file. Modes are only compared
+// if they are preserved and known for the tracked file
+func modeChanged(s *Sync, mode os.FileMode, tracked *FileInformation) bool {
+	if !s.Options.PreserveModes || tracked == nil || tracked.IsDirectory || tracked.Mode == 0 {
+		return false
+	}
+
+	return mode.Perm() != tracked.Mode.Perm()
+}
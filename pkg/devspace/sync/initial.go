@@ -16,6 +16,10 @@ import (
 
 type initialSyncer struct {
 	o *initialSyncOptions
+
+	// sameContent are the files that differ in mtime, but have the same content locally
+	// and remotely. Only set if compared by checksum
+	sameContent map[string]bool
 }
 
 type initialSyncOptions struct {
@@ -32,10 +36,26 @@ type initialSyncOptions struct {
 	DownstreamDisabled bool
 	FileIndex          *fileIndex
 
+	// Snapshot is the persisted state of the last sync. If set, only the changes
+	// since then are reconciled
+	Snapshot map[string]*FileInformation
+
+	// SnapshotChecksums are the checksums of the local files of the snapshot. Local files
+	// whose mtime changed since the last sync, but whose content did not, are not uploaded
+	SnapshotChecksums map[string]uint32
+
 	ApplyRemote func(changes []*FileInformation, remove bool)
 	ApplyLocal  func(changes []*remote.Change, force bool) error
 	AddSymlink  func(relativePath, absPath string) (os.FileInfo, error)
 
+	// SameContent returns the given files that have the same content locally and remotely.
+	// It is required if compared by checksum
+	SameContent func(files []string) (map[string]bool, error)
+
+	// OnConflict is called for every path that differs locally and remotely and needs
+	// to be resolved by the strategy. Local or remote is nil if the path was deleted
+	OnConflict func(relativePath string, local, remote *FileInformation)
+
 	UpstreamDone   func()
 	DownstreamDone func()
 
@@ -51,6 +71,18 @@ func newInitialSyncer(options *initialSyncOptions) *initialSyncer {
 }
 
 func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
+	if i.o.Snapshot != nil {
+		return i.reconcile(remoteState)
+	}
+
+	// Compare the content of files that only differ in mtime
+	if i.o.CompareBy == latest.InitialSyncCompareByChecksum {
+		err := i.compareContent(remoteState)
+		if err != nil {
+			return errors.Wrap(err, "compare checksums")
+		}
+	}
+
 	// Here we calculate the delta between the remote and local state, the result of this operation
 	// are files we should download (new and override) and files we should upload (new and override)
 	download := remoteState
@@ -140,6 +172,7 @@ func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
 							MtimeUnix:     element.Mtime,
 							MtimeUnixNano: element.MtimeNano,
 							Size:          element.Size,
+							Mode:          uint32(element.Mode.Perm()),
 							IsDir:         element.IsDirectory,
 						})
 					}
@@ -159,6 +192,7 @@ func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
 						MtimeUnix:     element.Mtime,
 						MtimeUnixNano: element.MtimeNano,
 						Size:          element.Size,
+						Mode:          uint32(element.Mode.Perm()),
 						IsDir:         element.IsDirectory,
 					})
 				}
@@ -175,6 +209,36 @@ func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
 	return nil
 }
 
+// compareContent hashes all files that exist locally and remotely with the same size, but
+// a different mtime
+func (i *initialSyncer) compareContent(remoteState map[string]*FileInformation) error {
+	files := []string{}
+	for _, element := range remoteState {
+		if element.IsDirectory || element.IsSymbolicLink {
+			continue
+		}
+
+		stat, err := os.Lstat(path.Join(i.o.LocalPath, element.Name))
+		if err != nil || !stat.Mode().IsRegular() || stat.Size() != element.Size || stat.ModTime().Unix() == element.Mtime {
+			continue
+		}
+
+		files = append(files, element.Name)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	i.o.Log.Infof("Initial Sync - Compare checksums of %d file(s)", len(files))
+	sameContent, err := i.o.SameContent(files)
+	if err != nil {
+		return err
+	}
+
+	i.sameContent = sameContent
+	return nil
+}
+
 func (i *initialSyncer) CalculateDelta(remoteState map[string]*FileInformation) ([]*FileInformation, error) {
 	strategy := i.o.Strategy
 	if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
@@ -369,10 +433,16 @@ func (i *initialSyncer) decide(fileInformation *FileInformation, strategy latest
 				return noAction
 			} else if i.o.CompareBy == latest.InitialSyncCompareBySize {
 				return noAction
+			} else if i.o.CompareBy == latest.InitialSyncCompareByChecksum && i.sameContent[fileInformation.Name] {
+				return noAction
 			}
 		}
 
 		// Okay we have a conflict so now we decide based on the given strategy
+		if i.o.OnConflict != nil {
+			i.o.OnConflict(fileInformation.Name, fileInformation, i.o.FileIndex.fileMap[fileInformation.Name])
+		}
+
 		switch strategy {
 		case latest.InitialSyncStrategyPreferLocal:
 			return uploadAction
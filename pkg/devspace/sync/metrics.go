@@ -0,0 +1,249 @@
+package sync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// persistStatusInterval is the interval in which the sync status is written to disk
+var persistStatusInterval = time.Second * 2
+
+// logMetricsInterval is the interval in which the sync metrics are printed to the sync log
+var logMetricsInterval = time.Minute
+
+// Status is a snapshot of the sync metrics
+type Status struct {
+	LocalPath string `json:"localPath"`
+
+	Started   time.Time `json:"started"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Stopped   bool      `json:"stopped,omitempty"`
+
+	BytesUploaded   int64 `json:"bytesUploaded"`
+	BytesDownloaded int64 `json:"bytesDownloaded"`
+
+	FilesUploaded      int64 `json:"filesUploaded"`
+	FilesDownloaded    int64 `json:"filesDownloaded"`
+	FilesRemovedRemote int64 `json:"filesRemovedRemote"`
+	FilesRemovedLocal  int64 `json:"filesRemovedLocal"`
+
+	UploadBatches   int64 `json:"uploadBatches"`
+	DownloadBatches int64 `json:"downloadBatches"`
+
+	// LastBatchLatency and AverageBatchLatency are the durations in milliseconds
+	// it took to apply a batch of changes
+	LastBatchLatency    int64 `json:"lastBatchLatencyMs"`
+	AverageBatchLatency int64 `json:"averageBatchLatencyMs"`
+
+	Retries int64 `json:"retries"`
+
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+
+	// UpstreamQueue is the amount of local events that wait to be processed
+	UpstreamQueue int64 `json:"upstreamQueue"`
+
+	// DownstreamPending is the amount of remote changes that wait to be downloaded
+	DownstreamPending int64 `json:"downstreamPending"`
+}
+
+// metrics holds the counters of a sync
+type metrics struct {
+	mutex sync.Mutex
+
+	status       Status
+	totalLatency time.Duration
+	changed      bool
+}
+
+func newMetrics(localPath string) *metrics {
+	return &metrics{
+		status: Status{
+			LocalPath: localPath,
+			Started:   time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+}
+
+func (m *metrics) update(fn func(status *Status)) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	fn(&m.status)
+	m.status.UpdatedAt = time.Now()
+	m.changed = true
+}
+
+// addBatch records a processed batch of changes
+func (m *metrics) addBatch(upload bool, started time.Time) {
+	latency := time.Since(started)
+	m.update(func(status *Status) {
+		if upload {
+			status.UploadBatches++
+		} else {
+			status.DownloadBatches++
+		}
+
+		m.totalLatency += latency
+		status.LastBatchLatency = latency.Milliseconds()
+		status.AverageBatchLatency = (m.totalLatency / time.Duration(status.UploadBatches+status.DownloadBatches)).Milliseconds()
+	})
+}
+
+func (m *metrics) addRetry() {
+	m.update(func(status *Status) {
+		status.Retries++
+	})
+}
+
+func (m *metrics) setError(err error) {
+	now := time.Now()
+	m.update(func(status *Status) {
+		status.LastError = err.Error()
+		status.LastErrorTime = &now
+	})
+}
+
+// snapshot returns a copy of the current status and resets the changed flag if reset is true
+func (m *metrics) snapshot(reset bool) (*Status, bool) {
+	if m == nil {
+		return &Status{}, false
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	status := m.status
+	changed := m.changed
+	if reset {
+		m.changed = false
+	}
+
+	return &status, changed
+}
+
+// Status returns the current metrics of the sync
+func (s *Sync) Status() *Status {
+	status, _ := s.metrics.snapshot(false)
+	s.fillQueues(status)
+	return status
+}
+
+func (s *Sync) fillQueues(status *Status) {
+	if s.upstream != nil {
+		status.UpstreamQueue = int64(len(s.upstream.events))
+	}
+}
+
+// ReadStatus reads the sync status from the given path
+func ReadStatus(path string) (*Status, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+	err = json.Unmarshal(out, status)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal sync status")
+	}
+
+	return status, nil
+}
+
+// saveStatus writes the current status to the status path
+func (s *Sync) saveStatus(status *Status) error {
+	if s.Options.StatusPath == "" {
+		return nil
+	}
+
+	out, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Options.StatusPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so readers never see a partial status
+	tempPath := s.Options.StatusPath + ".tmp"
+	err = ioutil.WriteFile(tempPath, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, s.Options.StatusPath)
+}
+
+// startMetricsReporter writes the sync status in a regular interval and prints the metrics
+// to the sync log if they changed
+func (s *Sync) startMetricsReporter() {
+	go func() {
+		persistTicker := time.NewTicker(persistStatusInterval)
+		defer persistTicker.Stop()
+		logTicker := time.NewTicker(logMetricsInterval)
+		defer logTicker.Stop()
+
+		initial, _ := s.metrics.snapshot(false)
+		logged := initial.UpdatedAt
+		for {
+			select {
+			case <-s.stopped:
+				return
+			case <-persistTicker.C:
+				status, changed := s.metrics.snapshot(true)
+				if !changed {
+					continue
+				}
+
+				s.fillQueues(status)
+				err := s.saveStatus(status)
+				if err != nil {
+					s.log.Infof("Error persisting sync status: %v", err)
+				}
+			case <-logTicker.C:
+				status := s.Status()
+				if !status.UpdatedAt.After(logged) {
+					continue
+				}
+
+				logged = status.UpdatedAt
+				s.logMetrics(status)
+			}
+		}
+	}()
+}
+
+func (s *Sync) logMetrics(status *Status) {
+	s.log.Infof("Sync metrics - Uploaded %d file(s) (~%0.2f KB), downloaded %d file(s) (~%0.2f KB), avg batch latency %dms, %d retries, queue %d/%d", status.FilesUploaded, float64(status.BytesUploaded)/1024.0, status.FilesDownloaded, float64(status.BytesDownloaded)/1024.0, status.AverageBatchLatency, status.Retries, status.UpstreamQueue, status.DownstreamPending)
+}
+
+// stopMetrics persists the final status of a stopped sync
+func (s *Sync) stopMetrics() {
+	s.metrics.update(func(status *Status) {
+		status.Stopped = true
+	})
+
+	status := s.Status()
+	if status.UploadBatches+status.DownloadBatches > 0 {
+		s.logMetrics(status)
+	}
+
+	err := s.saveStatus(status)
+	if err != nil {
+		s.log.Infof("Error persisting sync status: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+func TestSaveAndReadStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &Sync{
+		Options: Options{
+			StatusPath: filepath.Join(dir, "status", "sync-status.json"),
+		},
+		metrics: newMetrics(dir),
+		log:     log.Discard,
+	}
+
+	s.metrics.update(func(status *Status) {
+		status.BytesUploaded += 1024
+		status.FilesUploaded += 2
+	})
+	s.metrics.addBatch(true, time.Now().Add(-time.Second))
+	s.metrics.addBatch(false, time.Now().Add(-time.Second*3))
+	s.metrics.addRetry()
+	s.metrics.setError(errors.New("connection lost"))
+
+	status, changed := s.metrics.snapshot(true)
+	if !changed {
+		t.Fatalf("Expected metrics to be changed")
+	} else if status.UploadBatches != 1 || status.DownloadBatches != 1 || status.Retries != 1 {
+		t.Fatalf("Unexpected counters: %#v", status)
+	} else if status.LastBatchLatency < 3000 || status.AverageBatchLatency < 2000 || status.AverageBatchLatency >= status.LastBatchLatency {
+		t.Fatalf("Unexpected latencies: last %dms, average %dms", status.LastBatchLatency, status.AverageBatchLatency)
+	}
+
+	_, changed = s.metrics.snapshot(true)
+	if changed {
+		t.Fatalf("Expected metrics to be unchanged after snapshot")
+	}
+
+	s.stopMetrics()
+	read, err := ReadStatus(s.Options.StatusPath)
+	if err != nil {
+		t.Fatalf("Error reading status: %v", err)
+	} else if !read.Stopped || read.BytesUploaded != 1024 || read.FilesUploaded != 2 || read.LastError != "connection lost" || read.LastErrorTime == nil {
+		t.Fatalf("Read status does not match saved status: %#v", read)
+	}
+
+	_, err = ReadStatus(filepath.Join(dir, "missing.json"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected not exist error for a missing status, got %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/pkg/errors"
+)
+
+// reconcile compares the local and remote state against the persisted snapshot of the last
+// sync and only applies the changes that happened since then. If a path was changed on both
+// sides, the initial sync strategy decides which side wins
+func (i *initialSyncer) reconcile(remoteState map[string]*FileInformation) error {
+	i.o.Log.Infof("Found persisted sync state with %d files, reconcile changes since the last sync", len(i.o.Snapshot))
+
+	localState := make(map[string]*FileInformation)
+	hasChildren := make(map[string]bool)
+	err := i.walkLocal(i.o.LocalPath, localState, hasChildren)
+	if err != nil {
+		return errors.Wrap(err, "walk local")
+	}
+
+	// collect all paths and sort them, so that parents are processed before their children
+	allPaths := make(map[string]bool, len(localState)+len(remoteState))
+	for _, state := range []map[string]*FileInformation{localState, remoteState, i.o.Snapshot} {
+		for p := range state {
+			allPaths[p] = true
+		}
+	}
+	paths := make([]string, 0, len(allPaths))
+	for p := range allPaths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var (
+		upload       = []*FileInformation{}
+		removeRemote = []*FileInformation{}
+		download     = []*remote.Change{}
+		removeLocal  = []*remote.Change{}
+
+		removedDirs = []string{}
+	)
+
+	for _, p := range paths {
+		if isChildOf(p, removedDirs) {
+			continue
+		}
+
+		var (
+			localFile  = localState[p]
+			remoteFile = remoteState[p]
+			oldFile    = i.o.Snapshot[p]
+			isDir      = (localFile != nil && localFile.IsDirectory) || (remoteFile != nil && remoteFile.IsDirectory) || (oldFile != nil && oldFile.IsDirectory)
+		)
+		if i.o.IgnoreMatcher != nil && i.o.IgnoreMatcher.Matches(p, isDir) {
+			continue
+		}
+
+		localChanged := !sameFile(localFile, oldFile) && !i.sameChecksum(p, localFile, oldFile)
+		remoteChanged := !sameFile(remoteFile, oldFile)
+		if i.o.UploadIgnoreMatcher != nil && i.o.UploadIgnoreMatcher.Matches(p, isDir) {
+			localChanged = false
+		}
+		if i.o.DownloadIgnoreMatcher != nil && i.o.DownloadIgnoreMatcher.Matches(p, isDir) {
+			remoteChanged = false
+		}
+		if !localChanged && !remoteChanged {
+			continue
+		}
+
+		// changed on both sides
+		if localChanged && remoteChanged {
+			if sameFile(localFile, remoteFile) {
+				continue
+			}
+			if i.o.OnConflict != nil {
+				i.o.OnConflict(p, localFile, remoteFile)
+			}
+
+			switch i.decideConflict(localFile, remoteFile) {
+			case uploadAction:
+				remoteChanged = false
+			case downloadAction:
+				localChanged = false
+			default:
+				continue
+			}
+		}
+
+		if localChanged {
+			if localFile == nil {
+				if remoteFile != nil {
+					removeRemote = append(removeRemote, &FileInformation{
+						Name:        p,
+						IsDirectory: remoteFile.IsDirectory,
+					})
+					if remoteFile.IsDirectory {
+						removedDirs = append(removedDirs, p)
+					}
+				}
+			} else if !localFile.IsDirectory || (!hasChildren[p] && remoteFile == nil) {
+				upload = append(upload, localFile)
+			}
+		} else {
+			if remoteFile == nil {
+				if localFile != nil {
+					removeLocal = append(removeLocal, fileInformationToChange(localFile, remote.ChangeType_DELETE))
+					if localFile.IsDirectory {
+						removedDirs = append(removedDirs, p)
+					}
+				}
+			} else if !remoteFile.IsDirectory || localFile == nil {
+				download = append(download, fileInformationToChange(remoteFile, remote.ChangeType_CHANGE))
+			}
+		}
+	}
+
+	i.o.Log.Infof("Reconcile: %d upload(s), %d remote remove(s), %d download(s), %d local remove(s)", len(upload), len(removeRemote), len(download), len(removeLocal))
+
+	// Upstream
+	go func() {
+		if !i.o.UpstreamDisabled {
+			if len(removeRemote) > 0 {
+				i.o.ApplyRemote(removeRemote, true)
+			}
+			if len(upload) > 0 {
+				i.o.ApplyRemote(upload, false)
+			}
+		}
+
+		i.o.UpstreamDone()
+	}()
+
+	// Downstream
+	if !i.o.DownstreamDisabled {
+		if len(removeLocal) > 0 {
+			err = i.o.ApplyLocal(removeLocal, true)
+			if err != nil {
+				return errors.Wrap(err, "apply changes")
+			}
+		}
+		if len(download) > 0 {
+			err = i.o.ApplyLocal(download, false)
+			if err != nil {
+				return errors.Wrap(err, "apply changes")
+			}
+		}
+	}
+
+	i.o.DownstreamDone()
+	return nil
+}
+
+// decideConflict decides based on the strategy which side wins if a path was changed on both sides
+func (i *initialSyncer) decideConflict(local, remote *FileInformation) action {
+	switch i.o.Strategy {
+	case latest.InitialSyncStrategyMirrorLocal, latest.InitialSyncStrategyPreferLocal:
+		return uploadAction
+	case latest.InitialSyncStrategyMirrorRemote, latest.InitialSyncStrategyPreferRemote:
+		return downloadAction
+	case latest.InitialSyncStrategyPreferNewest:
+		if local == nil {
+			return downloadAction
+		} else if remote == nil || local.Mtime > remote.Mtime {
+			return uploadAction
+		} else if local.Mtime < remote.Mtime {
+			return downloadAction
+		}
+	}
+
+	return noAction
+}
+
+// walkLocal retrieves the local state of all files and folders that are not excluded
+func (i *initialSyncer) walkLocal(absPath string, state map[string]*FileInformation, hasChildren map[string]bool) error {
+	files, err := ioutil.ReadDir(absPath)
+	if err != nil {
+		i.o.Log.Infof("Couldn't read dir %s: %v", absPath, err)
+		return nil
+	}
+
+	relativeDir := getRelativeFromFullPath(absPath, i.o.LocalPath)
+	for _, f := range files {
+		childAbsPath := filepath.Join(absPath, f.Name())
+		relativePath := getRelativeFromFullPath(childAbsPath, i.o.LocalPath)
+
+		// We skip files that are suddenly not there anymore
+		stat, err := os.Stat(childAbsPath)
+		if err != nil {
+			continue
+		} else if i.o.IgnoreMatcher != nil && !i.o.IgnoreMatcher.RequireFullScan() && i.o.IgnoreMatcher.Matches(relativePath, stat.IsDir()) {
+			continue
+		}
+
+		// Check for symlinks
+		if f.Mode()&os.ModeSymlink != 0 {
+			stat, err = i.o.AddSymlink(relativePath, childAbsPath)
+			if err != nil {
+				return err
+			} else if stat == nil {
+				continue
+			}
+		}
+
+		hasChildren[relativeDir] = true
+		state[relativePath] = &FileInformation{
+			Name:        relativePath,
+			Mtime:       stat.ModTime().Unix(),
+			MtimeNano:   stat.ModTime().UnixNano(),
+			Size:        stat.Size(),
+			Mode:        stat.Mode(),
+			IsDirectory: stat.IsDir(),
+		}
+
+		if stat.IsDir() {
+			err = i.walkLocal(childAbsPath, state, hasChildren)
+			if err != nil {
+				return errors.Wrap(err, f.Name())
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameChecksum checks if a local file that was touched since the last sync still has the
+// content of the snapshot by comparing it with the persisted checksum
+func (i *initialSyncer) sameChecksum(p string, localFile, oldFile *FileInformation) bool {
+	if localFile == nil || oldFile == nil || localFile.IsDirectory || oldFile.IsDirectory || localFile.Size != oldFile.Size {
+		return false
+	}
+
+	checksum, ok := i.o.SnapshotChecksums[p]
+	if !ok {
+		return false
+	}
+
+	localChecksum, err := crc32.Checksum(filepath.Join(i.o.LocalPath, p))
+	if err != nil {
+		return false
+	}
+
+	return localChecksum == checksum
+}
+
+// sameFile checks if two file informations describe the same file
+func sameFile(a, b *FileInformation) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	} else if a.IsDirectory || b.IsDirectory {
+		return a.IsDirectory == b.IsDirectory
+	}
+
+	return a.Size == b.Size && a.Mtime == b.Mtime
+}
+
+func isChildOf(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fileInformationToChange(fileInformation *FileInformation, changeType remote.ChangeType) *remote.Change {
+	return &remote.Change{
+		ChangeType:    changeType,
+		Path:          fileInformation.Name,
+		MtimeUnix:     fileInformation.Mtime,
+		MtimeUnixNano: fileInformation.MtimeNano,
+		Size:          fileInformation.Size,
+		Mode:          uint32(fileInformation.Mode.Perm()),
+		IsDir:         fileInformation.IsDirectory,
+	}
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestReconcileRemoteChanges(t *testing.T) {
+	localPath, err := ioutil.TempDir("", "sync-reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(localPath)
+
+	synced := time.Unix(time.Now().Unix()-3600, 0)
+	snapshot := map[string]*FileInformation{}
+	for _, name := range []string{"unchanged", "removedRemote", "changedRemote", "changedBoth"} {
+		err = ioutil.WriteFile(filepath.Join(localPath, name), []byte("content"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(filepath.Join(localPath, name), synced, synced)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot["/"+name] = &FileInformation{Name: "/" + name, Size: 7, Mtime: synced.Unix()}
+	}
+
+	// change a file locally that was also changed in the container while the sync was stopped
+	err = ioutil.WriteFile(filepath.Join(localPath, "changedBoth"), []byte("local content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remoteState := map[string]*FileInformation{
+		"/unchanged":     snapshot["/unchanged"],
+		"/changedRemote": {Name: "/changedRemote", Size: 14, Mtime: synced.Unix() + 10},
+		"/changedBoth":   {Name: "/changedBoth", Size: 14, Mtime: synced.Unix() + 10},
+	}
+
+	var (
+		uploaded    []string
+		removed     []string
+		downloaded  []string
+		conflicts   []string
+		upstreamEnd = make(chan struct{})
+	)
+	i := newInitialSyncer(&initialSyncOptions{
+		LocalPath: localPath,
+		Strategy:  latest.InitialSyncStrategyPreferLocal,
+		Snapshot:  snapshot,
+		ApplyRemote: func(changes []*FileInformation, remove bool) {
+			for _, change := range changes {
+				uploaded = append(uploaded, change.Name)
+			}
+		},
+		ApplyLocal: func(changes []*remote.Change, force bool) error {
+			for _, change := range changes {
+				if change.ChangeType == remote.ChangeType_DELETE {
+					removed = append(removed, change.Path)
+				} else {
+					downloaded = append(downloaded, change.Path)
+				}
+			}
+			return nil
+		},
+		OnConflict: func(relativePath string, local, remote *FileInformation) {
+			conflicts = append(conflicts, relativePath)
+		},
+		UpstreamDone:   func() { close(upstreamEnd) },
+		DownstreamDone: func() {},
+		Log:            log.Discard,
+	})
+
+	err = i.Run(remoteState)
+	if err != nil {
+		t.Fatalf("Error reconciling: %v", err)
+	}
+	<-upstreamEnd
+
+	sort.Strings(downloaded)
+	if len(removed) != 1 || removed[0] != "/removedRemote" {
+		t.Fatalf("Expected the file removed in the container to be removed locally, got %v", removed)
+	} else if len(downloaded) != 1 || downloaded[0] != "/changedRemote" {
+		t.Fatalf("Expected the file changed in the container to be downloaded, got %v", downloaded)
+	} else if len(conflicts) != 1 || conflicts[0] != "/changedBoth" {
+		t.Fatalf("Expected a conflict for the file changed on both sides, got %v", conflicts)
+	} else if len(uploaded) != 1 || uploaded[0] != "/changedBoth" {
+		t.Fatalf("Expected the local version of the conflicting file to be uploaded, got %v", uploaded)
+	}
+}
@@ -0,0 +1,156 @@
+package sync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/util/crc32"
+	"github.com/pkg/errors"
+)
+
+// persistStateInterval is the interval in which the sync state is written to disk
+var persistStateInterval = time.Minute * 5
+
+// persistedState is the sync state that is written to disk to skip the full
+// initial comparison if the sync is restarted against the same container
+type persistedState struct {
+	// Key identifies the container the state belongs to
+	Key string `json:"key"`
+
+	// Files are the files and folders that were in sync on both sides
+	Files map[string]*FileInformation `json:"files"`
+
+	// Checksums are the checksums of the local content of the files. Files that
+	// could not be hashed have no checksum
+	Checksums map[string]uint32 `json:"checksums,omitempty"`
+}
+
+// stateChecksum is the checksum of a file with the size and mtime it was calculated for
+type stateChecksum struct {
+	size     int64
+	mtime    int64
+	checksum uint32
+}
+
+// loadState loads the persisted state from the given path. If there is no state or
+// the state belongs to another container, nil is returned
+func loadState(path string, key string) (*persistedState, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	state := &persistedState{}
+	err = json.Unmarshal(out, state)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal sync state")
+	} else if state.Key != key || state.Files == nil {
+		return nil, nil
+	}
+
+	return state, nil
+}
+
+// saveState writes the current file index with the checksums of the local files to the state path.
+// It has to be called with the state mutex held
+func (s *Sync) saveState() error {
+	if s.Options.StatePath == "" {
+		return nil
+	}
+
+	s.fileIndex.fileMapMutex.Lock()
+	state := &persistedState{
+		Key:       s.Options.StateKey,
+		Files:     make(map[string]*FileInformation, len(s.fileIndex.fileMap)),
+		Checksums: map[string]uint32{},
+	}
+	for key, element := range s.fileIndex.fileMap {
+		if element.IsSymbolicLink {
+			continue
+		}
+
+		copied := *element
+		state.Files[key] = &copied
+	}
+	s.fileIndex.fileMapMutex.Unlock()
+
+	// hash the local files that are in sync, files that were already hashed with the same size and mtime are not hashed again
+	checksums := make(map[string]*stateChecksum, len(state.Files))
+	for key, element := range state.Files {
+		if element.IsDirectory {
+			continue
+		}
+
+		cached := s.stateChecksums[key]
+		if cached == nil || cached.size != element.Size || cached.mtime != element.Mtime {
+			stat, err := os.Stat(filepath.Join(s.LocalPath, key))
+			if err != nil || stat.IsDir() || stat.Size() != element.Size || stat.ModTime().Unix() != element.Mtime {
+				continue
+			}
+
+			checksum, err := crc32.Checksum(filepath.Join(s.LocalPath, key))
+			if err != nil {
+				continue
+			}
+
+			cached = &stateChecksum{size: element.Size, mtime: element.Mtime, checksum: checksum}
+		}
+
+		checksums[key] = cached
+		state.Checksums[key] = cached.checksum
+	}
+	s.stateChecksums = checksums
+
+	out, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Options.StatePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so we never end up with a partial state
+	tempPath := s.Options.StatePath + ".tmp"
+	err = ioutil.WriteFile(tempPath, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, s.Options.StatePath)
+}
+
+// startStatePersister writes the sync state in a regular interval until the sync is stopped
+func (s *Sync) startStatePersister() {
+	if s.Options.StatePath == "" {
+		return
+	}
+
+	s.stateMutex.Lock()
+	s.persistState = true
+	s.stateMutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-s.stopped:
+				return
+			case <-time.After(persistStateInterval):
+				s.stateMutex.Lock()
+				err := s.saveState()
+				s.stateMutex.Unlock()
+				if err != nil {
+					s.log.Infof("Error persisting sync state: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "local")
+	err = os.MkdirAll(localPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(localPath, "file"), []byte("hello world"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(filepath.Join(localPath, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Sync{
+		LocalPath: localPath,
+		Options: Options{
+			StatePath: filepath.Join(dir, "state", "sync.json"),
+			StateKey:  "containerd://1234:/app",
+		},
+		fileIndex: newFileIndex(),
+	}
+	s.fileIndex.fileMap["/file"] = &FileInformation{Name: "/file", Size: stat.Size(), Mtime: stat.ModTime().Unix()}
+	s.fileIndex.fileMap["/changed"] = &FileInformation{Name: "/changed", Size: 10, Mtime: 100}
+	s.fileIndex.fileMap["/dir"] = &FileInformation{Name: "/dir", IsDirectory: true}
+	s.fileIndex.fileMap["/link"] = &FileInformation{Name: "/link", IsSymbolicLink: true}
+
+	err = s.saveState()
+	if err != nil {
+		t.Fatalf("Error saving state: %v", err)
+	}
+
+	state, err := loadState(s.Options.StatePath, s.Options.StateKey)
+	if err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	} else if len(state.Files) != 3 {
+		t.Fatalf("Expected 3 files in state, got %d", len(state.Files))
+	} else if !sameFile(state.Files["/file"], s.fileIndex.fileMap["/file"]) || !sameFile(state.Files["/dir"], s.fileIndex.fileMap["/dir"]) {
+		t.Fatalf("Loaded state does not match saved state")
+	} else if len(state.Checksums) != 1 {
+		t.Fatalf("Expected only the checksum of /file in state, got %v", state.Checksums)
+	}
+
+	// a file that was touched, but not changed since the last sync is not changed
+	touched := time.Now().Add(time.Hour)
+	err = os.Chtimes(filepath.Join(localPath, "file"), touched, touched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := newInitialSyncer(&initialSyncOptions{LocalPath: localPath, SnapshotChecksums: state.Checksums})
+	localFile := &FileInformation{Name: "/file", Size: stat.Size(), Mtime: touched.Unix()}
+	if !i.sameChecksum("/file", localFile, state.Files["/file"]) {
+		t.Fatalf("Expected touched file to have the same checksum")
+	}
+	err = ioutil.WriteFile(filepath.Join(localPath, "file"), []byte("hello there"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.sameChecksum("/file", localFile, state.Files["/file"]) {
+		t.Fatalf("Expected changed file to have a different checksum")
+	}
+
+	state, err = loadState(s.Options.StatePath, "containerd://5678:/app")
+	if err != nil {
+		t.Fatalf("Error loading state: %v", err)
+	} else if state != nil {
+		t.Fatalf("Expected no state for a different key")
+	}
+
+	state, err = loadState(filepath.Join(dir, "missing.json"), s.Options.StateKey)
+	if err != nil || state != nil {
+		t.Fatalf("Expected no state and no error for a missing file, got %v", err)
+	}
+}
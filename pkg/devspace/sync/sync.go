@@ -1,19 +1,26 @@
 package sync
 
 import (
+	"context"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/juju/ratelimit"
+	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/helper/util/modemask"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
 	"github.com/loft-sh/notify"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
 var syncRetries = 5
@@ -44,12 +51,44 @@ type Options struct {
 	DownstreamLimit int64
 	Verbose         bool
 
+	DeltaTransfer bool
+
 	UpstreamDisabled   bool
 	DownstreamDisabled bool
 
 	InitialSyncCompareBy latest.InitialSyncCompareBy
 	InitialSync          latest.InitialSyncStrategy
 
+	// StatePath is the path where the sync state is persisted between restarts.
+	// If empty, the state is not persisted
+	StatePath string
+
+	// StateKey identifies the container the persisted state belongs to. If the key
+	// of a persisted state differs, a full initial sync is done
+	StateKey string
+
+	// ConflictStrategy defines how files are handled that changed locally and remotely since
+	// they were last in sync
+	ConflictStrategy latest.ConflictStrategy
+
+	// ConflictLogPath is the path of the file where detected conflicts are logged
+	ConflictLogPath string
+
+	// StatusPath is the path where the sync metrics are written to. If empty, the
+	// metrics are only available through Status
+	StatusPath string
+
+	// Questioner is used to ask the user how a conflict should be resolved if the conflict
+	// strategy is ask
+	Questioner log.Logger
+
+	// PreserveModes syncs the full permission bits of files and folders, also if they
+	// already exist on the other side
+	PreserveModes bool
+
+	// ModeMasks are applied to the modes of downloaded files and folders
+	ModeMasks modemask.Masks
+
 	Log log.Logger
 }
 
@@ -70,7 +109,29 @@ type Sync struct {
 	upstream   *upstream
 	downstream *downstream
 
+	// sessionClient is set if the sync uses a session on a shared helper connection
+	sessionClient remote.SessionClient
+	sessionID     string
+
+	// snapshot is the persisted state of the last sync to the same container
+	snapshot          map[string]*FileInformation
+	snapshotChecksums map[string]uint32
+
+	stateMutex     sync.Mutex
+	persistState   bool
+	stateChecksums map[string]*stateChecksum
+
+	conflictMutex sync.Mutex
+
+	// sameContentChecksums are the checksums of the local files that have the same content
+	// remotely, although their mtimes differ
+	sameContentMutex     sync.Mutex
+	sameContentChecksums map[string]uint32
+
+	metrics *metrics
+
 	stopOnce sync.Once
+	stopped  chan struct{}
 
 	onError chan error
 	onDone  chan struct{}
@@ -110,6 +171,8 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 		Options:   options,
 
 		fileIndex: newFileIndex(),
+		metrics:   newMetrics(absoluteRealLocalPath),
+		stopped:   make(chan struct{}),
 		log:       options.Log,
 	}
 
@@ -118,17 +181,40 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 		return nil, errors.Wrap(err, "init ignore parsers")
 	}
 
+	// Load the persisted state of a previous sync
+	if options.StatePath != "" {
+		state, err := loadState(options.StatePath, options.StateKey)
+		if err != nil {
+			s.log.Infof("Error loading persisted sync state: %v", err)
+		} else if state != nil {
+			s.snapshot = state.Files
+			s.snapshotChecksums = state.Checksums
+			s.stateChecksums = map[string]*stateChecksum{}
+			for key, checksum := range state.Checksums {
+				if element := state.Files[key]; element != nil {
+					s.stateChecksums[key] = &stateChecksum{size: element.Size, mtime: element.Mtime, checksum: checksum}
+				}
+			}
+		}
+	}
+
 	return s, nil
 }
 
 // Error handles a sync error
 func (s *Sync) Error(err error) {
 	s.log.Errorf("Sync Error on %s: %v", s.LocalPath, err)
+	s.metrics.setError(err)
 }
 
 // InitUpstream inits the upstream
 func (s *Sync) InitUpstream(reader io.ReadCloser, writer io.WriteCloser) error {
-	upstream, err := newUpstream(reader, writer, s)
+	conn, err := NewClientConnection(reader, writer, s.Options.UpstreamLimit, s.Options.DownstreamLimit)
+	if err != nil {
+		return errors.Wrap(err, "new client connection")
+	}
+
+	upstream, err := newUpstream(remote.NewUpstreamClient(conn), reader, writer, s)
 	if err != nil {
 		return errors.Wrap(err, "new upstream")
 	}
@@ -139,15 +225,95 @@ func (s *Sync) InitUpstream(reader io.ReadCloser, writer io.WriteCloser) error {
 
 // InitDownstream inits the downstream
 func (s *Sync) InitDownstream(reader io.ReadCloser, writer io.WriteCloser) error {
-	downstream, err := newDownstream(reader, writer, s)
+	conn, err := NewClientConnection(reader, writer, s.Options.UpstreamLimit, s.Options.DownstreamLimit)
+	if err != nil {
+		return errors.Wrap(err, "new client connection")
+	}
+
+	downstream, err := newDownstream(remote.NewDownstreamClient(conn), reader, writer, s)
+	if err != nil {
+		return errors.Wrap(err, "new upstream")
+	}
+
+	s.downstream = downstream
+	return nil
+}
+
+// InitSession inits upstream and downstream with a helper connection that is shared with other
+// syncs. The session has to be started on the helper already and is stopped together with the sync
+func (s *Sync) InitSession(conn *grpc.ClientConn, sessionID string) error {
+	upstream, err := newUpstream(remote.NewSessionUpstreamClient(conn, sessionID), nil, nil, s)
 	if err != nil {
 		return errors.Wrap(err, "new upstream")
 	}
 
+	downstream, err := newDownstream(remote.NewSessionDownstreamClient(conn, sessionID), nil, nil, s)
+	if err != nil {
+		return errors.Wrap(err, "new downstream")
+	}
+
+	s.upstream = upstream
 	s.downstream = downstream
+	s.sessionClient = remote.NewSessionClient(conn)
+	s.sessionID = sessionID
 	return nil
 }
 
+// Stopped returns a channel that is closed as soon as the sync is stopped
+func (s *Sync) Stopped() <-chan struct{} {
+	return s.stopped
+}
+
+// NewClientConnection creates a new helper client connection with the given reader and writer. If
+// limits are specified, the bandwidth of the connection is limited to the given bytes per second
+func NewClientConnection(reader io.Reader, writer io.Writer, upstreamLimit, downstreamLimit int64) (*grpc.ClientConn, error) {
+	if downstreamLimit > 0 {
+		reader = ratelimit.Reader(reader, ratelimit.NewBucketWithRate(float64(downstreamLimit), downstreamLimit))
+	}
+	if upstreamLimit > 0 {
+		writer = ratelimit.Writer(writer, ratelimit.NewBucketWithRate(float64(upstreamLimit), upstreamLimit))
+	}
+
+	return util.NewClientConnection(reader, writer)
+}
+
+// NewTCPClientConnection creates a new helper client connection to the given tcp address. If limits
+// are specified, the bandwidth of the connection is limited to the given bytes per second
+func NewTCPClientConnection(address string, upstreamLimit, downstreamLimit int64) (*grpc.ClientConn, error) {
+	return grpc.Dial(address, grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+
+		limitedConn := &rateLimitedConn{Conn: conn, reader: conn, writer: conn}
+		if downstreamLimit > 0 {
+			limitedConn.reader = ratelimit.Reader(conn, ratelimit.NewBucketWithRate(float64(downstreamLimit), downstreamLimit))
+		}
+		if upstreamLimit > 0 {
+			limitedConn.writer = ratelimit.Writer(conn, ratelimit.NewBucketWithRate(float64(upstreamLimit), upstreamLimit))
+		}
+
+		return limitedConn, nil
+	}))
+}
+
+// rateLimitedConn is a connection that reads and writes through the given reader and writer
+type rateLimitedConn struct {
+	net.Conn
+
+	reader io.Reader
+	writer io.Writer
+}
+
+func (c *rateLimitedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *rateLimitedConn) Write(b []byte) (int, error) {
+	return c.writer.Write(b)
+}
+
 // Start starts a new sync instance
 func (s *Sync) Start(onInitUploadDone chan struct{}, onInitDownloadDone chan struct{}, onDone chan struct{}, onError chan error) error {
 	s.onError = onError
@@ -190,6 +356,7 @@ func (s *Sync) initIgnoreParsers() error {
 
 func (s *Sync) mainLoop(onInitUploadDone chan struct{}, onInitDownloadDone chan struct{}) {
 	s.log.Info("Start syncing")
+	s.startMetricsReporter()
 
 	// Start upstream as early as possible
 	if !s.Options.UpstreamDisabled {
@@ -215,6 +382,12 @@ func (s *Sync) startUpstream() {
 	defer s.Stop(nil)
 	s.tree = notify.NewTree()
 
+	// Changed permission bits are only picked up if the modes are preserved
+	events := notify.All
+	if s.Options.PreserveModes {
+		events |= attribEvents
+	}
+
 	// Set up a watchpoint listening for events within a directory tree rooted at specified directory
 	err := s.tree.Watch(s.LocalPath+"/...", s.upstream.events, func(path string) bool {
 		if s.ignoreMatcher == nil || s.ignoreMatcher.RequireFullScan() {
@@ -227,7 +400,7 @@ func (s *Sync) startUpstream() {
 		}
 
 		return s.ignoreMatcher.Matches(path[len(s.LocalPath):], stat.IsDir())
-	}, notify.All)
+	}, events)
 	if err != nil {
 		s.Stop(err)
 		return
@@ -253,21 +426,14 @@ func (s *Sync) startDownstream() {
 }
 
 func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone chan struct{}) error {
+	// The remote walk is also required with a persisted state, because the changes in the container since
+	// the last sync are found by comparing the remote state with the snapshot
 	err := s.downstream.populateFileMap()
 	if err != nil {
 		return errors.Wrap(err, "populate file map")
 	}
 
-	downloadChanges := make(map[string]*FileInformation)
-	s.fileIndex.fileMapMutex.Lock()
-	for key, element := range s.fileIndex.fileMap {
-		if element.IsSymbolicLink {
-			continue
-		}
-
-		downloadChanges[key] = element
-	}
-	s.fileIndex.fileMapMutex.Unlock()
+	downloadChanges := s.remoteState()
 
 	initialSync := newInitialSyncer(&initialSyncOptions{
 		LocalPath: s.LocalPath,
@@ -281,6 +447,9 @@ func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone ch
 		UpstreamDisabled:   s.Options.UpstreamDisabled,
 		DownstreamDisabled: s.Options.DownstreamDisabled,
 		FileIndex:          s.fileIndex,
+		Snapshot:           s.snapshot,
+		SnapshotChecksums:  s.snapshotChecksums,
+		SameContent:        s.sameContent,
 
 		ApplyRemote: s.sendChangesToUpstream,
 		ApplyLocal:  s.downstream.applyChanges,
@@ -307,7 +476,37 @@ func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone ch
 		},
 	})
 
-	return initialSync.Run(downloadChanges)
+	err = initialSync.Run(downloadChanges)
+	if err != nil {
+		return err
+	}
+
+	s.startStatePersister()
+	return nil
+}
+
+// remoteState returns the remote files of the populated file map without symlinks
+func (s *Sync) remoteState() map[string]*FileInformation {
+	s.fileIndex.fileMapMutex.Lock()
+	defer s.fileIndex.fileMapMutex.Unlock()
+
+	remoteState := make(map[string]*FileInformation)
+	for key, element := range s.fileIndex.fileMap {
+		if element.IsSymbolicLink {
+			continue
+		}
+
+		remoteState[key] = element
+	}
+
+	return remoteState
+}
+
+// shouldUploadInitially checks if a change of the initial sync should be uploaded. The file map
+// mutex has to be held by the caller
+func (s *Sync) shouldUploadInitially(change *FileInformation) bool {
+	element := s.fileIndex.fileMap[change.Name]
+	return element == nil || change.Mtime > element.Mtime || change.Size != element.Size
 }
 
 func (s *Sync) sendChangesToUpstream(changes []*FileInformation, remove bool) {
@@ -324,7 +523,7 @@ func (s *Sync) sendChangesToUpstream(changes []*FileInformation, remove bool) {
 		for i := j; i < (j+initialUpstreamBatchSize) && i < len(changes); i++ {
 			if remove {
 				sendBatch = append(sendBatch, changes[i])
-			} else if s.fileIndex.fileMap[changes[i].Name] == nil || changes[i].Mtime > s.fileIndex.fileMap[changes[i].Name].Mtime || changes[i].Size != s.fileIndex.fileMap[changes[i].Name].Size {
+			} else if s.shouldUploadInitially(changes[i]) {
 				sendBatch = append(sendBatch, changes[i])
 			}
 		}
@@ -369,13 +568,43 @@ func (s *Sync) Stop(fatalError error) {
 			}
 		}
 
+		// Stop the session on the shared helper connection
+		if s.sessionClient != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			_, err := s.sessionClient.Stop(ctx, &remote.SessionID{ID: s.sessionID})
+			cancel()
+			if err != nil {
+				s.log.Infof("Error stopping sync session %s: %v", s.sessionID, err)
+			}
+		}
+
+		// Persist the sync state, but only on a clean stop, because after a fatal error the file
+		// index might not describe the files that are in sync anymore
+		s.stateMutex.Lock()
+		if s.persistState && fatalError == nil {
+			err := s.saveState()
+			if err != nil {
+				s.log.Infof("Error persisting sync state: %v", err)
+			}
+		}
+		s.stateMutex.Unlock()
+
+		if fatalError != nil {
+			s.metrics.setError(fatalError)
+		}
+		s.stopMetrics()
+		close(s.stopped)
+
 		if fatalError != nil {
 			s.Error(fatalError)
 
 			// This needs to be rethought because we do not always kill the application here, would be better to have an error channel
-			// or runtime error here
+			// or runtime error here. The send must not block, because nobody might be reading the channel anymore
 			if s.onError != nil {
-				s.onError <- fatalError
+				select {
+				case s.onError <- fatalError:
+				default:
+				}
 			}
 		}
 
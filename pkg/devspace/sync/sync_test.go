@@ -277,6 +277,114 @@ func TestNormalSync(t *testing.T) {
 	// @Florian TODO: Test upstream symlinks
 }
 
+func TestModeOnlySync(t *testing.T) {
+	remote, local, outside := initTestDirs(t)
+	defer os.RemoveAll(remote)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(outside)
+
+	syncClient, err := createTestSyncClient(local, testCaseList{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syncClient.Stop(nil)
+	syncClient.Options.PreserveModes = true
+
+	// Start the downstream server
+	downClientReader, downClientWriter, _ := os.Pipe()
+	downServerReader, downServerWriter, _ := os.Pipe()
+	defer downClientReader.Close()
+	defer downClientWriter.Close()
+	defer downServerReader.Close()
+	defer downServerWriter.Close()
+
+	go func() {
+		err := server.StartDownstreamServer(downServerReader, downClientWriter, &server.DownstreamOptions{
+			RemotePath:  remote,
+			ExitOnClose: false,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = syncClient.InitDownstream(downClientReader, downServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Start upstream server
+	upClientReader, upClientWriter, _ := os.Pipe()
+	upServerReader, upServerWriter, _ := os.Pipe()
+	defer upClientReader.Close()
+	defer upClientWriter.Close()
+	defer upServerReader.Close()
+	defer upServerWriter.Close()
+
+	go func() {
+		err := server.StartUpstreamServer(upServerReader, upClientWriter, &server.UpstreamOptions{
+			UploadPath:    remote,
+			ExludePaths:   []string{},
+			ExitOnClose:   false,
+			PreserveModes: true,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = syncClient.InitUpstream(upClientReader, upServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncClient.readyChan = make(chan bool)
+
+	go syncClient.startUpstream()
+	go syncClient.startDownstream()
+
+	<-syncClient.readyChan
+
+	err = ioutil.WriteFile(filepath.Join(local, "script.sh"), []byte("#!/bin/sh\necho hello\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chmod(filepath.Join(local, "script.sh"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMode(t, filepath.Join(remote, "script.sh"), 0644)
+
+	// only the permission bits are changed locally
+	err = os.Chmod(filepath.Join(local, "script.sh"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMode(t, filepath.Join(remote, "script.sh"), 0755)
+
+	// only the permission bits are changed in the container
+	err = os.Chmod(filepath.Join(remote, "script.sh"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMode(t, filepath.Join(local, "script.sh"), 0700)
+}
+
+func waitForMode(t *testing.T, path string, mode os.FileMode) {
+	var current os.FileMode
+	for start := time.Now(); time.Since(start) < 15*time.Second; time.Sleep(100 * time.Millisecond) {
+		stat, err := os.Stat(path)
+		if err == nil {
+			current = stat.Mode().Perm()
+			if current == mode {
+				return
+			}
+		}
+	}
+
+	t.Fatalf("Expected mode %o of %s, got %o", mode, path, current)
+}
+
 func getSyncOptions(testCases testCaseList) Options {
 	options := Options{
 		ExcludePaths:         []string{},
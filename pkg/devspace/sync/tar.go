@@ -111,6 +111,18 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 			return false, err
 		}
 
+		// Set the folder mode if it should be preserved or masked
+		if u.syncConfig.Options.PreserveModes || len(u.syncConfig.Options.ModeMasks) > 0 {
+			mode := os.FileMode(0755)
+			if u.syncConfig.Options.PreserveModes {
+				mode = header.FileInfo().Mode()
+			} else if stat != nil {
+				mode = stat.Mode()
+			}
+
+			_ = os.Chmod(outFileName, u.syncConfig.Options.ModeMasks.Apply(relativePath, true, mode.Perm()))
+		}
+
 		u.syncConfig.fileIndex.CreateDirInFileMap(relativePath)
 		return true, nil
 	}
@@ -138,16 +150,16 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 		return false, errors.Wrap(err, "close file")
 	}
 
-	if stat != nil {
+	if stat != nil && !u.syncConfig.Options.PreserveModes {
 		// Set old permissions correctly
-		_ = os.Chmod(outFileName, stat.Mode())
+		_ = os.Chmod(outFileName, u.syncConfig.Options.ModeMasks.Apply(relativePath, false, stat.Mode()))
 
 		// Set owner & group correctly
 		// TODO: Enable this on supported platforms
 		// _ = os.Chown(outFileName, stat.Sys().(*syscall.Stat).Uid, stat.Sys().(*syscall.Stat_t).Gid)
 	} else {
 		// Set permissions
-		_ = os.Chmod(outFileName, header.FileInfo().Mode())
+		_ = os.Chmod(outFileName, u.syncConfig.Options.ModeMasks.Apply(relativePath, false, header.FileInfo().Mode()))
 	}
 
 	// Set mod time correctly
@@ -203,6 +215,12 @@ func (u *Unarchiver) createAllFolders(name string, perm os.FileMode) error {
 			return errors.Errorf("Error creating %s: %v", dirToCreate, err)
 		}
 
+		// Apply mode masks to the new folder
+		localPath := filepath.ToSlash(u.syncConfig.LocalPath)
+		if len(u.syncConfig.Options.ModeMasks) > 0 && strings.HasPrefix(dirToCreate, localPath+"/") {
+			_ = os.Chmod(dirToCreate, u.syncConfig.Options.ModeMasks.Apply(dirToCreate[len(localPath):], true, perm))
+		}
+
 		if u.syncConfig.Options.DirCreateCmd != "" {
 			cmdArgs := make([]string, 0, len(u.syncConfig.Options.DirCreateArgs))
 			for _, arg := range u.syncConfig.Options.DirCreateArgs {
@@ -231,6 +249,9 @@ type Archiver struct {
 	ignoreMatcher ignoreparser.IgnoreParser
 	writer        *tar.Writer
 	writtenFiles  map[string]*FileInformation
+
+	// preserveModes adds all folders to the archive, so that their modes are transferred as well
+	preserveModes bool
 }
 
 // NewArchiver creates a new archiver
@@ -290,7 +311,7 @@ func (a *Archiver) tarFolder(target *FileInformation, targetStat os.FileInfo) er
 		return nil
 	}
 
-	if len(files) == 0 && target.Name != "" {
+	if (len(files) == 0 || a.preserveModes) && target.Name != "" {
 		// check if not excluded
 		if a.ignoreMatcher == nil || !a.ignoreMatcher.RequireFullScan() || !a.ignoreMatcher.Matches(target.Name, true) {
 			// Case empty directory
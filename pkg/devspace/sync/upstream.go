@@ -13,12 +13,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/juju/ratelimit"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
-	"github.com/loft-sh/devspace/helper/util/crc32"
 
-	"github.com/juju/ratelimit"
 	"github.com/loft-sh/devspace/helper/remote"
-	"github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/notify"
 	"github.com/pkg/errors"
 )
@@ -40,33 +38,20 @@ type upstream struct {
 	eventBufferMutex sync.Mutex
 
 	ignoreMatcher ignoreparser.IgnoreParser
+
+	// deltaLimiter limits the bandwidth of the delta payloads to the upstream limit
+	deltaLimiter *ratelimit.Bucket
 }
 
 const (
 	removeFilesBufferSize = 64
+
+	// deltaTransferMinSize is the minimum file size for which we try to only send the changed blocks
+	deltaTransferMinSize = 512 * 1024
 )
 
 // newUpstream creates a new upstream handler with the given parameters
-func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upstream, error) {
-	var (
-		clientReader io.Reader = reader
-		clientWriter io.Writer = writer
-	)
-
-	// Apply limits if specified
-	if sync.Options.DownstreamLimit > 0 {
-		clientReader = ratelimit.Reader(reader, ratelimit.NewBucketWithRate(float64(sync.Options.DownstreamLimit), sync.Options.DownstreamLimit))
-	}
-	if sync.Options.UpstreamLimit > 0 {
-		clientWriter = ratelimit.Writer(writer, ratelimit.NewBucketWithRate(float64(sync.Options.UpstreamLimit), sync.Options.UpstreamLimit))
-	}
-
-	// Create client
-	conn, err := util.NewClientConnection(clientReader, clientWriter)
-	if err != nil {
-		return nil, errors.Wrap(err, "new client connection")
-	}
-
+func newUpstream(client remote.UpstreamClient, reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upstream, error) {
 	// Create combined exclude paths
 	excludePaths := make([]string, 0, len(sync.Options.ExcludePaths)+len(sync.Options.UploadExcludePaths))
 	excludePaths = append(excludePaths, sync.Options.ExcludePaths...)
@@ -77,6 +62,11 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 		return nil, errors.Wrap(err, "compile paths")
 	}
 
+	var deltaLimiter *ratelimit.Bucket
+	if sync.Options.UpstreamLimit > 0 {
+		deltaLimiter = ratelimit.NewBucketWithRate(float64(sync.Options.UpstreamLimit), sync.Options.UpstreamLimit)
+	}
+
 	return &upstream{
 		events:      make(chan notify.EventInfo, 1000), // High buffer size so we don't miss any fsevents if there are a lot of changes
 		eventBuffer: make([]notify.EventInfo, 0, 64),
@@ -87,9 +77,10 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 
 		reader: reader,
 		writer: writer,
-		client: remote.NewUpstreamClient(conn),
+		client: client,
 
 		ignoreMatcher: ignoreMatcher,
+		deltaLimiter:  deltaLimiter,
 	}, nil
 }
 
@@ -247,6 +238,14 @@ func (u *upstream) getFileInformationFromEvent(events []notify.EventInfo) ([]*Fi
 				continue
 			}
 
+			// Attribute changes of folders are skipped, because the folder would be uploaded with its contents again
+			if event.Event()&notify.All == 0 && event.Event()&attribEvents != 0 {
+				stat, err := os.Stat(fullPath)
+				if err == nil && stat.IsDir() {
+					continue
+				}
+			}
+
 			relativePath := getRelativeFromFullPath(fullPath, u.sync.LocalPath)
 
 			// Determine what kind of change we got (Create or Remove)
@@ -326,9 +325,14 @@ func (u *upstream) evaluateChange(relativePath, fullpath string) (*FileInformati
 			IsDirectory:    stat.IsDir(),
 			IsSymbolicLink: stat.Mode()&os.ModeSymlink != 0,
 		}
+		tracked := u.sync.fileIndex.fileMap[relativePath]
 		if shouldUpload(u.sync, fileInfo) {
 			// New Create Task
 			return fileInfo, nil
+		} else if fileInfo.IsDirectory && tracked != nil && tracked.IsDirectory && !tracked.IsSymbolicLink && (u.sync.ignoreMatcher == nil || !u.sync.ignoreMatcher.Matches(relativePath, true)) {
+			// The folder was created by downstream in the meantime, but files that were created right after
+			// the folder might be missing in the events, so the folder is uploaded with its contents again
+			return fileInfo, nil
 		}
 	} else {
 		// Remove symlinks
@@ -392,6 +396,7 @@ func (u *upstream) RemoveSymlinks(absPath string) {
 }
 
 func (u *upstream) applyChanges(changes []*FileInformation) error {
+	started := time.Now()
 	var creates []*FileInformation
 	var removes []*FileInformation
 
@@ -414,6 +419,15 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 		}
 	}
 
+	// Check if there are files that were also changed remotely
+	if len(creates) > 0 && u.sync.conflictDetectionEnabled() {
+		var err error
+		creates, err = u.resolveConflicts(creates)
+		if err != nil {
+			return errors.Wrap(err, "resolve conflicts")
+		}
+	}
+
 	// Apply creates
 	var writtenChanges int
 	if len(creates) > 0 {
@@ -433,6 +447,7 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 				}
 
 				u.sync.log.Infof("Upstream - Retry upload because of error: %v", err)
+				u.sync.metrics.addRetry()
 				creates = u.updateUploadChanges(creates)
 				if len(creates) == 0 {
 					break
@@ -451,12 +466,18 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 		return nil
 	}
 
+	u.sync.metrics.update(func(status *Status) {
+		status.FilesUploaded += int64(writtenChanges)
+		status.FilesRemovedRemote += int64(len(removes))
+	})
+
 	// execute batch command
 	err := u.ExecuteBatchCommand()
 	if err != nil {
 		return err
 	}
 
+	u.sync.metrics.addBatch(true, started)
 	u.sync.log.Infof("Upstream - Successfully processed %d change(s)", changeAmount)
 
 	// Restart container if needed
@@ -519,6 +540,17 @@ func (u *upstream) applyCreates(files []*FileInformation) (int, error) {
 		return 0, nil
 	}
 
+	// upload big files that already exist remotely as delta
+	deltaChanges := 0
+	if u.sync.Options.DeltaTransfer {
+		files, deltaChanges, err = u.applyDeltaCreates(files)
+		if err != nil {
+			return 0, errors.Wrap(err, "upload delta")
+		} else if len(files) == 0 {
+			return deltaChanges, nil
+		}
+	}
+
 	size := int64(0)
 	for _, c := range files {
 		if c.IsDirectory {
@@ -567,7 +599,7 @@ func (u *upstream) applyCreates(files []*FileInformation) (int, error) {
 		u.sync.fileIndex.fileMap[element.Name] = element
 	}
 
-	return len(archiver.WrittenFiles()), nil
+	return len(archiver.WrittenFiles()) + deltaChanges, nil
 }
 
 func (u *upstream) filterChanges(files []*FileInformation) ([]*FileInformation, error) {
@@ -579,7 +611,7 @@ func (u *upstream) filterChanges(files []*FileInformation) ([]*FileInformation,
 	for _, f := range files {
 		if alreadyUsed[f.Name] {
 			continue
-		} else if f.IsDirectory || u.sync.fileIndex.fileMap[f.Name] == nil || u.sync.fileIndex.fileMap[f.Name].Size != f.Size || f.Size < 1024 {
+		} else if f.IsDirectory || u.sync.fileIndex.fileMap[f.Name] == nil || u.sync.fileIndex.fileMap[f.Name].Size != f.Size || f.Size < 1024 || modeChanged(u.sync, f.Mode, u.sync.fileIndex.fileMap[f.Name]) {
 			newChanges = append(newChanges, f)
 			alreadyUsed[f.Name] = true
 			continue
@@ -591,74 +623,20 @@ func (u *upstream) filterChanges(files []*FileInformation) ([]*FileInformation,
 
 	// now compare crc32 hashes
 	if len(needCheck) > 0 {
-		// cancel after 10 minutes
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
-		defer cancel()
-
-		// create done chan
-		done := make(chan error)
-
-		// start remote hashing
-		remoteChecksums := make([]uint32, 0, len(needCheck))
-		localChecksums := make([]uint32, 0, len(needCheck))
-		go func() {
-			// send 100 each time
-			for i := 0; i < len(needCheck); i += 100 {
-				batch := make([]string, 0, 100)
-				for j := 0; j < 100; j++ {
-					if i+j >= len(needCheck) {
-						break
-					}
-
-					batch = append(batch, needCheck[i+j].Name)
-				}
-
-				// ask remote for checksums
-				checksums, err := u.client.Checksums(ctx, &remote.Paths{Paths: batch})
-				if err != nil {
-					done <- err
-					return
-				} else if checksums == nil {
-					done <- fmt.Errorf("unexpected checksum response")
-					return
-				} else if len(checksums.Checksums) != len(batch) {
-					done <- fmt.Errorf("unexpected checksum size %d != %d", len(checksums.Checksums), len(batch))
-					return
-				}
-
-				remoteChecksums = append(remoteChecksums, checksums.Checksums...)
-			}
-
-			done <- nil
-		}()
-
-		// start local hashing
+		paths := make([]string, 0, len(needCheck))
 		for _, c := range needCheck {
-			// Just remove everything inside and ignore any errors
-			absolutePath := path.Join(u.sync.LocalPath, c.Name)
-			checksum, err := crc32.Checksum(absolutePath)
-			if err != nil && !os.IsNotExist(err) {
-				u.sync.log.Infof("Error hashing file %s: %v", c.Name, err)
-			}
-
-			localChecksums = append(localChecksums, checksum)
+			paths = append(paths, c.Name)
 		}
 
-		// wait for remote
-		err := <-done
+		same, err := u.sync.sameContent(paths)
 		if err != nil {
-			return nil, errors.Wrap(err, "hashing remote files")
-		} else if len(remoteChecksums) != len(localChecksums) {
-			return nil, fmt.Errorf("unexpected checksum size %d != %d", len(remoteChecksums), len(localChecksums))
+			return nil, err
 		}
 
-		// compare checksums
-		for i := range remoteChecksums {
-			if remoteChecksums[i] != 0 && remoteChecksums[i] == localChecksums[i] {
-				continue
+		for _, c := range needCheck {
+			if !same[c.Name] {
+				newChanges = append(newChanges, c)
 			}
-
-			newChanges = append(newChanges, needCheck[i])
 		}
 	}
 
@@ -678,6 +656,7 @@ func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ign
 
 	// Archive the given files
 	archiver := NewArchiver(u.sync.LocalPath, tarWriter, ignoreMatcher)
+	archiver.preserveModes = u.sync.Options.PreserveModes
 	for _, file := range files {
 		err := archiver.AddToArchive(file.Name)
 		if err != nil {
@@ -716,6 +695,10 @@ func (u *upstream) uploadArchive(reader io.ReadCloser) error {
 
 				return errors.Wrap(err, "upload send")
 			}
+
+			u.sync.metrics.update(func(status *Status) {
+				status.BytesUploaded += int64(n)
+			})
 		}
 
 		if err == io.EOF {
@@ -0,0 +1,9 @@
+//go:build darwin && !kqueue && cgo
+// +build darwin,!kqueue,cgo
+
+package sync
+
+import "github.com/loft-sh/notify"
+
+// attribEvents are the events that report changed permission bits
+const attribEvents = notify.FSEventsInodeMetaMod
@@ -0,0 +1,6 @@
+package sync
+
+import "github.com/loft-sh/notify"
+
+// attribEvents are the events that report changed permission bits
+const attribEvents = notify.InAttrib
@@ -0,0 +1,9 @@
+//go:build (!linux && !darwin) || (darwin && kqueue) || (darwin && !cgo)
+// +build !linux,!darwin darwin,kqueue darwin,!cgo
+
+package sync
+
+import "github.com/loft-sh/notify"
+
+// attribEvents are the events that report changed permission bits. They are not watched on this platform
+const attribEvents notify.Event = 0
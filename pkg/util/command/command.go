@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"io"
 	"os"
 	"os/exec"
@@ -66,6 +67,13 @@ func NewStreamCommand(command string, args []string) Interface {
 	}
 }
 
+// NewStreamCommandContext creates a new stream command that is killed if the context is canceled
+func NewStreamCommandContext(ctx context.Context, command string, args []string) Interface {
+	return &StreamCommand{
+		cmd: exec.CommandContext(ctx, command, args...),
+	}
+}
+
 // CombinedOutput runs the command and returns the stdout and stderr
 func (s *StreamCommand) CombinedOutput() ([]byte, error) {
 	return s.cmd.CombinedOutput()
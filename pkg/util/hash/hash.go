@@ -82,6 +82,23 @@ func Directory(path string) (string, error) {
 
 // DirectoryExcludes calculates a hash for a directory and excludes the submitted patterns
 func DirectoryExcludes(srcPath string, excludePatterns []string, fast bool) (string, error) {
+	return directoryExcludes(srcPath, excludePatterns, fast, false, nil)
+}
+
+// DirectoryExcludesWithIndex calculates the same hash as DirectoryExcludes, but only reads the files that
+// changed since they were added to the given index
+func DirectoryExcludesWithIndex(srcPath string, excludePatterns []string, index *FileIndex) (string, error) {
+	return directoryExcludes(srcPath, excludePatterns, false, false, index)
+}
+
+// DirectoryContentExcludes calculates a hash for a directory and excludes the submitted patterns. In contrast to
+// DirectoryExcludes the hash only depends on the relative paths, the modes and the sha256 hashes of the file contents,
+// so the same directory results in the same hash on every machine
+func DirectoryContentExcludes(srcPath string, excludePatterns []string) (string, error) {
+	return directoryExcludes(srcPath, excludePatterns, false, true, nil)
+}
+
+func directoryExcludes(srcPath string, excludePatterns []string, fast bool, content bool, index *FileIndex) (string, error) {
 	srcPath, err := filepath.Abs(srcPath)
 	if err != nil {
 		return "", err
@@ -180,6 +197,23 @@ func DirectoryExcludes(srcPath string, excludePatterns []string, fast bool) (str
 			return nil
 		}
 		seen[relFilePath] = true
+		if content {
+			filePath = filepath.ToSlash(relFilePath)
+			mode := strconv.FormatUint(uint64(f.Mode().Perm()), 8)
+			if f.IsDir() {
+				_, _ = io.WriteString(hash, filePath+";"+mode)
+				return nil
+			}
+
+			checksum, err := File(filepath.Join(srcPath, relFilePath))
+			if err != nil {
+				return nil
+			}
+
+			_, _ = io.WriteString(hash, filePath+";"+mode+";"+checksum)
+			return nil
+		}
+
 		if f.IsDir() {
 			// Path is enough
 			_, _ = io.WriteString(hash, filePath)
@@ -188,7 +222,7 @@ func DirectoryExcludes(srcPath string, excludePatterns []string, fast bool) (str
 				_, _ = io.WriteString(hash, filePath+";"+strconv.FormatInt(f.Size(), 10)+";"+strconv.FormatInt(f.ModTime().Unix(), 10))
 			} else {
 				// Check file change
-				checksum, err := hashFileCRC32(filePath, 0xedb88320)
+				checksum, err := index.checksum(relFilePath, filepath.Join(srcPath, relFilePath), f)
 				if err != nil {
 					return nil
 				}
@@ -3,7 +3,10 @@ package hash
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
 
@@ -101,3 +104,104 @@ func TestHashDirectoryExcludes(t *testing.T) {
 	}
 
 }
+
+func TestHashDirectoryContentExcludes(t *testing.T) {
+	dirs := []string{}
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "test")
+		if err != nil {
+			t.Fatalf("Error creating temporary directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		_ = fsutil.WriteToFile([]byte("content"), filepath.Join(dir, "includedFile"))
+		_ = fsutil.WriteToFile([]byte("excluded"+strconv.Itoa(i)), filepath.Join(dir, "excludedFile"))
+		dirs = append(dirs, dir)
+	}
+
+	hashA, err := DirectoryContentExcludes(dirs[0], []string{"excludedFile"})
+	if err != nil {
+		t.Fatalf("Error creating hash of directory: %v", err)
+	}
+	hashB, err := DirectoryContentExcludes(dirs[1], []string{"excludedFile"})
+	if err != nil {
+		t.Fatalf("Error creating hash of directory: %v", err)
+	}
+	assert.Equal(t, hashA, hashB, "Same content in different directories results in different hashes")
+
+	stat, err := os.Stat(filepath.Join(dirs[1], "includedFile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chmod(filepath.Join(dirs[1], "includedFile"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err = DirectoryContentExcludes(dirs[1], []string{"excludedFile"})
+	if err != nil {
+		t.Fatalf("Error creating hash of directory: %v", err)
+	}
+	assert.Assert(t, hashA != hashB, "Changed mode results in the same hash")
+
+	err = os.Chmod(filepath.Join(dirs[1], "includedFile"), stat.Mode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = fsutil.WriteToFile([]byte("changed"), filepath.Join(dirs[1], "includedFile"))
+	hashB, err = DirectoryContentExcludes(dirs[1], []string{"excludedFile"})
+	if err != nil {
+		t.Fatalf("Error creating hash of directory: %v", err)
+	}
+	assert.Assert(t, hashA != hashB, "Changed content results in the same hash")
+}
+
+func TestHashDirectoryExcludesWithIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	contextDir := filepath.Join(dir, "context")
+	indexPath := filepath.Join(dir, "index.json")
+	past := time.Now().Add(-time.Hour)
+	for _, file := range []string{"a", "b", "sub/c", "excluded"} {
+		_ = fsutil.WriteToFile([]byte(file), filepath.Join(contextDir, file))
+		_ = os.Chtimes(filepath.Join(contextDir, file), past, past)
+	}
+
+	// the hash with an empty index is the same as without an index
+	expected, err := DirectoryExcludes(contextDir, []string{"excluded"}, false)
+	assert.NilError(t, err)
+	index := LoadFileIndex(indexPath, contextDir)
+	hashed, err := DirectoryExcludesWithIndex(contextDir, []string{"excluded"}, index)
+	assert.NilError(t, err)
+	assert.Equal(t, hashed, expected)
+	assert.NilError(t, index.Save())
+
+	// unchanged files are taken from the index
+	index = LoadFileIndex(indexPath, contextDir)
+	assert.Equal(t, len(index.Files), 3)
+	index.Files["a"].Checksum = "cached"
+	hashed, err = DirectoryExcludesWithIndex(contextDir, []string{"excluded"}, index)
+	assert.NilError(t, err)
+	assert.Assert(t, hashed != expected, "Index was not used")
+
+	// changed and deleted files are detected
+	_ = fsutil.WriteToFile([]byte("changed"), filepath.Join(contextDir, "b"))
+	_ = os.Remove(filepath.Join(contextDir, "sub/c"))
+	expected, err = DirectoryExcludes(contextDir, []string{"excluded"}, false)
+	assert.NilError(t, err)
+	index = LoadFileIndex(indexPath, contextDir)
+	hashed, err = DirectoryExcludesWithIndex(contextDir, []string{"excluded"}, index)
+	assert.NilError(t, err)
+	assert.Equal(t, hashed, expected)
+	assert.NilError(t, index.Save())
+
+	index = LoadFileIndex(indexPath, contextDir)
+	assert.Equal(t, len(index.Files), 2)
+
+	// an index of another directory is ignored
+	index = LoadFileIndex(indexPath, dir)
+	assert.Equal(t, len(index.Files), 0)
+}
@@ -0,0 +1,126 @@
+package hash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// racyInterval is the duration before the index timestamp in which modified files are always rehashed,
+// because file systems with a coarse modification time might not record a change that happened shortly
+// after the file was hashed
+const racyInterval = 2 * time.Second
+
+// FileIndex caches the checksums of the files within a directory, so that only files that changed since
+// the last hashing have to be read again
+type FileIndex struct {
+	path string
+
+	// Root is the absolute directory the index was created for
+	Root string `json:"root"`
+
+	// Timestamp is the time in unix nanoseconds when the index was created. Files that were modified shortly
+	// before or after this time could have changed again within the same modification time and are always rehashed
+	Timestamp int64 `json:"timestamp"`
+
+	// Files maps the slash separated paths relative to the root to the cached checksums
+	Files map[string]*FileIndexEntry `json:"files"`
+
+	mutex   sync.Mutex
+	started int64
+	seen    map[string]*FileIndexEntry
+}
+
+// FileIndexEntry is a cached checksum of a single file
+type FileIndexEntry struct {
+	Size     int64  `json:"size"`
+	ModTime  int64  `json:"mtime"`
+	Inode    uint64 `json:"inode,omitempty"`
+	Checksum string `json:"checksum"`
+}
+
+// LoadFileIndex loads the file index for the given root from the given path. If the index does not exist
+// or is invalid, an empty index is returned
+func LoadFileIndex(path string, root string) *FileIndex {
+	root, _ = filepath.Abs(root)
+	index := &FileIndex{}
+	out, err := ioutil.ReadFile(path)
+	if err != nil || json.Unmarshal(out, index) != nil || index.Root != root {
+		index = &FileIndex{}
+	}
+
+	index.path = path
+	index.Root = root
+	if index.Files == nil {
+		index.Files = map[string]*FileIndexEntry{}
+	}
+
+	index.started = time.Now().UnixNano()
+	index.seen = map[string]*FileIndexEntry{}
+	return index
+}
+
+// checksum returns the crc32 checksum of the file, which is only computed if the file changed since
+// it was indexed. Symlinks are always hashed, because a changed target does not change the link itself
+func (i *FileIndex) checksum(relPath string, absPath string, info os.FileInfo) (string, error) {
+	if i == nil || !info.Mode().IsRegular() {
+		return hashFileCRC32(absPath, 0xedb88320)
+	}
+
+	key := filepath.ToSlash(relPath)
+	inode := getInode(info)
+	modTime := info.ModTime().UnixNano()
+
+	i.mutex.Lock()
+	entry := i.Files[key]
+	i.mutex.Unlock()
+	if entry == nil || entry.Size != info.Size() || entry.ModTime != modTime || entry.Inode != inode || modTime >= i.Timestamp-int64(racyInterval) {
+		checksum, err := hashFileCRC32(absPath, 0xedb88320)
+		if err != nil {
+			return "", err
+		}
+
+		entry = &FileIndexEntry{
+			Size:     info.Size(),
+			ModTime:  modTime,
+			Inode:    inode,
+			Checksum: checksum,
+		}
+	}
+
+	i.mutex.Lock()
+	i.seen[key] = entry
+	i.mutex.Unlock()
+	return entry.Checksum, nil
+}
+
+// Save writes the files that were hashed since the index was loaded to disk. Files that were not
+// hashed anymore, e.g. because they were deleted, are removed from the index
+func (i *FileIndex) Save() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.Files = i.seen
+	i.Timestamp = i.started
+	out, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(i.path), 0755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so a concurrent reader never sees a partial index
+	tempPath := i.path + ".tmp"
+	err = ioutil.WriteFile(tempPath, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, i.path)
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package hash
+
+import (
+	"os"
+	"syscall"
+)
+
+// getInode returns the inode of the file or 0 if it cannot be determined
+func getInode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+
+	return 0
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package hash
+
+import "os"
+
+// getInode returns 0, because os.FileInfo does not contain the file index on windows
+func getInode(info os.FileInfo) uint64 {
+	return 0
+}